@@ -17,7 +17,7 @@ func Example() {
 	store = &dbStore
 	defer dbStore.Close()
 
-	newServer := server.NewRouter(options, &store)
+	newServer := server.NewRouter(options, &store, nil, nil)
 
 	_ = http.ListenAndServe(options.ServerAddress, newServer)
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/PaBah/url-shortener.git/internal/config"
 	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/storage"
 	"go.uber.org/zap"
 )
 
@@ -25,17 +26,33 @@ func isFlagPassed(name string) bool {
 func ParseFlags(options *config.Options) {
 	var specified bool
 	var serverAddress, baseURL, logsLevel, fileStoragePath, databaseDSN, enableHTTPS, configFilePath, trustedSubnet string
-	var gRPCAddress string
+	var gRPCAddress, idStrategy, idLength, jwtSecret, jwtKeysFile, jwtTokenExp, deletedRetention string
+	var fileSyncPolicy, fileCompactInterval, storageBackend, sqlitePath, cacheSize, cacheTTL, shutdownTimeout string
+	var trustedProxies string
 
 	flag.StringVar(&configFilePath, "c", "", "path to config file")
 	flag.StringVar(&options.ServerAddress, "a", ":8080", "host:port on which server run")
 	flag.StringVar(&options.GRPCAddress, "g", ":3200", "host:port on which gRPC run")
 	flag.StringVar(&options.BaseURL, "b", "http://localhost:8080", "URL for of shortened URLs hosting")
-	flag.StringVar(&options.DatabaseDSN, "d", "host=localhost user=paulbahush dbname=urlshortener password=", "database DSN address")
+	flag.StringVar(&options.DatabaseDSN, "d", "", "database DSN address")
 	flag.StringVar(&options.LogsLevel, "l", "info", "logs level")
 	flag.StringVar(&options.FileStoragePath, "f", "/tmp/short-url-db.json", "path to file.json with file storage data")
 	flag.StringVar(&options.TrustedSubnet, "t", "", "CIDR address of allowed subnet")
+	flag.StringVar(&options.TrustedProxies, "trusted-proxies", "", "comma separated CIDR addresses of proxies whose x-real-ip gRPC metadata is trusted")
 	flag.BoolVar(&options.EnableHTTPS, "s", false, "enable-https")
+	flag.StringVar(&options.IDStrategy, "id-strategy", "hash", "short ID generation strategy: hash, random or sequence")
+	flag.IntVar(&options.IDLength, "id-length", 8, "length of randomly generated short IDs")
+	flag.StringVar(&options.JWTSecret, "jwt-secret", "", "HS256 secret for JWT tokens")
+	flag.StringVar(&options.JWTKeysFile, "jwt-keys", "", "path to JSON file with rotated JWT keys")
+	flag.StringVar(&options.JWTTokenExp, "jwt-exp", "3h", "lifetime of issued JWT tokens")
+	flag.StringVar(&options.DeletedRetention, "deleted-retention", "0", "period after which deleted URLs are permanently purged, e.g. 720h, 0 disables purge")
+	flag.StringVar(&options.FileSyncPolicy, "file-sync", "interval", "fsync policy of file storage log: always, interval or never")
+	flag.StringVar(&options.FileCompactInterval, "file-compact", "10m", "min period between file storage log compactions, 0 compacts only on shutdown")
+	flag.StringVar(&options.StorageBackend, "storage-backend", "", "storage backend: memory, file, postgres or sqlite, empty is postgres when database DSN is set and file otherwise")
+	flag.StringVar(&options.SQLitePath, "sqlite-path", "/tmp/short-url.sqlite", "path to SQLite database file of sqlite storage backend")
+	flag.IntVar(&options.CacheSize, "cache-size", storage.DefaultCacheSize, "max amount of short IDs in redirect lookups cache, 0 disables cache")
+	flag.StringVar(&options.CacheTTL, "cache-ttl", storage.DefaultCacheTTL.String(), "lifetime of redirect lookups cache entries")
+	flag.StringVar(&options.ShutdownTimeout, "shutdown-timeout", "30s", "max period of graceful shutdown, in-flight requests and streams are dropped after it")
 	flag.Parse()
 
 	var fileConfig config.Options
@@ -71,6 +88,48 @@ func ParseFlags(options *config.Options) {
 				if !isFlagPassed("g") {
 					options.GRPCAddress = fileConfig.GRPCAddress
 				}
+				if !isFlagPassed("id-strategy") && fileConfig.IDStrategy != "" {
+					options.IDStrategy = fileConfig.IDStrategy
+				}
+				if !isFlagPassed("id-length") && fileConfig.IDLength != 0 {
+					options.IDLength = fileConfig.IDLength
+				}
+				if !isFlagPassed("jwt-secret") && fileConfig.JWTSecret != "" {
+					options.JWTSecret = fileConfig.JWTSecret
+				}
+				if !isFlagPassed("jwt-keys") && fileConfig.JWTKeysFile != "" {
+					options.JWTKeysFile = fileConfig.JWTKeysFile
+				}
+				if !isFlagPassed("jwt-exp") && fileConfig.JWTTokenExp != "" {
+					options.JWTTokenExp = fileConfig.JWTTokenExp
+				}
+				if !isFlagPassed("deleted-retention") && fileConfig.DeletedRetention != "" {
+					options.DeletedRetention = fileConfig.DeletedRetention
+				}
+				if !isFlagPassed("file-sync") && fileConfig.FileSyncPolicy != "" {
+					options.FileSyncPolicy = fileConfig.FileSyncPolicy
+				}
+				if !isFlagPassed("file-compact") && fileConfig.FileCompactInterval != "" {
+					options.FileCompactInterval = fileConfig.FileCompactInterval
+				}
+				if !isFlagPassed("storage-backend") && fileConfig.StorageBackend != "" {
+					options.StorageBackend = fileConfig.StorageBackend
+				}
+				if !isFlagPassed("sqlite-path") && fileConfig.SQLitePath != "" {
+					options.SQLitePath = fileConfig.SQLitePath
+				}
+				if !isFlagPassed("cache-size") && fileConfig.CacheSize != 0 {
+					options.CacheSize = fileConfig.CacheSize
+				}
+				if !isFlagPassed("cache-ttl") && fileConfig.CacheTTL != "" {
+					options.CacheTTL = fileConfig.CacheTTL
+				}
+				if !isFlagPassed("shutdown-timeout") && fileConfig.ShutdownTimeout != "" {
+					options.ShutdownTimeout = fileConfig.ShutdownTimeout
+				}
+				if !isFlagPassed("trusted-proxies") && fileConfig.TrustedProxies != "" {
+					options.TrustedProxies = fileConfig.TrustedProxies
+				}
 			}
 		}
 	}
@@ -114,4 +173,74 @@ func ParseFlags(options *config.Options) {
 	if specified {
 		options.EnableHTTPS, _ = strconv.ParseBool(enableHTTPS)
 	}
+
+	idStrategy, specified = os.LookupEnv("ID_STRATEGY")
+	if specified {
+		options.IDStrategy = idStrategy
+	}
+
+	idLength, specified = os.LookupEnv("ID_LENGTH")
+	if specified {
+		options.IDLength, _ = strconv.Atoi(idLength)
+	}
+
+	jwtSecret, specified = os.LookupEnv("JWT_SECRET")
+	if specified {
+		options.JWTSecret = jwtSecret
+	}
+
+	jwtKeysFile, specified = os.LookupEnv("JWT_KEYS_FILE")
+	if specified {
+		options.JWTKeysFile = jwtKeysFile
+	}
+
+	jwtTokenExp, specified = os.LookupEnv("JWT_TOKEN_EXP")
+	if specified {
+		options.JWTTokenExp = jwtTokenExp
+	}
+
+	deletedRetention, specified = os.LookupEnv("DELETED_RETENTION")
+	if specified {
+		options.DeletedRetention = deletedRetention
+	}
+
+	fileSyncPolicy, specified = os.LookupEnv("FILE_SYNC_POLICY")
+	if specified {
+		options.FileSyncPolicy = fileSyncPolicy
+	}
+
+	fileCompactInterval, specified = os.LookupEnv("FILE_COMPACT_INTERVAL")
+	if specified {
+		options.FileCompactInterval = fileCompactInterval
+	}
+
+	storageBackend, specified = os.LookupEnv("STORAGE_BACKEND")
+	if specified {
+		options.StorageBackend = storageBackend
+	}
+
+	sqlitePath, specified = os.LookupEnv("SQLITE_PATH")
+	if specified {
+		options.SQLitePath = sqlitePath
+	}
+
+	cacheSize, specified = os.LookupEnv("CACHE_SIZE")
+	if specified {
+		options.CacheSize, _ = strconv.Atoi(cacheSize)
+	}
+
+	cacheTTL, specified = os.LookupEnv("CACHE_TTL")
+	if specified {
+		options.CacheTTL = cacheTTL
+	}
+
+	shutdownTimeout, specified = os.LookupEnv("SHUTDOWN_TIMEOUT")
+	if specified {
+		options.ShutdownTimeout = shutdownTimeout
+	}
+
+	trustedProxies, specified = os.LookupEnv("TRUSTED_PROXIES")
+	if specified {
+		options.TrustedProxies = trustedProxies
+	}
 }
@@ -41,6 +41,8 @@ func TestParseFlags(t *testing.T) {
 			assert.Equal(t, options.GRPCAddress, tt.expectedValue[5], "Правльно распаршеный DATABASE_DSN")
 			assert.Equal(t, options.TrustedSubnet, tt.expectedValue[6], "Правльно распаршеный DATABASE_DSN")
 			assert.Equal(t, options.EnableHTTPS, true, "Правльно распаршеный DATABASE_DSN")
+			assert.Equal(t, "0", options.DeletedRetention, "purge of deleted URLs is disabled by default")
+			assert.Equal(t, "", options.StorageBackend, "storage backend is chosen by database DSN by default")
 		})
 	}
 }
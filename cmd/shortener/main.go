@@ -2,23 +2,29 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
+	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
 	"github.com/PaBah/url-shortener.git/cmd/shortener/server"
+	"github.com/PaBah/url-shortener.git/internal/async"
+	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/config"
 	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/metrics"
+	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
 	"github.com/PaBah/url-shortener.git/internal/tls"
-
-	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
 )
 
 var (
@@ -39,38 +45,94 @@ func main() {
 		return
 	}
 
-	var store storage.Repository
-	dbStore, err := storage.NewDBStorage(context.Background(), options.DatabaseDSN)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	err := run(ctx, options)
+	stop()
 	if err != nil {
-		logger.Log().Error("Database error with start", zap.Error(err))
-		inFileStore := storage.NewInFileStorage(options.FileStoragePath)
-		store = &inFileStore
+		logger.Log().Error("Server stopped with error", zap.Error(err))
+		os.Exit(1)
+	}
+}
 
-		defer inFileStore.Close()
-	} else {
-		store = &dbStore
-		defer dbStore.Close()
+// run - start HTTP and gRPC servers with background workers and serve until ctx is done or server fails,
+// then shut them down gracefully and close storage
+func run(ctx context.Context, options *config.Options) (err error) {
+	idGenerator, err := models.NewIDGenerator(options.IDStrategy, options.IDLength)
+	if err != nil {
+		return fmt.Errorf("ID generator can not be initialized: %w", err)
 	}
 
-	newServer := server.NewRouter(options, &store)
-	newGRPCServer := server.NewShortenerServer(options, &store)
+	deletedRetention, err := time.ParseDuration(options.DeletedRetention)
+	if err != nil {
+		return fmt.Errorf("deleted URLs retention can not be parsed: %w", err)
+	}
 
-	logger.Log().Info("Start server on", zap.String("address", options.ServerAddress))
+	shutdownTimeout, err := time.ParseDuration(options.ShutdownTimeout)
+	if err != nil {
+		return fmt.Errorf("shutdown timeout can not be parsed: %w", err)
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	defer stop()
+	keySet, err := newKeySet(options)
+	if err != nil {
+		return fmt.Errorf("JWT keys can not be initialized: %w", err)
+	}
+	auth.SetKeySet(keySet)
 
-	go func() {
-		listen, err := net.Listen("tcp", options.GRPCAddress)
-		if err != nil {
-			log.Fatal(err)
+	store, closeStore, err := newStorage(options)
+	if err != nil {
+		return fmt.Errorf("storage %q can not be initialized: %w", options.StorageBackend, err)
+	}
+	defer func() {
+		if closeErr := closeStore(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("storage can not be closed: %w", closeErr))
 		}
-		s := grpc.NewServer()
-		pb.RegisterShortenerServiceServer(s, newGRPCServer)
+	}()
 
-		if err := s.Serve(listen); err != nil {
-			log.Fatal(err)
+	if _, ok := idGenerator.(*models.SequenceIDGenerator); ok {
+		start, err := sequenceStart(ctx, store)
+		if err != nil {
+			return fmt.Errorf("sequence of short IDs can not be restored: %w", err)
 		}
+		idGenerator = models.NewSequenceIDGenerator(start)
+	}
+	models.SetIDGenerator(idGenerator)
+	store = metrics.NewInstrumentedRepository(store)
+	store, err = newCachedStorage(options, store)
+	if err != nil {
+		return fmt.Errorf("storage cache can not be initialized: %w", err)
+	}
+
+	clickRecorder := async.NewClickRecorder(store, async.ClicksFlushInterval)
+	defer clickRecorder.Close()
+
+	clickHub := async.NewClickHub()
+
+	httpServer := &http.Server{Handler: server.NewRouter(options, &store, clickRecorder, clickHub)}
+	grpcServer, err := server.NewGRPCServer(server.NewShortenerServer(options, &store, clickHub))
+	if err != nil {
+		return fmt.Errorf("gRPC server can not be initialized: %w", err)
+	}
+
+	httpListener, err := net.Listen("tcp", options.ServerAddress)
+	if err != nil {
+		return err
+	}
+	grpcListener, err := net.Listen("tcp", options.GRPCAddress)
+	if err != nil {
+		_ = httpListener.Close()
+		return err
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	workers := []worker{{name: "expiration sweeper", done: async.ExpirationSweeper(workersCtx, store, async.ExpirationSweepInterval)}}
+	if deletedRetention > 0 {
+		workers = append(workers, worker{name: "deleted URLs purger", done: async.DeletedPurger(workersCtx, store, async.PurgeInterval, deletedRetention)})
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- grpcServer.Serve(grpcListener)
 	}()
 	go func() {
 		if options.EnableHTTPS {
@@ -78,15 +140,196 @@ func main() {
 				certFilePath = "cert.pem" // certFilePath - path to TLS certificate
 				keyFilePath  = "key.pem"  // keyFilePath - path to TLS key
 			)
-			err = tls.CreateTLSCert(certFilePath, keyFilePath)
-			err = http.ListenAndServeTLS(options.ServerAddress, certFilePath, keyFilePath, newServer)
+			if err := tls.CreateTLSCert(certFilePath, keyFilePath); err != nil {
+				serveErr <- err
+				return
+			}
+			serveErr <- httpServer.ServeTLS(httpListener, certFilePath, keyFilePath)
 		} else {
-			err = http.ListenAndServe(options.ServerAddress, newServer)
+			serveErr <- httpServer.Serve(httpListener)
 		}
-		if err != nil {
-			logger.Log().Fatal("Error starting server", zap.Error(err))
+	}()
+	logger.Log().Info("Start server on", zap.String("address", options.ServerAddress), zap.String("grpc_address", options.GRPCAddress))
+
+	select {
+	case <-ctx.Done():
+		logger.Log().Info("Shutdown is started")
+	case err = <-serveErr:
+		logger.Log().Error("Server failed, shutdown is started", zap.Error(err))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	stopWorkers()
+	if shutdownErr := shutdown(shutdownCtx, httpServer, grpcServer, workers...); shutdownErr != nil {
+		err = errors.Join(err, shutdownErr)
+	}
+	logger.Log().Info("Servers are stopped and queued work is drained, storage is closing")
+	return err
+}
+
+// worker - background worker which closes done when it is stopped
+type worker struct {
+	name string
+	done chan struct{}
+}
+
+// shutdown - stop servers gracefully, they stop accepting and finish in-flight requests,
+// then wait for queued deletions and stopped workers, so storage can be closed after it.
+// Requests and streams which are not finished until ctx is done are dropped
+func shutdown(ctx context.Context, httpServer *http.Server, grpcServer *grpc.Server, workers ...worker) error {
+	var wg sync.WaitGroup
+	var httpErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if httpErr = httpServer.Shutdown(ctx); httpErr != nil {
+			httpErr = errors.Join(fmt.Errorf("HTTP server is not stopped gracefully: %w", httpErr), httpServer.Close())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logger.Log().Warn("gRPC server is not stopped gracefully, its streams are dropped")
+			grpcServer.Stop()
+			<-stopped
 		}
 	}()
+	wg.Wait()
+
+	err := httpErr
+	if deletionsErr := async.WaitDeletions(ctx); deletionsErr != nil {
+		err = errors.Join(err, fmt.Errorf("queued deletions are not finished: %w", deletionsErr))
+	}
+	var hung []string
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			select {
+			case <-w.done:
+			default:
+				hung = append(hung, w.name)
+			}
+		}
+	}
+	if len(hung) > 0 {
+		err = errors.Join(err, fmt.Errorf("background workers are not stopped: %s: %w", strings.Join(hung, ", "), ctx.Err()))
+	}
+	return err
+}
 
-	<-ctx.Done()
+// newKeySet - build JWT KeySet from keys file or single secret, falls back to insecure default key
+func newKeySet(options *config.Options) (*auth.KeySet, error) {
+	tokenExp := auth.TokenExp
+	if options.JWTTokenExp != "" {
+		var err error
+		tokenExp, err = time.ParseDuration(options.JWTTokenExp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.JWTKeysFile != "" {
+		return auth.LoadKeySet(options.JWTKeysFile, tokenExp)
+	}
+	secret := options.JWTSecret
+	if secret == "" {
+		logger.Log().Warn("JWT secret is not configured, insecure default key is used")
+		secret = auth.SecretKey
+	}
+	return auth.NewKeySet(auth.DefaultKeyID, []*auth.Key{auth.NewHMACKey(auth.DefaultKeyID, secret)}, tokenExp)
+}
+
+// newStorage - open storage backend chosen by configuration, returns it with its close function.
+// When backend is not chosen Postgres is used if database DSN is configured and file storage otherwise,
+// unavailable Postgres fails startup and never falls back to file storage
+func newStorage(options *config.Options) (storage.Repository, func() error, error) {
+	switch options.StorageBackend {
+	case storage.BackendMemory:
+		memoryStore := storage.NewMemoryStorage()
+		return memoryStore, memoryStore.Close, nil
+	case storage.BackendFile:
+		inFileStore, err := newInFileStorage(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		return inFileStore, inFileStore.Close, nil
+	case storage.BackendPostgres:
+		dbStore, err := storage.NewDBStorage(context.Background(), options.DatabaseDSN)
+		if err != nil {
+			// connection pool is opened before schema is migrated, it is leaked otherwise
+			return nil, nil, errors.Join(err, dbStore.Close())
+		}
+		return &dbStore, dbStore.Close, nil
+	case storage.BackendSQLite:
+		sqliteStore, err := storage.NewSQLiteStorage(context.Background(), options.SQLitePath)
+		if err != nil {
+			return nil, nil, errors.Join(err, sqliteStore.Close())
+		}
+		return sqliteStore, sqliteStore.Close, nil
+	case "":
+		chosen := *options
+		chosen.StorageBackend = storage.BackendFile
+		if options.DatabaseDSN != "" {
+			chosen.StorageBackend = storage.BackendPostgres
+		}
+		return newStorage(&chosen)
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", storage.ErrUnknownBackend, options.StorageBackend)
+	}
+}
+
+// sequenceStart - returns max sequence number among stored not alias short IDs, so sequence generator continues after them
+func sequenceStart(ctx context.Context, store storage.Repository) (start uint64, err error) {
+	err = store.ScanURLs(ctx, func(shortURL models.ShortenURL) error {
+		if n, ok := models.DecodeSequenceID(shortURL.UUID); ok && !shortURL.IsAlias {
+			start = max(start, n)
+		}
+		return nil
+	})
+	return
+}
+
+// newCachedStorage - wrap storage with redirect lookups cache and expose its metrics, disabled by zero cache size
+func newCachedStorage(options *config.Options, store storage.Repository) (storage.Repository, error) {
+	if options.CacheSize <= 0 {
+		return store, nil
+	}
+	cacheTTL := storage.DefaultCacheTTL
+	if options.CacheTTL != "" {
+		var err error
+		cacheTTL, err = time.ParseDuration(options.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cachedStore := storage.NewCachedRepository(store, options.CacheSize, cacheTTL)
+	if err := metrics.RegisterRepositoryCache(cachedStore); err != nil {
+		return nil, err
+	}
+	return cachedStore, nil
+}
+
+// newInFileStorage - open InFileStorage with configured log fsync policy and compaction interval
+func newInFileStorage(options *config.Options) (*storage.InFileStorage, error) {
+	inFileOptions := storage.DefaultInFileOptions()
+	if options.FileSyncPolicy != "" {
+		inFileOptions.SyncPolicy = options.FileSyncPolicy
+	}
+	if options.FileCompactInterval != "" {
+		compactInterval, err := time.ParseDuration(options.FileCompactInterval)
+		if err != nil {
+			return nil, err
+		}
+		inFileOptions.CompactInterval = compactInterval
+	}
+	return storage.NewInFileStorageWithOptions(options.FileStoragePath, inFileOptions)
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/config"
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/mock"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+)
+
+func TestNewStorage(t *testing.T) {
+	store, closeStore, err := newStorage(&config.Options{StorageBackend: storage.BackendMemory})
+	require.NoError(t, err)
+	assert.IsType(t, &storage.MemoryStorage{}, store)
+	assert.NoError(t, closeStore())
+
+	store, closeStore, err = newStorage(&config.Options{StorageBackend: storage.BackendFile, FileStoragePath: filepath.Join(t.TempDir(), "store")})
+	require.NoError(t, err)
+	assert.IsType(t, &storage.InFileStorage{}, store)
+	assert.NoError(t, closeStore())
+
+	store, closeStore, err = newStorage(&config.Options{StorageBackend: storage.BackendSQLite, SQLitePath: filepath.Join(t.TempDir(), "store.sqlite")})
+	require.NoError(t, err)
+	assert.IsType(t, &storage.SQLiteStorage{}, store)
+	assert.NoError(t, closeStore())
+
+	_, _, err = newStorage(&config.Options{StorageBackend: storage.BackendPostgres, DatabaseDSN: "host=127.0.0.1 port=1 connect_timeout=1"})
+	assert.Error(t, err, "chosen backend does not fall back to file")
+
+	_, _, err = newStorage(&config.Options{DatabaseDSN: "host=127.0.0.1 port=1 connect_timeout=1", FileStoragePath: filepath.Join(t.TempDir(), "store")})
+	assert.Error(t, err, "configured database DSN chooses Postgres without fallback to file")
+
+	store, closeStore, err = newStorage(&config.Options{FileStoragePath: filepath.Join(t.TempDir(), "store")})
+	require.NoError(t, err)
+	assert.IsType(t, &storage.InFileStorage{}, store, "file storage is used without database DSN")
+	assert.NoError(t, closeStore())
+
+	_, _, err = newStorage(&config.Options{StorageBackend: storage.BackendSQLite, SQLitePath: t.TempDir()})
+	assert.Error(t, err, "directory is not SQLite file")
+
+	_, _, err = newStorage(&config.Options{StorageBackend: "redis"})
+	assert.ErrorIs(t, err, storage.ErrUnknownBackend)
+}
+
+func TestNewCachedStorage(t *testing.T) {
+	memoryStore := storage.NewMemoryStorage()
+
+	store, err := newCachedStorage(&config.Options{CacheSize: 0}, memoryStore)
+	require.NoError(t, err)
+	assert.Equal(t, memoryStore, store, "zero cache size disables cache")
+
+	_, err = newCachedStorage(&config.Options{CacheSize: 10, CacheTTL: "minute"}, memoryStore)
+	assert.Error(t, err)
+
+	store, err = newCachedStorage(&config.Options{CacheSize: 10, CacheTTL: "1m"}, memoryStore)
+	require.NoError(t, err)
+	assert.IsType(t, &storage.CachedRepository{}, store)
+}
+
+func TestSequenceStart(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+	for _, shortURL := range []models.ShortenURL{
+		{UUID: "Y", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"},
+		{UUID: "10", OriginalURL: "https://practicum.yandex.kz/", UserID: "1"},
+		models.NewAliasShortURL("zzzz", "https://ya.ru/", "1"),
+		{UUID: "not-sequence", OriginalURL: "https://ya.ru/sale", UserID: "1", IsAlias: true},
+	} {
+		require.NoError(t, store.Store(ctx, &shortURL))
+	}
+
+	start, err := sequenceStart(ctx, store)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(62), start, "max stored sequence ID, aliases are skipped")
+
+	ctrl := gomock.NewController(t)
+	failing := mock.NewMockRepository(ctrl)
+	failing.EXPECT().ScanURLs(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+	_, err = sequenceStart(ctx, failing)
+	assert.Error(t, err, "scan failure is returned")
+}
+
+// freeAddress - returns local address with port which is free at the moment
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestRun_gracefulShutdownOnSIGTERM(t *testing.T) {
+	const amount = 2000
+	filePath := filepath.Join(t.TempDir(), "store.json")
+	options := &config.Options{
+		ServerAddress:    freeAddress(t),
+		GRPCAddress:      freeAddress(t),
+		BaseURL:          "http://localhost:8080",
+		StorageBackend:   storage.BackendFile,
+		FileStoragePath:  filePath,
+		FileSyncPolicy:   "never",
+		IDStrategy:       "random",
+		IDLength:         8,
+		DeletedRetention: "0",
+		ShutdownTimeout:  "10s",
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- run(ctx, options)
+	}()
+
+	JWTToken, err := auth.BuildJWTString("1")
+	require.NoError(t, err)
+	batch := make([]dto.BatchShortenRequest, 0, amount)
+	for i := 0; i < amount; i++ {
+		batch = append(batch, dto.BatchShortenRequest{CorrelationID: fmt.Sprint(i), URL: fmt.Sprintf("https://ya.ru/%d", i)})
+	}
+	body, err := json.Marshal(batch)
+	require.NoError(t, err)
+	send := func(method string, route string, body []byte) (*http.Response, error) {
+		req, err := http.NewRequest(method, "http://"+options.ServerAddress+route, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "Authorization", Value: JWTToken})
+		return http.DefaultClient.Do(req)
+	}
+
+	var res *http.Response
+	require.Eventually(t, func() bool {
+		res, err = send(http.MethodPost, "/api/shorten/batch", body)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "server is started")
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var shortened []dto.BatchShortenResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&shortened))
+	require.NoError(t, res.Body.Close())
+	shortIDs := make([]string, 0, len(shortened))
+	for _, shortURL := range shortened {
+		shortIDs = append(shortIDs, path.Base(shortURL.ShortURL))
+	}
+
+	body, err = json.Marshal(shortIDs)
+	require.NoError(t, err)
+	res, err = send(http.MethodDelete, "/api/user/urls", body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	select {
+	case err = <-stopped:
+		require.NoError(t, err, "shutdown is graceful")
+	case <-time.After(15 * time.Second):
+		t.Fatal("server is not stopped after SIGTERM")
+	}
+	_, err = send(http.MethodGet, "/ping", nil)
+	assert.Error(t, err, "server does not accept requests after shutdown")
+
+	inFileStore := storage.NewInFileStorage(filePath)
+	defer inFileStore.Close()
+	for _, shortID := range shortIDs {
+		shortURL, err := inFileStore.FindByID(context.Background(), shortID)
+		require.NoError(t, err)
+		require.True(t, shortURL.DeletedFlag, "deletion queued before SIGTERM is stored")
+	}
+}
+
+func TestShutdown_hungWorker(t *testing.T) {
+	stopped := make(chan struct{})
+	close(stopped)
+	hung := make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := shutdown(ctx, &http.Server{}, grpc.NewServer(),
+		worker{name: "expiration sweeper", done: stopped},
+		worker{name: "deleted URLs purger", done: hung},
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "background workers are not stopped: deleted URLs purger")
+	assert.NotContains(t, err.Error(), "expiration sweeper", "stopped worker is not reported")
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Errors of user defined short ID validation
+var (
+	// ErrInvalidAlias - error when alias has wrong length or contains forbidden symbols
+	ErrInvalidAlias = errors.New("alias must be 3-64 symbols long and contain only latin letters, digits, '-' and '_'")
+	// ErrReservedAlias - error when alias collides with server routes
+	ErrReservedAlias = errors.New("alias is reserved")
+)
+
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,64}$`)
+
+// reservedAliases - first path segments of server routes which can not be used as short IDs
+var reservedAliases = []string{"api", "metrics", "ping"}
+
+// validateAlias - check if alias can be used as short ID
+func validateAlias(alias string) error {
+	if !aliasPattern.MatchString(alias) {
+		return ErrInvalidAlias
+	}
+	for _, reserved := range reservedAliases {
+		if strings.EqualFold(alias, reserved) {
+			return ErrReservedAlias
+		}
+	}
+	return nil
+}
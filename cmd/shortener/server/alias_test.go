@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateAlias(t *testing.T) {
+	tests := []struct {
+		alias   string
+		wantErr error
+	}{
+		{alias: "spring-sale", wantErr: nil},
+		{alias: "Sale_2024", wantErr: nil},
+		{alias: "ab", wantErr: ErrInvalidAlias},
+		{alias: "spring sale", wantErr: ErrInvalidAlias},
+		{alias: "sale/1", wantErr: ErrInvalidAlias},
+		{alias: "ping", wantErr: ErrReservedAlias},
+		{alias: "API", wantErr: ErrReservedAlias},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			assert.ErrorIs(t, validateAlias(tt.alias), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// ErrDuplicateCorrelationID - error when correlation ID is used by several URLs of batch
+var ErrDuplicateCorrelationID = errors.New("correlation_id is already used in batch")
+
+// batchItem - user provided params of one shortened URL of batch
+type batchItem struct {
+	correlationID string
+	params        shortenParams
+	err           error // err - reason why item can not be shortened found before, e.g. while parsing
+}
+
+// batchItemResult - outcome of shortening one URL of batch
+type batchItemResult struct {
+	correlationID string
+	shortURL      string // shortURL - full short URL, empty for invalid items
+	status        string // status - one of dto batch statuses
+	err           error  // err - reason of invalid status
+}
+
+// shortenBatch - validate and store batch of URLs, invalid items do not prevent storing others.
+// Results follow items order, error is returned only when storage failed as a whole or short ID can not be generated
+func shortenBatch(ctx context.Context, repository storage.Repository, baseURL string, items []batchItem) ([]batchItemResult, error) {
+	results := make([]batchItemResult, len(items))
+	shortURLs := make([]models.ShortenURL, 0, len(items))
+	// positions - index of item result of every stored URL, so batch is resolved in items order
+	positions := make([]int, 0, len(items))
+	correlationIDs := make(map[string]struct{}, len(items))
+	for i, item := range items {
+		results[i] = batchItemResult{correlationID: item.correlationID, status: dto.BatchStatusInvalid, err: item.err}
+		if item.err != nil {
+			continue
+		}
+		if _, found := correlationIDs[item.correlationID]; found {
+			results[i].err = ErrDuplicateCorrelationID
+			continue
+		}
+		shortURL, err := newShortURL(item.params)
+		if errors.Is(err, models.ErrIDGeneration) {
+			return nil, err
+		}
+		if err != nil {
+			results[i].err = err
+			continue
+		}
+		correlationIDs[item.correlationID] = struct{}{}
+		shortURLs = append(shortURLs, shortURL)
+		positions = append(positions, i)
+	}
+
+	stored, err := repository.StoreBatch(ctx, shortURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range stored {
+		i := positions[j]
+		switch result.Status {
+		case models.BatchCreated:
+			results[i].status = dto.BatchStatusCreated
+		case models.BatchExisted:
+			results[i].status = dto.BatchStatusExisting
+		default:
+			results[i].err = result.Err
+			continue
+		}
+		results[i].shortURL = fmt.Sprintf("%s/%s", baseURL, result.ShortURL.UUID)
+	}
+	return results, nil
+}
+
+// isPartialBatch - check if some URLs of batch were not shortened
+func isPartialBatch(results []batchItemResult) bool {
+	for _, result := range results {
+		if result.status == dto.BatchStatusInvalid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// Formats of user's short URLs export
+const (
+	// ExportFormatJSON - JSON array of dto.ExportURLResponse
+	ExportFormatJSON = "json"
+	// ExportFormatNDJSON - dto.ExportURLResponse per line
+	ExportFormatNDJSON = "ndjson"
+	// ExportFormatCSV - header with column names and short URL per row
+	ExportFormatCSV = "csv"
+)
+
+// ErrUnsupportedExportFormat - error when export is requested in unknown format
+var ErrUnsupportedExportFormat = errors.New("export format must be " + ExportFormatJSON + ", " + ExportFormatNDJSON + " or " + ExportFormatCSV)
+
+// exportCSVHeader - columns of CSV export
+var exportCSVHeader = []string{"short_id", "short_url", "original_url", "is_alias", "created_at", "expires_at", "max_clicks", "clicks", "is_deleted", "deleted_at"}
+
+// exportUserURLs - read every short URL of user from context page by page in creation order and pass pages to send,
+// so whole export is never kept in memory. Deleted short URLs are read only when includeDeleted is set.
+// Clicks of short URL are its recorded clicks, counter of clicks limit is taken when it is bigger,
+// as expansions over gRPC are counted only there
+func exportUserURLs(ctx context.Context, repository storage.Repository, includeDeleted bool, send func(shortURLs []models.ShortenURL) error) error {
+	options := models.ListOptions{Limit: models.MaxListLimit, Status: models.StatusActive}
+	if includeDeleted {
+		options.Status = models.StatusAll
+	}
+	for {
+		page, err := repository.ListUserURLs(ctx, options)
+		if err != nil {
+			return err
+		}
+		shortIDs := make([]string, 0, len(page.URLs))
+		for _, shortURL := range page.URLs {
+			shortIDs = append(shortIDs, shortURL.UUID)
+		}
+		recorded, err := repository.CountClicks(ctx, shortIDs)
+		if err != nil {
+			return err
+		}
+		for i := range page.URLs {
+			page.URLs[i].Clicks = max(page.URLs[i].Clicks, recorded[page.URLs[i].UUID])
+		}
+		if err = send(page.URLs); err != nil {
+			return err
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		options.Cursor = page.NextCursor
+	}
+}
+
+// exportedURL - build export response of short URL
+func exportedURL(baseURL string, shortURL models.ShortenURL) dto.ExportURLResponse {
+	optionalTime := func(moment time.Time) *time.Time {
+		if moment.IsZero() {
+			return nil
+		}
+		moment = moment.UTC()
+		return &moment
+	}
+	return dto.ExportURLResponse{
+		ShortID:     shortURL.UUID,
+		ShortURL:    fmt.Sprintf("%s/%s", baseURL, shortURL.UUID),
+		OriginalURL: shortURL.OriginalURL,
+		IsAlias:     shortURL.IsAlias,
+		CreatedAt:   optionalTime(shortURL.CreatedAt),
+		ExpiresAt:   optionalTime(shortURL.ExpiresAt),
+		MaxClicks:   shortURL.MaxClicks,
+		Clicks:      shortURL.Clicks,
+		IsDeleted:   shortURL.DeletedFlag,
+		DeletedAt:   optionalTime(shortURL.DeletedAt),
+	}
+}
+
+// exportWriter - writes exported short URLs in one of export formats, nothing is written before first write or close
+type exportWriter interface {
+	// write - write exported short URL
+	write(shortURL dto.ExportURLResponse) error
+	// close - finish export, written data is flushed
+	close() error
+}
+
+// newExportWriter - create exportWriter of format and returns content type of its output
+func newExportWriter(format string, w io.Writer) (writer exportWriter, contentType string, err error) {
+	switch format {
+	case ExportFormatJSON:
+		return &jsonExportWriter{writer: w}, "application/json", nil
+	case ExportFormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, ContentTypeNDJSON, nil
+	case ExportFormatCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}, ContentTypeCSV, nil
+	default:
+		return nil, "", ErrUnsupportedExportFormat
+	}
+}
+
+// jsonExportWriter - exportWriter of JSON array, array is not closed when export is broken
+type jsonExportWriter struct {
+	writer  io.Writer
+	started bool
+}
+
+func (w *jsonExportWriter) write(shortURL dto.ExportURLResponse) error {
+	data, err := json.Marshal(shortURL)
+	if err != nil {
+		return err
+	}
+	delimiter := ","
+	if !w.started {
+		delimiter, w.started = "[", true
+	}
+	_, err = w.writer.Write(append([]byte(delimiter), data...))
+	return err
+}
+
+func (w *jsonExportWriter) close() (err error) {
+	if !w.started {
+		_, err = io.WriteString(w.writer, "[]")
+		return
+	}
+	_, err = io.WriteString(w.writer, "]")
+	return
+}
+
+// ndjsonExportWriter - exportWriter of JSON object per line
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonExportWriter) write(shortURL dto.ExportURLResponse) error {
+	return w.encoder.Encode(shortURL)
+}
+
+func (w *ndjsonExportWriter) close() error {
+	return nil
+}
+
+// csvExportWriter - exportWriter of CSV rows with exportCSVHeader, header is written even for empty export
+type csvExportWriter struct {
+	writer  *csv.Writer
+	started bool
+}
+
+func (w *csvExportWriter) write(shortURL dto.ExportURLResponse) error {
+	if !w.started {
+		w.started = true
+		if err := w.writer.Write(exportCSVHeader); err != nil {
+			return err
+		}
+	}
+	formatTime := func(moment *time.Time) string {
+		if moment == nil {
+			return ""
+		}
+		return moment.Format(time.RFC3339)
+	}
+	err := w.writer.Write([]string{
+		shortURL.ShortID,
+		shortURL.ShortURL,
+		shortURL.OriginalURL,
+		strconv.FormatBool(shortURL.IsAlias),
+		formatTime(shortURL.CreatedAt),
+		formatTime(shortURL.ExpiresAt),
+		strconv.Itoa(shortURL.MaxClicks),
+		strconv.Itoa(shortURL.Clicks),
+		strconv.FormatBool(shortURL.IsDeleted),
+		formatTime(shortURL.DeletedAt),
+	})
+	if err != nil {
+		return err
+	}
+	// rows are written through like in other formats, so export is not held in csv.Writer buffer
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvExportWriter) close() error {
+	if !w.started {
+		w.started = true
+		if err := w.writer.Write(exportCSVHeader); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
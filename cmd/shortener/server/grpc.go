@@ -2,16 +2,27 @@ package server
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/PaBah/url-shortener.git/internal/async"
 	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/config"
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/interceptors"
+	"github.com/PaBah/url-shortener.git/internal/metrics"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
 )
@@ -20,21 +31,44 @@ import (
 type ShortenerServer struct {
 	pb.UnimplementedShortenerServiceServer
 
-	options *config.Options
-	storage storage.Repository
+	options  *config.Options
+	storage  storage.Repository
+	clickHub *async.ClickHub
 }
 
 // Short - handler for shortening URL
 func (s *ShortenerServer) Short(ctx context.Context, in *pb.ShortRequest) (*pb.ShortResponse, error) {
 	response := &pb.ShortResponse{}
-	shortURL := models.NewShortURL(in.Url, in.UserId)
-	err := s.storage.Store(ctx, shortURL)
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
+	shortURL, err := newShortURL(shortenParams{
+		originalURL: in.Url,
+		userID:      userID,
+		alias:       in.Alias,
+		expiresAt:   timestampToTime(in.ExpiresAt),
+		maxClicks:   int(in.MaxClicks),
+	})
+	if errors.Is(err, models.ErrIDGeneration) {
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	err = s.storage.Store(ctx, &shortURL)
 
+	if errors.Is(err, storage.ErrAliasTaken) {
+		return response, status.Errorf(codes.AlreadyExists, err.Error())
+	}
 	response.Result = shortURL.UUID
 	if errors.Is(err, storage.ErrConflict) {
 		return response, status.Errorf(codes.InvalidArgument, err.Error())
 	}
-	return response, err
+	if err != nil {
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+	return response, nil
 }
 
 // Expand - handler for list user's shortened URLs
@@ -45,6 +79,14 @@ func (s *ShortenerServer) Expand(ctx context.Context, in *pb.ExpandRequest) (*pb
 	if shortenURL.DeletedFlag {
 		return response, status.Errorf(codes.InvalidArgument, "shorten URL already expanded")
 	}
+	if shortenURL.IsExpired(time.Now()) {
+		return response, status.Errorf(codes.FailedPrecondition, storage.ErrLinkExpired.Error())
+	}
+	if shortenURL.MaxClicks > 0 {
+		if err := s.storage.RegisterClick(ctx, in.ShortId); errors.Is(err, storage.ErrLinkExpired) {
+			return response, status.Errorf(codes.FailedPrecondition, err.Error())
+		}
+	}
 	response.Url = shortenURL.OriginalURL
 	return response, nil
 }
@@ -52,25 +94,60 @@ func (s *ShortenerServer) Expand(ctx context.Context, in *pb.ExpandRequest) (*pb
 // Delete - handler for delete short URLs
 func (s *ShortenerServer) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteResponse, error) {
 	response := &pb.DeleteResponse{}
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
 
 	inputCh := async.BulkDeletionDataGenerator(in.Id)
 
-	channels := async.DeletionFanOut(in.UserId, s.storage, inputCh)
+	channels := async.DeletionFanOut(userID, s.storage, inputCh)
 	addResultCh := async.DeletionFanIn(channels...)
-	async.Delete(s.storage, addResultCh)
+	if err = async.Delete(s.storage, addResultCh); err != nil {
+		return response, status.Errorf(codes.Unavailable, err.Error())
+	}
+
+	return response, nil
+}
+
+// Restore - handler for undo of short URLs deletion
+func (s *ShortenerServer) Restore(ctx context.Context, in *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+	response := &pb.RestoreResponse{}
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
 
+	restored, err := s.storage.RestoreShortURLs(context.WithValue(ctx, auth.ContextUserKey, userID), in.Id)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+
+	response.Id = restored
 	return response, nil
 }
 
 // GetUserBucket - handler for list of short URLs of authorized user
 func (s *ShortenerServer) GetUserBucket(ctx context.Context, in *pb.GetUserBucketRequest) (*pb.GetUserBucketResponse, error) {
 	response := &pb.GetUserBucketResponse{}
-	shortURLs, err := s.storage.GetAllUsers(context.WithValue(ctx, auth.ContextUserKey, in.UserId))
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
+	page, err := s.storage.ListUserURLs(context.WithValue(ctx, auth.ContextUserKey, userID), models.ListOptions{
+		Limit:  int(in.Limit),
+		Cursor: in.Cursor,
+		SortBy: in.SortBy,
+		Desc:   in.Desc,
+		Status: in.Status,
+		Search: in.Search,
+	})
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, err.Error())
 	}
 
-	for _, shortURL := range shortURLs {
+	response.NextCursor = page.NextCursor
+	for _, shortURL := range page.URLs {
 		response.Data = append(response.Data, &pb.OriginalAndShort{
 			OriginalUrl: shortURL.OriginalURL,
 			ShortUrl:    fmt.Sprintf("%s/%s", s.options.BaseURL, shortURL.UUID),
@@ -83,47 +160,374 @@ func (s *ShortenerServer) GetUserBucket(ctx context.Context, in *pb.GetUserBucke
 // ShortBatch - handler for creation of list of short URLs
 func (s *ShortenerServer) ShortBatch(ctx context.Context, in *pb.ShortBatchRequest) (*pb.ShortBatchResponse, error) {
 	response := &pb.ShortBatchResponse{}
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
 
-	shortURLsMap := make(map[string]models.ShortenURL, len(in.Original))
+	items := make([]batchItem, 0, len(in.Original))
 	for _, batchRequest := range in.Original {
-		shortURL := models.NewShortURL(batchRequest.OriginalUrl, in.UserId)
-		shortURLsMap[batchRequest.CorrelationId] = shortURL
+		items = append(items, batchItem{correlationID: batchRequest.CorrelationId, params: shortenParams{
+			originalURL: batchRequest.OriginalUrl,
+			userID:      userID,
+			alias:       batchRequest.Alias,
+			expiresAt:   timestampToTime(batchRequest.ExpiresAt),
+			maxClicks:   int(batchRequest.MaxClicks),
+		}})
 	}
 
-	err := s.storage.StoreBatch(ctx, shortURLsMap)
+	// invalid items are reported in their results, so error is failure of storage as a whole
+	results, err := shortenBatch(ctx, s.storage, s.options.BaseURL, items)
 	if err != nil {
-		return response, status.Errorf(codes.InvalidArgument, err.Error())
+		return response, status.Errorf(storageCode(err), err.Error())
 	}
 
-	for correlationID, shortenedURL := range shortURLsMap {
-		response.Short = append(response.Short, &pb.CorrelatedShortURL{
-			CorrelationId: correlationID,
-			ShortUrl:      fmt.Sprintf("%s/%s", s.options.BaseURL, shortenedURL.UUID),
-		})
+	for _, result := range results {
+		shortURL := &pb.CorrelatedShortURL{CorrelationId: result.correlationID, ShortUrl: result.shortURL, Status: batchItemStatuses[result.status]}
+		if result.err != nil {
+			shortURL.Error = result.err.Error()
+		}
+		response.Short = append(response.Short, shortURL)
 	}
 
 	return response, nil
 }
 
+// storageCode - gRPC code of storage failure: cancelled and timed out requests keep their codes,
+// lost connection to storage is Unavailable, so client may retry, everything else is Internal
+func storageCode(err error) codes.Code {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.As(err, &netErr):
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// ImportURLs - handler for bulk import of streamed URLs, they are stored by chunks as they are received
+// and results of every chunk are sent back at once, so response never holds whole import
+func (s *ShortenerServer) ImportURLs(stream pb.ShortenerService_ImportURLsServer) error {
+	userID, err := requestUserID(stream.Context(), "")
+	if err != nil {
+		return err
+	}
+
+	var created, existing, invalid int64
+	chunk := make([]batchItem, 0, importChunkSize)
+	store := func() error {
+		results, err := shortenBatch(stream.Context(), s.storage, s.options.BaseURL, chunk)
+		if err != nil {
+			return status.Errorf(storageCode(err), "%d URLs are imported: %s", created+existing, err.Error())
+		}
+		response := &pb.ImportURLsResponse{Short: make([]*pb.CorrelatedShortURL, 0, len(results))}
+		for _, result := range results {
+			shortURL := &pb.CorrelatedShortURL{CorrelationId: result.correlationID, ShortUrl: result.shortURL, Status: batchItemStatuses[result.status]}
+			switch result.status {
+			case dto.BatchStatusCreated:
+				created++
+			case dto.BatchStatusExisting:
+				existing++
+			default:
+				invalid++
+				shortURL.Error = result.err.Error()
+			}
+			response.Short = append(response.Short, shortURL)
+		}
+		response.Created, response.Existing, response.Invalid = created, existing, invalid
+		chunk = chunk[:0]
+		return stream.Send(response)
+	}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = requestUserID(stream.Context(), in.UserId); err != nil {
+			return err
+		}
+		for _, original := range in.Original {
+			chunk = append(chunk, batchItem{correlationID: original.CorrelationId, params: shortenParams{
+				originalURL: original.OriginalUrl,
+				userID:      userID,
+				alias:       original.Alias,
+				expiresAt:   timestampToTime(original.ExpiresAt),
+				maxClicks:   int(original.MaxClicks),
+			}})
+			if len(chunk) == importChunkSize {
+				if err = store(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(chunk) > 0 {
+		return store()
+	}
+	return nil
+}
+
+// batchItemStatuses - gRPC statuses of shortened URLs of batch by dto batch statuses
+var batchItemStatuses = map[string]pb.BatchItemStatus{
+	dto.BatchStatusCreated:  pb.BatchItemStatus_BATCH_ITEM_STATUS_CREATED,
+	dto.BatchStatusExisting: pb.BatchItemStatus_BATCH_ITEM_STATUS_EXISTING,
+	dto.BatchStatusInvalid:  pb.BatchItemStatus_BATCH_ITEM_STATUS_INVALID,
+}
+
 // Stats - handler to check internal service stats
 func (s *ShortenerServer) Stats(ctx context.Context, in *pb.StatsRequest) (*pb.StatsResponse, error) {
 	response := &pb.StatsResponse{}
-	urls, users, err := s.storage.GetStats(ctx)
+	stats, err := s.storage.GetStats(ctx)
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, err.Error())
 	}
 
-	response.Urls = int64(urls)
-	response.Users = int64(users)
+	response.Urls = int64(stats.URLs)
+	response.Users = int64(stats.Users)
+	response.Active = int64(stats.Active)
+	response.Deleted = int64(stats.Deleted)
+	response.Purged = int64(stats.Purged)
 
 	return response, nil
 }
 
+// MigrationStatus - handler to check schema migrations of storage
+func (s *ShortenerServer) MigrationStatus(ctx context.Context, in *pb.MigrationStatusRequest) (*pb.MigrationStatusResponse, error) {
+	response := &pb.MigrationStatusResponse{}
+	migrationStatus, err := s.storage.MigrationStatus(ctx)
+	if errors.Is(err, storage.ErrNoMigrations) {
+		return response, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+
+	response.Version = uint64(migrationStatus.Version)
+	response.Latest = uint64(migrationStatus.Latest)
+	response.Pending = int64(migrationStatus.Pending)
+	response.Dirty = migrationStatus.Dirty
+
+	return response, nil
+}
+
+// ExportURLs - handler for export of all short URLs of authorized user, short URLs are streamed in creation order
+func (s *ShortenerServer) ExportURLs(in *pb.ExportURLsRequest, stream pb.ShortenerService_ExportURLsServer) error {
+	userID, err := requestUserID(stream.Context(), in.UserId)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.WithValue(stream.Context(), auth.ContextUserKey, userID)
+	err = exportUserURLs(ctx, s.storage, in.IncludeDeleted, func(shortURLs []models.ShortenURL) error {
+		for _, shortURL := range shortURLs {
+			err := stream.Send(&pb.ExportedURL{
+				ShortId:     shortURL.UUID,
+				ShortUrl:    fmt.Sprintf("%s/%s", s.options.BaseURL, shortURL.UUID),
+				OriginalUrl: shortURL.OriginalURL,
+				IsAlias:     shortURL.IsAlias,
+				CreatedAt:   timeToTimestamp(shortURL.CreatedAt),
+				ExpiresAt:   timeToTimestamp(shortURL.ExpiresAt),
+				MaxClicks:   int64(shortURL.MaxClicks),
+				Clicks:      int64(shortURL.Clicks),
+				IsDeleted:   shortURL.DeletedFlag,
+				DeletedAt:   timeToTimestamp(shortURL.DeletedAt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if _, isStatus := status.FromError(err); !isStatus {
+		return status.Errorf(codes.Internal, "export is broken: %s", err.Error())
+	}
+	return err
+}
+
+// StreamClicks - handler for live feed of clicks on user's short URLs
+func (s *ShortenerServer) StreamClicks(in *pb.StreamClicksRequest, stream pb.ShortenerService_StreamClicksServer) error {
+	if s.clickHub == nil {
+		return status.Errorf(codes.Unavailable, "clicks streaming is disabled")
+	}
+
+	userID, err := requestUserID(stream.Context(), in.UserId)
+	if err != nil {
+		return err
+	}
+
+	subscription := s.clickHub.Subscribe(userID, in.ShortId)
+	defer s.clickHub.Unsubscribe(subscription)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case click := <-subscription.Clicks():
+			err := stream.Send(&pb.ClickEvent{
+				ShortId:   click.ShortURL,
+				Timestamp: timestamppb.New(click.Timestamp),
+				Referrer:  click.Referrer,
+				UserAgent: click.UserAgent,
+				Ip:        click.IP,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// UpdateURL - handler for change of user's short URL destination to new URL or to version from its history
+func (s *ShortenerServer) UpdateURL(ctx context.Context, in *pb.UpdateURLRequest) (*pb.UpdateURLResponse, error) {
+	response := &pb.UpdateURLResponse{}
+	if _, err := requestUserID(ctx, in.UserId); err != nil {
+		return response, err
+	}
+
+	var err error
+	switch destination := in.Destination.(type) {
+	case *pb.UpdateURLRequest_Url:
+		if err = validateOriginalURL(destination.Url); err != nil {
+			return response, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		err = s.storage.UpdateURL(ctx, in.ShortId, destination.Url)
+	case *pb.UpdateURLRequest_RestoreVersion:
+		err = restoreURLVersion(ctx, s.storage, in.ShortId, int(destination.RestoreVersion))
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound) || errors.Is(err, ErrUnknownVersion):
+		return response, status.Errorf(codes.NotFound, err.Error())
+	case errors.Is(err, storage.ErrConflict):
+		return response, status.Errorf(codes.AlreadyExists, "user already has short URL with such destination")
+	case err != nil:
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+
+	response.Result = fmt.Sprintf("%s/%s", s.options.BaseURL, in.ShortId)
+	return response, nil
+}
+
+// GetURLHistory - handler for previous destinations of user's short URL
+func (s *ShortenerServer) GetURLHistory(ctx context.Context, in *pb.GetURLHistoryRequest) (*pb.GetURLHistoryResponse, error) {
+	response := &pb.GetURLHistoryResponse{}
+	userID, err := requestUserID(ctx, in.UserId)
+	if err != nil {
+		return response, err
+	}
+
+	shortURL, err := s.storage.FindByID(ctx, in.ShortId)
+	if err != nil || shortURL.UserID != userID {
+		return response, status.Errorf(codes.NotFound, storage.ErrNotFound.Error())
+	}
+	versions, err := s.storage.GetURLHistory(ctx, in.ShortId)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, err.Error())
+	}
+
+	for _, version := range versions {
+		response.Versions = append(response.Versions, &pb.URLVersion{
+			Version:     int32(version.Version),
+			OriginalUrl: version.OriginalURL,
+			ReplacedAt:  timestamppb.New(version.ReplacedAt),
+		})
+	}
+	return response, nil
+}
+
+// requestUserID - returns UserID of authorized caller, user_id of request is optional but has to match it
+func requestUserID(ctx context.Context, requestUserID string) (string, error) {
+	userID, _ := ctx.Value(auth.ContextUserKey).(string)
+	if userID == "" {
+		return "", status.Errorf(codes.Unauthenticated, "unauthorized requests forbidden")
+	}
+	if requestUserID != "" && requestUserID != userID {
+		return "", status.Errorf(codes.PermissionDenied, "user_id does not match authorized user")
+	}
+	return userID, nil
+}
+
+// timestampToTime - convert optional protobuf timestamp to time, nil becomes zero time
+func timestampToTime(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.AsTime()
+}
+
+// timeToTimestamp - convert time to optional protobuf timestamp, zero time becomes nil
+func timeToTimestamp(moment time.Time) *timestamppb.Timestamp {
+	if moment.IsZero() {
+		return nil
+	}
+	return timestamppb.New(moment)
+}
+
 // NewShortenerServer - creates new gRPC server instance
-func NewShortenerServer(options *config.Options, storage *storage.Repository) *ShortenerServer {
+func NewShortenerServer(options *config.Options, storage *storage.Repository, clickHub *async.ClickHub) *ShortenerServer {
 	s := ShortenerServer{
-		options: options,
-		storage: *storage,
+		options:  options,
+		storage:  *storage,
+		clickHub: clickHub,
 	}
 	return &s
 }
+
+// parseSubnets - parse comma separated CIDR addresses, empty value has no subnets
+func parseSubnets(value string) (subnets []*net.IPNet, err error) {
+	for _, cidr := range strings.Split(value, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		subnets = append(subnets, subnet)
+	}
+	return
+}
+
+// NewGRPCServer - creates gRPC server with registered shortener service and its interceptors
+func NewGRPCServer(shortenerServer *ShortenerServer) (*grpc.Server, error) {
+	validation, err := interceptors.NewValidation()
+	if err != nil {
+		return nil, err
+	}
+
+	var trustedSubnet *net.IPNet
+	if shortenerServer.options.TrustedSubnet != "" {
+		_, trustedSubnet, err = net.ParseCIDR(shortenerServer.options.TrustedSubnet)
+		if err != nil {
+			return nil, err
+		}
+	}
+	trustedProxies, err := parseSubnets(shortenerServer.options.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	authorization := auth.NewGRPCAuthorization(
+		[]string{
+			pb.ShortenerService_Short_FullMethodName,
+			pb.ShortenerService_Expand_FullMethodName,
+			pb.ShortenerService_ShortBatch_FullMethodName,
+			pb.ShortenerService_ImportURLs_FullMethodName,
+		},
+		[]string{pb.ShortenerService_Stats_FullMethodName, pb.ShortenerService_MigrationStatus_FullMethodName},
+		trustedSubnet,
+		trustedProxies,
+	)
+
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor, authorization.Unary, validation.Unary),
+		grpc.ChainStreamInterceptor(authorization.Stream, validation.Stream),
+	)
+	pb.RegisterShortenerServiceServer(s, shortenerServer)
+	return s, nil
+}
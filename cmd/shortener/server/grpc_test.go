@@ -2,9 +2,18 @@ package server
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/PaBah/url-shortener.git/internal/async"
+	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/config"
 	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
 	"github.com/PaBah/url-shortener.git/internal/mock"
@@ -13,8 +22,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func Test_Short(t *testing.T) {
@@ -41,20 +55,20 @@ func Test_Short(t *testing.T) {
 
 	rm.
 		EXPECT().
-		Store(gomock.Any(), gomock.Eq(models.NewShortURL("https://practicum.yandex.ru/", "1"))).
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(newTestShortURL(t, "https://practicum.yandex.ru/", "1")))).
 		Return(nil).
 		AnyTimes()
 	rm.
 		EXPECT().
-		Store(gomock.Any(), gomock.Eq(models.NewShortURL("https://bad.url.ru/", "1"))).
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(newTestShortURL(t, "https://bad.url.ru/", "1")))).
 		Return(storage.ErrConflict).
 		AnyTimes()
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run("Store", func(t *testing.T) {
-			result, err := sh.Short(context.Background(), &pb.ShortRequest{Url: tc.requestURL, UserId: "1"})
+			result, err := sh.Short(userContext("1"), &pb.ShortRequest{Url: tc.requestURL, UserId: "1"})
 
 			assert.Equal(t, tc.expectedResult, result.Result, "Expected result get")
 			if tc.expectedError {
@@ -66,6 +80,57 @@ func Test_Short(t *testing.T) {
 	}
 }
 
+func Test_Short_with_alias(t *testing.T) {
+	testCases := []struct {
+		alias          string
+		expectedResult string
+		expectedError  bool
+		errorCode      codes.Code
+	}{
+		{alias: "spring-sale", expectedError: false, expectedResult: "spring-sale"},
+		{alias: "api", expectedError: true, errorCode: codes.InvalidArgument},
+		{alias: "taken-alias", expectedError: true, errorCode: codes.AlreadyExists},
+	}
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+		DatabaseDSN:   "wrong DSN",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	rm.
+		EXPECT().
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(models.NewAliasShortURL("spring-sale", "https://practicum.yandex.ru/", "1")))).
+		Return(nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(models.NewAliasShortURL("taken-alias", "https://practicum.yandex.ru/", "1")))).
+		Return(storage.ErrAliasTaken).
+		AnyTimes()
+
+	sh := NewShortenerServer(options, &store, nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.alias, func(t *testing.T) {
+			result, err := sh.Short(userContext("1"), &pb.ShortRequest{Url: "https://practicum.yandex.ru/", UserId: "1", Alias: tc.alias})
+
+			assert.Equal(t, tc.expectedResult, result.Result, "Expected result get")
+			if tc.expectedError {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tc.errorCode, e.Code(), "Expected error code get")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func Test_Expand(t *testing.T) {
 	testCases := []struct {
 		storage        storage.Repository
@@ -76,6 +141,7 @@ func Test_Expand(t *testing.T) {
 	}{
 		{shortID: "2187b119", expectedError: false, expectedResult: "https://practicum.yandex.ru/"},
 		{shortID: "4e76a198", expectedError: true, errorCode: codes.InvalidArgument, expectedResult: ""},
+		{shortID: "expired1", expectedError: true, errorCode: codes.FailedPrecondition, expectedResult: ""},
 	}
 	options := &config.Options{
 		ServerAddress: ":8080",
@@ -91,19 +157,24 @@ func Test_Expand(t *testing.T) {
 	rm.
 		EXPECT().
 		FindByID(gomock.Any(), "2187b119").
-		Return(models.NewShortURL("https://practicum.yandex.ru/", "1"), nil).
+		Return(newTestShortURL(t, "https://practicum.yandex.ru/", "1"), nil).
 		AnyTimes()
 	rm.
 		EXPECT().
 		FindByID(gomock.Any(), "4e76a198").
 		Return(models.ShortenURL{OriginalURL: "https://practicum.yandex.ru/", UserID: "1", DeletedFlag: true}, nil).
 		AnyTimes()
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "expired1").
+		Return(models.ShortenURL{OriginalURL: "https://practicum.yandex.ru/", UserID: "1", MaxClicks: 1, Clicks: 1}, nil).
+		AnyTimes()
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.shortID, func(t *testing.T) {
-			result, err := sh.Expand(context.Background(), &pb.ExpandRequest{ShortId: tc.shortID})
+			result, err := sh.Expand(userContext("1"), &pb.ExpandRequest{ShortId: tc.shortID})
 
 			assert.Equal(t, tc.expectedResult, result.Url, "Expected result get")
 			if tc.expectedError {
@@ -139,11 +210,11 @@ func Test_Delete(t *testing.T) {
 		AsyncCheckURLsUserID(gomock.Eq("1"), gomock.Any()).
 		Return(make(chan string)).AnyTimes()
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.shortID, func(t *testing.T) {
-			_, err := sh.Delete(context.Background(), &pb.DeleteRequest{Id: []string{tc.shortID}, UserId: "1"})
+			_, err := sh.Delete(userContext("1"), &pb.DeleteRequest{Id: []string{tc.shortID}, UserId: "1"})
 			assert.NoError(t, err, "Expected success")
 			//if tc.expectedError {
 			//	e, ok := status.FromError(err)
@@ -154,6 +225,33 @@ func Test_Delete(t *testing.T) {
 	}
 }
 
+func Test_Restore(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	rm.
+		EXPECT().
+		RestoreShortURLs(gomock.Any(), []string{"2187b119", "other123"}).
+		Return([]string{"2187b119"}, nil).
+		Times(1)
+
+	sh := NewShortenerServer(options, &store, nil)
+	result, err := sh.Restore(userContext("1"), &pb.RestoreRequest{Id: []string{"2187b119", "other123"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2187b119"}, result.Id, "only restored short IDs returned")
+
+	_, err = sh.Restore(context.Background(), &pb.RestoreRequest{Id: []string{"2187b119"}})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
 func Test_GetUserBucket(t *testing.T) {
 	testCases := []struct {
 		storage        storage.Repository
@@ -178,20 +276,20 @@ func Test_GetUserBucket(t *testing.T) {
 
 	rm.
 		EXPECT().
-		GetAllUsers(gomock.Any()).
-		Return([]models.ShortenURL{models.NewShortURL("https://practicum.yandex.kz/", "1")}, nil).
+		ListUserURLs(gomock.Any(), gomock.Any()).
+		Return(models.URLsPage{URLs: []models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.kz/", "1")}}, nil).
 		Times(1)
 	rm.
 		EXPECT().
-		GetAllUsers(gomock.Any()).
-		Return([]models.ShortenURL{}, errors.New("Error")).
+		ListUserURLs(gomock.Any(), gomock.Any()).
+		Return(models.URLsPage{}, errors.New("Error")).
 		Times(1)
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.userID, func(t *testing.T) {
-			result, err := sh.GetUserBucket(context.Background(), &pb.GetUserBucketRequest{UserId: tc.userID})
+			result, err := sh.GetUserBucket(userContext("1"), &pb.GetUserBucketRequest{UserId: tc.userID})
 
 			if tc.expectedError {
 				e, ok := status.FromError(err)
@@ -204,6 +302,45 @@ func Test_GetUserBucket(t *testing.T) {
 	}
 }
 
+func Test_GetUserBucket_pagination(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	rm.
+		EXPECT().
+		ListUserURLs(gomock.Any(), gomock.Eq(models.ListOptions{
+			Limit:  2,
+			Cursor: "cursor",
+			SortBy: models.SortByOriginalURL,
+			Desc:   true,
+			Status: models.StatusActive,
+			Search: "sale",
+		})).
+		Return(models.URLsPage{URLs: []models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.kz/sale", "1")}, NextCursor: "next"}, nil).
+		Times(1)
+
+	sh := NewShortenerServer(options, &store, nil)
+	result, err := sh.GetUserBucket(userContext("1"), &pb.GetUserBucketRequest{
+		Limit:  2,
+		Cursor: "cursor",
+		SortBy: models.SortByOriginalURL,
+		Desc:   true,
+		Status: models.StatusActive,
+		Search: "sale",
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "next", result.NextCursor, "Cursor of next page returned")
+}
+
 func Test_ShortBatch(t *testing.T) {
 	testCases := []struct {
 		storage        storage.Repository
@@ -220,7 +357,13 @@ func Test_ShortBatch(t *testing.T) {
 		{
 			request:        "https://practicum.yandex.kz/",
 			expectedError:  true,
-			errorCode:      codes.InvalidArgument,
+			errorCode:      codes.Internal,
+			expectedResult: "",
+		},
+		{
+			request:        "https://practicum.yandex.ru/",
+			expectedError:  true,
+			errorCode:      codes.Unavailable,
 			expectedResult: "",
 		},
 	}
@@ -237,20 +380,25 @@ func Test_ShortBatch(t *testing.T) {
 
 	rm.
 		EXPECT().
-		StoreBatch(gomock.Any(), gomock.Eq(map[string]models.ShortenURL{"1": models.NewShortURL("https://practicum.yandex.kz/", "1")})).
-		Return(nil).
+		StoreBatch(gomock.Any(), gomock.Eq([]models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.kz/", "1")})).
+		Return([]models.BatchResult{{Status: models.BatchCreated, ShortURL: newTestShortURL(t, "https://practicum.yandex.kz/", "1")}}, nil).
 		Times(1)
 	rm.
 		EXPECT().
-		StoreBatch(gomock.Any(), gomock.Eq(map[string]models.ShortenURL{"1": models.NewShortURL("https://practicum.yandex.kz/", "1")})).
-		Return(errors.New("Error")).
+		StoreBatch(gomock.Any(), gomock.Eq([]models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.kz/", "1")})).
+		Return(nil, errors.New("Error")).
+		Times(1)
+	rm.
+		EXPECT().
+		StoreBatch(gomock.Any(), gomock.Eq([]models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.ru/", "1")})).
+		Return(nil, fmt.Errorf("batch is not stored: %w", driver.ErrBadConn)).
 		Times(1)
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.request, func(t *testing.T) {
-			result, err := sh.ShortBatch(context.Background(), &pb.ShortBatchRequest{
+			result, err := sh.ShortBatch(userContext("1"), &pb.ShortBatchRequest{
 				UserId: "1",
 				Original: []*pb.CorrelatedOriginalURL{
 					{
@@ -265,11 +413,160 @@ func Test_ShortBatch(t *testing.T) {
 				assert.Equal(t, tc.errorCode, e.Code(), "Expected error code get")
 			} else {
 				assert.Equal(t, tc.expectedResult, result.Short[0].ShortUrl, "Expected result get")
+				assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_CREATED, result.Short[0].Status)
 			}
 		})
 	}
 }
 
+func Test_ShortBatch_statuses(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	var store storage.Repository = storage.NewMemoryStorage()
+	stored := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	require.NoError(t, store.Store(context.Background(), &stored))
+
+	sh := NewShortenerServer(options, &store, nil)
+	result, err := sh.ShortBatch(userContext("1"), &pb.ShortBatchRequest{
+		Original: []*pb.CorrelatedOriginalURL{
+			{CorrelationId: "new", OriginalUrl: "https://practicum.yandex.kz/"},
+			{CorrelationId: "old", OriginalUrl: "https://practicum.yandex.ru/"},
+			{CorrelationId: "reserved", OriginalUrl: "https://practicum.yandex.ru/sale", Alias: "ping"},
+			{CorrelationId: "9", OriginalUrl: "https://ya.ru/"},
+			{CorrelationId: "10", OriginalUrl: "https://ya.ru/"},
+		}})
+	require.NoError(t, err, "partial success is not an error")
+	require.Len(t, result.Short, 5)
+	assert.Equal(t, "new", result.Short[0].CorrelationId, "request order kept")
+	assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_CREATED, result.Short[0].Status)
+	assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_EXISTING, result.Short[1].Status)
+	assert.Equal(t, "http://localhost:8080/2187b119", result.Short[1].ShortUrl, "stored short URL returned")
+	assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_INVALID, result.Short[2].Status)
+	assert.NotEmpty(t, result.Short[2].Error)
+	assert.Empty(t, result.Short[2].ShortUrl)
+	assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_CREATED, result.Short[3].Status, "earlier item of URL shortened twice wins")
+	assert.Equal(t, pb.BatchItemStatus_BATCH_ITEM_STATUS_EXISTING, result.Short[4].Status)
+	assert.Equal(t, result.Short[3].ShortUrl, result.Short[4].ShortUrl)
+}
+
+func Test_ImportURLs(t *testing.T) {
+	const userID = "48c01326-079e-4092-a903-b994a5b62b21"
+
+	var store storage.Repository = storage.NewMemoryStorage()
+	stored := newTestShortURL(t, "https://practicum.yandex.ru/", userID)
+	require.NoError(t, store.Store(context.Background(), &stored))
+	client := newTestGRPCClient(t, store)
+	JWTToken, _ := auth.BuildJWTString(userID)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationMetadataKey, "Bearer "+JWTToken)
+
+	// receiveAll - close sending and collect every response of import stream
+	receiveAll := func(stream pb.ShortenerService_ImportURLsClient) (responses []*pb.ImportURLsResponse, err error) {
+		require.NoError(t, stream.CloseSend())
+		for {
+			response, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return responses, nil
+			}
+			if err != nil {
+				return responses, err
+			}
+			responses = append(responses, response)
+		}
+	}
+
+	stream, err := client.ImportURLs(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.ImportURLsRequest{Original: []*pb.CorrelatedOriginalURL{
+		{CorrelationId: "new", OriginalUrl: "https://practicum.yandex.kz/"},
+		{CorrelationId: "old", OriginalUrl: "https://practicum.yandex.ru/"},
+	}}))
+	require.NoError(t, stream.Send(&pb.ImportURLsRequest{UserId: userID, Original: []*pb.CorrelatedOriginalURL{
+		{CorrelationId: "reserved", OriginalUrl: "https://practicum.yandex.ru/sale", Alias: "ping"},
+	}}))
+	responses, err := receiveAll(stream)
+	require.NoError(t, err)
+	require.Len(t, responses, 1, "URLs smaller than chunk are stored at once")
+	result := responses[0]
+	assert.Equal(t, int64(1), result.Created)
+	assert.Equal(t, int64(1), result.Existing)
+	assert.Equal(t, int64(1), result.Invalid)
+	require.Len(t, result.Short, 3)
+	assert.Equal(t, "old", result.Short[1].CorrelationId, "stream order kept")
+	assert.Equal(t, "http://localhost:8080/2187b119", result.Short[1].ShortUrl)
+	assert.NotEmpty(t, result.Short[2].Error)
+
+	stream, err = client.ImportURLs(ctx)
+	require.NoError(t, err)
+	originals := make([]*pb.CorrelatedOriginalURL, 0, importChunkSize+1)
+	for i := 0; i <= importChunkSize; i++ {
+		originals = append(originals, &pb.CorrelatedOriginalURL{CorrelationId: strconv.Itoa(i), OriginalUrl: fmt.Sprintf("https://ya.ru/%d", i)})
+	}
+	require.NoError(t, stream.Send(&pb.ImportURLsRequest{Original: originals}))
+	responses, err = receiveAll(stream)
+	require.NoError(t, err)
+	require.Len(t, responses, 2, "results are streamed per stored chunk")
+	assert.Len(t, responses[0].Short, importChunkSize)
+	assert.Equal(t, int64(importChunkSize), responses[0].Created)
+	require.Len(t, responses[1].Short, 1)
+	assert.Equal(t, strconv.Itoa(importChunkSize), responses[1].Short[0].CorrelationId)
+	assert.Equal(t, int64(importChunkSize+1), responses[1].Created, "counters are totals of import")
+
+	stream, err = client.ImportURLs(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.ImportURLsRequest{UserId: "1"}))
+	_, err = receiveAll(stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "invalid message aborts import")
+}
+
+func Test_ExportURLs(t *testing.T) {
+	const userID = "48c01326-079e-4092-a903-b994a5b62b21"
+
+	var store storage.Repository = storage.NewMemoryStorage()
+	ctx := userContext(userID)
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err := store.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: userID, CreatedAt: createdAt},
+		{UUID: "sale", OriginalURL: "https://ya.ru/", UserID: userID, IsAlias: true, CreatedAt: createdAt.Add(time.Hour), MaxClicks: 3},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteShortURLs(ctx, []string{"2187b119"}))
+	client := newTestGRPCClient(t, store)
+	JWTToken, _ := auth.BuildJWTString(userID)
+	outgoing := metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationMetadataKey, "Bearer "+JWTToken)
+
+	receive := func(request *pb.ExportURLsRequest) (exported []*pb.ExportedURL) {
+		stream, err := client.ExportURLs(outgoing, request)
+		require.NoError(t, err)
+		for {
+			shortURL, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			require.NoError(t, err)
+			exported = append(exported, shortURL)
+		}
+	}
+
+	exported := receive(&pb.ExportURLsRequest{})
+	require.Len(t, exported, 1, "deleted short URL is not exported by default")
+	assert.Equal(t, "sale", exported[0].ShortId)
+	assert.Equal(t, "http://localhost:8080/sale", exported[0].ShortUrl)
+	assert.True(t, exported[0].IsAlias)
+	assert.Equal(t, int64(3), exported[0].MaxClicks)
+	assert.Nil(t, exported[0].ExpiresAt)
+
+	exported = receive(&pb.ExportURLsRequest{UserId: userID, IncludeDeleted: true})
+	require.Len(t, exported, 2)
+	assert.Equal(t, "2187b119", exported[0].ShortId, "creation order")
+	assert.True(t, exported[0].IsDeleted)
+	assert.NotNil(t, exported[0].DeletedAt)
+	assert.Equal(t, createdAt, exported[0].CreatedAt.AsTime())
+
+	stream, err := client.ExportURLs(context.Background(), &pb.ExportURLsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
 func Test_Stats(t *testing.T) {
 	testCases := []struct {
 		storage        storage.Repository
@@ -301,15 +598,15 @@ func Test_Stats(t *testing.T) {
 	rm.
 		EXPECT().
 		GetStats(gomock.Any()).
-		Return(2, 1, nil).
+		Return(models.Stats{URLs: 2, Users: 1, Active: 2}, nil).
 		Times(1)
 	rm.
 		EXPECT().
 		GetStats(gomock.Any()).
-		Return(0, 0, errors.New("Error")).
+		Return(models.Stats{}, errors.New("Error")).
 		Times(1)
 
-	sh := NewShortenerServer(options, &store)
+	sh := NewShortenerServer(options, &store, nil)
 
 	for _, tc := range testCases {
 		t.Run("test", func(t *testing.T) {
@@ -326,3 +623,375 @@ func Test_Stats(t *testing.T) {
 		})
 	}
 }
+
+type clickEventsStream struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	events chan *pb.ClickEvent
+}
+
+func (s *clickEventsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *clickEventsStream) Send(event *pb.ClickEvent) error {
+	s.events <- event
+	return nil
+}
+
+func Test_StreamClicks(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	store = mock.NewMockRepository(ctrl)
+
+	err := NewShortenerServer(options, &store, nil).StreamClicks(&pb.StreamClicksRequest{}, &clickEventsStream{ctx: context.Background()})
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unavailable, e.Code(), "Streaming without hub is unavailable")
+
+	clickHub := async.NewClickHub()
+	sh := NewShortenerServer(options, &store, clickHub)
+	ctx, cancel := context.WithCancel(userContext("1"))
+	stream := &clickEventsStream{ctx: ctx, events: make(chan *pb.ClickEvent, 1024)}
+
+	done := make(chan error)
+	go func() {
+		done <- sh.StreamClicks(&pb.StreamClicksRequest{ShortId: []string{"2187b119"}}, stream)
+	}()
+	require.Eventually(t, func() bool {
+		clickHub.Publish("2", models.NewClick("2187b119", "", "", "127.0.0.1"))
+		clickHub.Publish("1", models.NewClick("bc2c0be9", "", "", "127.0.0.1"))
+		clickHub.Publish("1", models.NewClick("2187b119", "https://ya.ru/", "curl", "127.0.0.1"))
+		return len(stream.events) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	event := <-stream.events
+	assert.Equal(t, "2187b119", event.ShortId)
+	assert.Equal(t, "https://ya.ru/", event.Referrer)
+	assert.Equal(t, "curl", event.UserAgent)
+	assert.Equal(t, "127.0.0.0", event.Ip)
+
+	cancel()
+	assert.NoError(t, <-done, "Stream finished with client")
+}
+
+func Test_UpdateURL(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	replacedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "2187b119", "https://practicum.yandex.kz/").
+		Return(nil).
+		Times(1)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "other123", gomock.Any()).
+		Return(storage.ErrNotFound).
+		Times(1)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "2187b119", "https://practicum.yandex.ru/").
+		Return(nil).
+		Times(1)
+	rm.
+		EXPECT().
+		GetURLHistory(gomock.Any(), "2187b119").
+		Return([]models.URLVersion{{ShortURL: "2187b119", Version: 1, OriginalURL: "https://practicum.yandex.ru/", ReplacedAt: replacedAt}}, nil).
+		Times(3)
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "2187b119").
+		Return(newTestShortURL(t, "https://practicum.yandex.kz/", "1"), nil).
+		Times(1)
+
+	sh := NewShortenerServer(options, &store, nil)
+
+	result, err := sh.UpdateURL(userContext("1"), &pb.UpdateURLRequest{
+		ShortId:     "2187b119",
+		Destination: &pb.UpdateURLRequest_Url{Url: "https://practicum.yandex.kz/"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/2187b119", result.Result)
+
+	_, err = sh.UpdateURL(userContext("1"), &pb.UpdateURLRequest{
+		ShortId:     "other123",
+		Destination: &pb.UpdateURLRequest_Url{Url: "https://practicum.yandex.kz/"},
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err), "URL of another user")
+
+	_, err = sh.UpdateURL(userContext("1"), &pb.UpdateURLRequest{
+		ShortId:     "2187b119",
+		Destination: &pb.UpdateURLRequest_RestoreVersion{RestoreVersion: 1},
+	})
+	assert.NoError(t, err, "version from history restored")
+
+	_, err = sh.UpdateURL(userContext("1"), &pb.UpdateURLRequest{
+		ShortId:     "2187b119",
+		Destination: &pb.UpdateURLRequest_RestoreVersion{RestoreVersion: 2},
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err), "unknown version")
+
+	history, err := sh.GetURLHistory(userContext("1"), &pb.GetURLHistoryRequest{ShortId: "2187b119"})
+	require.NoError(t, err)
+	require.Len(t, history.Versions, 1)
+	assert.Equal(t, "https://practicum.yandex.ru/", history.Versions[0].OriginalUrl)
+	assert.Equal(t, replacedAt, history.Versions[0].ReplacedAt.AsTime())
+}
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.ContextUserKey, userID)
+}
+
+func newTestGRPCClient(t *testing.T, store storage.Repository) pb.ShortenerServiceClient {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+		TrustedSubnet: "10.0.0.0/8",
+		// TrustedProxies - test client connects from loopback, so its x-real-ip metadata is trusted
+		TrustedProxies: "127.0.0.0/8",
+	}
+	s, err := NewGRPCServer(NewShortenerServer(options, &store, async.NewClickHub()))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewShortenerServiceClient(conn)
+}
+
+func Test_Validation(t *testing.T) {
+	const userID = "48c01326-079e-4092-a903-b994a5b62b21"
+
+	ctrl := gomock.NewController(t)
+	client := newTestGRPCClient(t, mock.NewMockRepository(ctrl))
+	JWTToken, _ := auth.BuildJWTString(userID)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationMetadataKey, "Bearer "+JWTToken)
+
+	testCases := []struct {
+		name          string
+		call          func() error
+		expectedField string
+	}{
+		{
+			name: "Short with not UUID user_id",
+			call: func() error {
+				_, err := client.Short(ctx, &pb.ShortRequest{UserId: "1", Url: "https://practicum.yandex.ru/"})
+				return err
+			},
+			expectedField: "user_id",
+		},
+		{
+			name: "Short with invalid url",
+			call: func() error {
+				_, err := client.Short(ctx, &pb.ShortRequest{UserId: userID, Url: "practicum"})
+				return err
+			},
+			expectedField: "url",
+		},
+		{
+			name: "Short with invalid alias",
+			call: func() error {
+				_, err := client.Short(ctx, &pb.ShortRequest{UserId: userID, Url: "https://practicum.yandex.ru/", Alias: "sale!"})
+				return err
+			},
+			expectedField: "alias",
+		},
+		{
+			name: "Short with negative max_clicks",
+			call: func() error {
+				_, err := client.Short(ctx, &pb.ShortRequest{UserId: userID, Url: "https://practicum.yandex.ru/", MaxClicks: -1, ExpiresAt: timestamppb.Now()})
+				return err
+			},
+			expectedField: "max_clicks",
+		},
+		{
+			name: "Expand with empty short_id",
+			call: func() error {
+				_, err := client.Expand(ctx, &pb.ExpandRequest{})
+				return err
+			},
+			expectedField: "short_id",
+		},
+		{
+			name: "Expand with too long short_id",
+			call: func() error {
+				_, err := client.Expand(ctx, &pb.ExpandRequest{ShortId: strings.Repeat("a", 65)})
+				return err
+			},
+			expectedField: "short_id",
+		},
+		{
+			name: "Delete with not UUID user_id",
+			call: func() error {
+				_, err := client.Delete(ctx, &pb.DeleteRequest{UserId: "1", Id: []string{"2187b119"}})
+				return err
+			},
+			expectedField: "user_id",
+		},
+		{
+			name: "Delete with empty id",
+			call: func() error {
+				_, err := client.Delete(ctx, &pb.DeleteRequest{UserId: userID, Id: []string{""}})
+				return err
+			},
+			expectedField: "id[0]",
+		},
+		{
+			name: "GetUserBucket with not UUID user_id",
+			call: func() error {
+				_, err := client.GetUserBucket(ctx, &pb.GetUserBucketRequest{UserId: "1"})
+				return err
+			},
+			expectedField: "user_id",
+		},
+		{
+			name: "ShortBatch with empty correlation_id",
+			call: func() error {
+				_, err := client.ShortBatch(ctx, &pb.ShortBatchRequest{UserId: userID, Original: []*pb.CorrelatedOriginalURL{
+					{OriginalUrl: "https://practicum.yandex.ru/"},
+				}})
+				return err
+			},
+			expectedField: "original[0].correlation_id",
+		},
+		{
+			name: "ShortBatch with invalid original_url",
+			call: func() error {
+				_, err := client.ShortBatch(ctx, &pb.ShortBatchRequest{UserId: userID, Original: []*pb.CorrelatedOriginalURL{
+					{CorrelationId: "1", OriginalUrl: "practicum"},
+				}})
+				return err
+			},
+			expectedField: "original[0].original_url",
+		},
+		{
+			name: "StreamClicks with not UUID user_id",
+			call: func() error {
+				stream, err := client.StreamClicks(ctx, &pb.StreamClicksRequest{UserId: "1"})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			expectedField: "user_id",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, ok := status.FromError(tc.call())
+			require.True(t, ok)
+			assert.Equal(t, codes.InvalidArgument, e.Code(), "Expected error code get")
+
+			require.Len(t, e.Details(), 1, "Violations passed in status details")
+			badRequest, ok := e.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.NotEmpty(t, badRequest.FieldViolations)
+			assert.Equal(t, tc.expectedField, badRequest.FieldViolations[0].Field, "Violated field reported")
+		})
+	}
+}
+
+func Test_Authorization(t *testing.T) {
+	const userID = "48c01326-079e-4092-a903-b994a5b62b21"
+
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	client := newTestGRPCClient(t, rm)
+
+	rm.
+		EXPECT().
+		ListUserURLs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, options models.ListOptions) (models.URLsPage, error) {
+			assert.Equal(t, userID, ctx.Value(auth.ContextUserKey), "UserID taken from token")
+			return models.URLsPage{}, nil
+		}).
+		Times(1)
+	rm.
+		EXPECT().
+		Store(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(1)
+	rm.
+		EXPECT().
+		GetStats(gomock.Any()).
+		Return(models.Stats{URLs: 2, Users: 1, Active: 2}, nil).
+		Times(1)
+
+	JWTToken, _ := auth.BuildJWTString(userID)
+	authorized := metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationMetadataKey, "Bearer "+JWTToken)
+
+	_, err := client.GetUserBucket(context.Background(), &pb.GetUserBucketRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "Token required")
+
+	_, err = client.GetUserBucket(metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationMetadataKey, "broken"), &pb.GetUserBucketRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "Broken token rejected")
+
+	_, err = client.Delete(authorized, &pb.DeleteRequest{UserId: "8f4e8a7d-5d2a-4c47-a0e3-0c5c1d6bb0f1", Id: []string{"2187b119"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "Foreign user_id rejected")
+
+	_, err = client.GetUserBucket(authorized, &pb.GetUserBucketRequest{})
+	assert.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.Short(context.Background(), &pb.ShortRequest{Url: "https://practicum.yandex.ru/"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, auth.GetUserID(header.Get(auth.AuthorizationMetadataKey)[0]), "New user registered on public method")
+
+	_, err = client.Stats(authorized, &pb.StatsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "Stats not allowed from untrusted subnet")
+
+	_, err = client.Stats(metadata.AppendToOutgoingContext(context.Background(), auth.RealIPMetadataKey, "10.1.2.3"), &pb.StatsRequest{})
+	assert.NoError(t, err)
+}
+
+func Test_MigrationStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	client := newTestGRPCClient(t, rm)
+	trusted := metadata.AppendToOutgoingContext(context.Background(), auth.RealIPMetadataKey, "10.1.2.3")
+
+	gomock.InOrder(
+		rm.EXPECT().MigrationStatus(gomock.Any()).Return(models.MigrationStatus{Version: 8, Latest: 10, Pending: 2}, nil),
+		rm.EXPECT().MigrationStatus(gomock.Any()).Return(models.MigrationStatus{}, storage.ErrNoMigrations),
+		rm.EXPECT().MigrationStatus(gomock.Any()).Return(models.MigrationStatus{}, errors.New("connection refused")),
+	)
+
+	_, err := client.MigrationStatus(context.Background(), &pb.MigrationStatusRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "not allowed from untrusted subnet")
+
+	response, err := client.MigrationStatus(trusted, &pb.MigrationStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), response.Version)
+	assert.Equal(t, uint64(10), response.Latest)
+	assert.Equal(t, int64(2), response.Pending)
+	assert.False(t, response.Dirty)
+
+	_, err = client.MigrationStatus(trusted, &pb.MigrationStatusRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "storage without schema")
+
+	_, err = client.MigrationStatus(trusted, &pb.MigrationStatusRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
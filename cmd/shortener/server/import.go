@@ -0,0 +1,210 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// importChunkSize - amount of imported URLs stored by one StoreBatch call
+const importChunkSize = storage.MaxBatchInsertRows
+
+// Content types accepted by bulk import
+const (
+	// ContentTypeNDJSON - JSON object of dto.BatchShortenRequest per line
+	ContentTypeNDJSON = "application/x-ndjson"
+	// ContentTypeCSV - header with column names and URL per row
+	ContentTypeCSV = "text/csv"
+)
+
+// Errors of bulk import body
+var (
+	// ErrUnsupportedImportType - error when body of import has unsupported content type
+	ErrUnsupportedImportType = errors.New("import accepts only " + ContentTypeNDJSON + " or " + ContentTypeCSV)
+	// ErrMissingURLColumn - error when CSV header has no original_url column
+	ErrMissingURLColumn = errors.New("CSV header must have original_url column")
+	// ErrMissingURL - error when imported line has no original URL
+	ErrMissingURL = errors.New("original_url is required")
+)
+
+// importLine - URL of import body, err is set when line is malformed
+type importLine struct {
+	line    int
+	request dto.BatchShortenRequest
+	err     error
+}
+
+// importReader - reads URLs of import body one by one
+type importReader interface {
+	// next - returns next line of body, err is io.EOF at the end of body or error of body reading
+	next() (line importLine, err error)
+}
+
+// newImportReader - create importReader of body by its content type
+func newImportReader(contentType string, body io.Reader) (importReader, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case ContentTypeNDJSON:
+		return &ndjsonImportReader{reader: bufio.NewReader(body)}, nil
+	case ContentTypeCSV:
+		return newCSVImportReader(body)
+	default:
+		return nil, ErrUnsupportedImportType
+	}
+}
+
+// ndjsonImportReader - importReader of newline delimited JSON objects, blank lines are skipped
+type ndjsonImportReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (r *ndjsonImportReader) next() (line importLine, err error) {
+	for {
+		data, err := r.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return line, err
+		}
+		if len(data) == 0 {
+			return line, io.EOF
+		}
+		r.line++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		line.line = r.line
+		if line.err = json.Unmarshal(data, &line.request); line.err != nil {
+			line.err = fmt.Errorf("line can not be parsed: %w", line.err)
+			return line, nil
+		}
+		line.err = validateImportRequest(line.request)
+		return line, nil
+	}
+}
+
+// csvImportReader - importReader of CSV rows, columns are named by header: original_url, correlation_id, alias, expires_at and max_clicks
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(body io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV header can not be read: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, found := columns["original_url"]; !found {
+		return nil, ErrMissingURLColumn
+	}
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) next() (line importLine, err error) {
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		line.line, line.err = parseErr.StartLine, fmt.Errorf("line can not be parsed: %w", err)
+		return line, nil
+	}
+	if err != nil {
+		return line, err
+	}
+	line.line, _ = r.reader.FieldPos(0)
+	line.err = r.parse(record, &line.request)
+	return line, nil
+}
+
+// parse - fill request from CSV record by header columns
+func (r *csvImportReader) parse(record []string, request *dto.BatchShortenRequest) (err error) {
+	column := func(name string) string {
+		if i, found := r.columns[name]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	request.URL = column("original_url")
+	request.CorrelationID = column("correlation_id")
+	request.Alias = column("alias")
+	if expiresAt := column("expires_at"); expiresAt != "" {
+		if request.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+			return fmt.Errorf("expires_at must be RFC 3339 date: %w", err)
+		}
+	}
+	if maxClicks := column("max_clicks"); maxClicks != "" {
+		if request.MaxClicks, err = strconv.Atoi(maxClicks); err != nil {
+			return fmt.Errorf("max_clicks must be a number: %w", err)
+		}
+	}
+	return validateImportRequest(*request)
+}
+
+// validateImportRequest - check that imported line has URL to shorten
+func validateImportRequest(request dto.BatchShortenRequest) error {
+	if request.URL == "" {
+		return ErrMissingURL
+	}
+	return nil
+}
+
+// importedItem - URL of import with its line number
+type importedItem struct {
+	line int
+	batchItem
+}
+
+// readImportChunk - read up to importChunkSize URLs of user from reader, lines without correlation ID are correlated by line number.
+// io.EOF is returned with the last chunk
+func readImportChunk(reader importReader, userID string) (chunk []importedItem, err error) {
+	chunk = make([]importedItem, 0, importChunkSize)
+	for len(chunk) < importChunkSize {
+		var line importLine
+		if line, err = reader.next(); err != nil {
+			return
+		}
+		request := line.request
+		if request.CorrelationID == "" {
+			request.CorrelationID = strconv.Itoa(line.line)
+		}
+		chunk = append(chunk, importedItem{line: line.line, batchItem: batchItem{
+			correlationID: request.CorrelationID,
+			params: shortenParams{
+				originalURL: request.URL,
+				userID:      userID,
+				alias:       request.Alias,
+				expiresAt:   request.ExpiresAt,
+				maxClicks:   request.MaxClicks,
+			},
+			err: line.err,
+		}})
+	}
+	return chunk, nil
+}
+
+// batchItems - returns batch items of import chunk
+func batchItems(chunk []importedItem) []batchItem {
+	items := make([]batchItem, 0, len(chunk))
+	for _, item := range chunk {
+		items = append(items, item.batchItem)
+	}
+	return items
+}
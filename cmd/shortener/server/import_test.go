@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readImportLines(t *testing.T, reader importReader) (lines []importLine) {
+	for {
+		line, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+}
+
+func Test_ndjsonImportReader(t *testing.T) {
+	reader, err := newImportReader("application/x-ndjson; charset=utf-8", strings.NewReader(
+		`{"correlation_id": "first", "original_url": "https://practicum.yandex.ru/"}`+"\n"+
+			"\n"+
+			`{"original_url": "https://ya.ru/", "alias": "sale", "max_clicks": 10}`+"\n"+
+			`{"original_url": `+"\n"+
+			`{"correlation_id": "empty"}`))
+	require.NoError(t, err)
+
+	lines := readImportLines(t, reader)
+	require.Len(t, lines, 4, "blank line skipped")
+	assert.Equal(t, 1, lines[0].line)
+	assert.Equal(t, "first", lines[0].request.CorrelationID)
+	assert.NoError(t, lines[0].err)
+	assert.Equal(t, 3, lines[1].line, "blank line counted")
+	assert.Equal(t, "sale", lines[1].request.Alias)
+	assert.Equal(t, 10, lines[1].request.MaxClicks)
+	assert.Error(t, lines[2].err, "malformed line reported")
+	assert.Equal(t, 4, lines[2].line)
+	assert.ErrorIs(t, lines[3].err, ErrMissingURL)
+}
+
+func Test_csvImportReader(t *testing.T) {
+	reader, err := newImportReader("text/csv", strings.NewReader(
+		"Original_URL,correlation_id,expires_at,max_clicks\n"+
+			"https://practicum.yandex.ru/,first,2030-01-02T03:04:05Z,5\n"+
+			"https://ya.ru/\n"+
+			"https://ya.ru/sale,,tomorrow,\n"+
+			"https://ya.ru/\"sale\n"))
+	require.NoError(t, err)
+
+	lines := readImportLines(t, reader)
+	require.Len(t, lines, 4)
+	assert.NoError(t, lines[0].err)
+	assert.Equal(t, 2, lines[0].line)
+	assert.Equal(t, "first", lines[0].request.CorrelationID)
+	assert.Equal(t, 2030, lines[0].request.ExpiresAt.Year())
+	assert.Equal(t, 5, lines[0].request.MaxClicks)
+	assert.NoError(t, lines[1].err, "short row keeps optional columns empty")
+	assert.Equal(t, "https://ya.ru/", lines[1].request.URL)
+	assert.Error(t, lines[2].err, "invalid expires_at reported")
+	assert.Equal(t, 4, lines[2].line)
+	assert.Error(t, lines[3].err, "malformed row reported")
+	assert.Equal(t, 5, lines[3].line)
+}
+
+func Test_newImportReader(t *testing.T) {
+	_, err := newImportReader("application/json", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnsupportedImportType)
+	_, err = newImportReader("text/csv", strings.NewReader("url,alias\n"))
+	assert.ErrorIs(t, err, ErrMissingURLColumn)
+	_, err = newImportReader("text/csv", strings.NewReader(""))
+	assert.Error(t, err, "header is required")
+}
+
+func Test_readImportChunk(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < importChunkSize+1; i++ {
+		body.WriteString(`{"original_url": "https://practicum.yandex.ru/"}` + "\n")
+	}
+	reader, err := newImportReader(ContentTypeNDJSON, strings.NewReader(body.String()))
+	require.NoError(t, err)
+
+	chunk, err := readImportChunk(reader, "1")
+	require.NoError(t, err)
+	require.Len(t, chunk, importChunkSize)
+	assert.Equal(t, "1", chunk[0].correlationID, "line number is default correlation ID")
+	assert.Equal(t, "1", chunk[0].params.userID)
+
+	chunk, err = readImportChunk(reader, "1")
+	assert.ErrorIs(t, err, io.EOF)
+	require.Len(t, chunk, 1, "last chunk returned with io.EOF")
+	assert.Equal(t, importChunkSize+1, chunk[0].line)
+}
@@ -7,13 +7,16 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/PaBah/url-shortener.git/internal/async"
 	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/config"
 	"github.com/PaBah/url-shortener.git/internal/dto"
 	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/metrics"
 	"github.com/PaBah/url-shortener.git/internal/middlewares"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
@@ -24,22 +27,51 @@ import (
 
 // Server - entity which presents application server
 type Server struct {
-	options *config.Options
-	storage storage.Repository
+	options       *config.Options
+	storage       storage.Repository
+	clickRecorder *async.ClickRecorder
+	clickHub      *async.ClickHub
 }
 
 // GetShortURLHandle - handler for list user's shortened URLs
 func (s Server) GetShortURLHandle(res http.ResponseWriter, req *http.Request) {
 	shortID := chi.URLParam(req, "id")
 
-	shortenURL, _ := s.storage.FindByID(req.Context(), shortID)
-	if shortenURL.DeletedFlag {
+	shortenURL, findErr := s.storage.FindByID(req.Context(), shortID)
+	if shortenURL.DeletedFlag || shortenURL.IsExpired(time.Now()) {
 		res.WriteHeader(http.StatusGone)
 		return
 	}
+	if shortenURL.MaxClicks > 0 {
+		if err := s.storage.RegisterClick(req.Context(), shortID); errors.Is(err, storage.ErrLinkExpired) {
+			res.WriteHeader(http.StatusGone)
+			return
+		}
+	}
+	if findErr == nil {
+		click := models.NewClick(shortID, req.Referer(), req.UserAgent(), clientIP(req))
+		if s.clickRecorder != nil {
+			s.clickRecorder.Record(click)
+		}
+		if s.clickHub != nil {
+			s.clickHub.Publish(shortenURL.UserID, click)
+		}
+	}
 	http.Redirect(res, req, shortenURL.OriginalURL, http.StatusTemporaryRedirect)
 }
 
+// clientIP - returns IP of client from X-Real-IP header or from connection address
+func clientIP(req *http.Request) string {
+	if ip := req.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
 // PostURLHandle - handler for shortening URL
 func (s Server) PostURLHandle(res http.ResponseWriter, req *http.Request) {
 	body, err := io.ReadAll(req.Body)
@@ -47,8 +79,16 @@ func (s Server) PostURLHandle(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	shortURL := models.NewShortURL(string(body), req.Context().Value(auth.ContextUserKey).(string))
-	err = s.storage.Store(req.Context(), shortURL)
+	shortURL, err := models.NewShortURL(string(body), req.Context().Value(auth.ContextUserKey).(string))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = s.storage.Store(req.Context(), &shortURL)
+	if err != nil && !errors.Is(err, storage.ErrConflict) {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	shortenedURL := fmt.Sprintf("%s/%s", s.options.BaseURL, shortURL.UUID)
 	res.Header().Set("Content-Type", "")
@@ -83,8 +123,31 @@ func (s Server) APIShortenHandle(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	shortURL := models.NewShortURL(requestData.URL, req.Context().Value(auth.ContextUserKey).(string))
-	err = s.storage.Store(req.Context(), shortURL)
+	shortURL, err := newShortURL(shortenParams{
+		originalURL: requestData.URL,
+		userID:      req.Context().Value(auth.ContextUserKey).(string),
+		alias:       requestData.Alias,
+		expiresAt:   requestData.ExpiresAt,
+		maxClicks:   requestData.MaxClicks,
+	})
+	if errors.Is(err, models.ErrIDGeneration) {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.storage.Store(req.Context(), &shortURL)
+	if errors.Is(err, storage.ErrAliasTaken) {
+		http.Error(res, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, storage.ErrConflict) {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	res.Header().Set("Content-Type", "application/json")
 	if errors.Is(err, storage.ErrConflict) {
@@ -114,13 +177,7 @@ func (s Server) APIShortenHandle(res http.ResponseWriter, req *http.Request) {
 
 // PingHandle - handler for checking if DB is working
 func (s Server) PingHandle(res http.ResponseWriter, req *http.Request) {
-	dbStorage, ok := s.storage.(*storage.DBStorage)
-	if !ok {
-		http.Error(res, "Service working not on top DB storage", http.StatusInternalServerError)
-		return
-	}
-
-	if err := dbStorage.Ping(req.Context()); err != nil {
+	if err := storage.Ping(req.Context(), s.storage); err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -141,24 +198,31 @@ func (s Server) APIShortenBatchHandle(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	shortURLsMap := make(map[string]models.ShortenURL, len(requestData))
+	userID := req.Context().Value(auth.ContextUserKey).(string)
+	items := make([]batchItem, 0, len(requestData))
 	for _, batchRequest := range requestData {
-		shortURL := models.NewShortURL(batchRequest.URL, req.Context().Value(auth.ContextUserKey).(string))
-		shortURLsMap[batchRequest.CorrelationID] = shortURL
+		items = append(items, batchItem{correlationID: batchRequest.CorrelationID, params: shortenParams{
+			originalURL: batchRequest.URL,
+			userID:      userID,
+			alias:       batchRequest.Alias,
+			expiresAt:   batchRequest.ExpiresAt,
+			maxClicks:   batchRequest.MaxClicks,
+		}})
 	}
 
-	err = s.storage.StoreBatch(req.Context(), shortURLsMap)
+	results, err := shortenBatch(req.Context(), s.storage, s.options.BaseURL, items)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var responseData []dto.BatchShortenResponse
-	for correlationID, shortenedURL := range shortURLsMap {
-		responseData = append(responseData, dto.BatchShortenResponse{
-			CorrelationID: correlationID,
-			ShortURL:      fmt.Sprintf("%s/%s", s.options.BaseURL, shortenedURL.UUID),
-		})
+	responseData := make([]dto.BatchShortenResponse, 0, len(results))
+	for _, result := range results {
+		batchResponse := dto.BatchShortenResponse{CorrelationID: result.correlationID, ShortURL: result.shortURL, Status: result.status}
+		if result.err != nil {
+			batchResponse.Error = result.err.Error()
+		}
+		responseData = append(responseData, batchResponse)
 	}
 
 	res.Header().Set("Content-Type", "application/json")
@@ -169,25 +233,120 @@ func (s Server) APIShortenBatchHandle(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	res.WriteHeader(http.StatusCreated)
+	if isPartialBatch(results) {
+		res.WriteHeader(http.StatusMultiStatus)
+	} else {
+		res.WriteHeader(http.StatusCreated)
+	}
 	_, err = res.Write(response)
 	if err != nil {
-		logger.Log().Error("Can not send response from APIShortenHandle:", zap.Error(err))
+		logger.Log().Error("Can not send response from APIShortenBatchHandle:", zap.Error(err))
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-// UserUrlsHandle - handler for list of short URLs of authorized user
+// APIShortenImportHandle - handler for bulk import of URLs from NDJSON or CSV body, body is read and stored by chunks
+// and result of every line is streamed back as NDJSON
+func (s Server) APIShortenImportHandle(res http.ResponseWriter, req *http.Request) {
+	reader, err := newImportReader(req.Header.Get("Content-Type"), req.Body)
+	if errors.Is(err, ErrUnsupportedImportType) {
+		http.Error(res, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := req.Context().Value(auth.ContextUserKey).(string)
+	controller := http.NewResponseController(res)
+	// results of read lines are sent while rest of body is still read
+	_ = controller.EnableFullDuplex()
+	res.Header().Set("Content-Type", ContentTypeNDJSON)
+	res.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(res)
+	for {
+		chunk, readErr := readImportChunk(reader, userID)
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			logger.Log().Error("Import body can not be read", zap.Error(readErr))
+		}
+		if len(chunk) == 0 {
+			return
+		}
+
+		results, err := shortenBatch(req.Context(), s.storage, s.options.BaseURL, batchItems(chunk))
+		for i, item := range chunk {
+			response := dto.ImportURLResponse{Line: item.line, CorrelationID: item.correlationID, Status: dto.BatchStatusInvalid}
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.ShortURL, response.Status = results[i].shortURL, results[i].status
+				if results[i].err != nil {
+					response.Error = results[i].err.Error()
+				}
+			}
+			if encodeErr := encoder.Encode(response); encodeErr != nil {
+				logger.Log().Error("Can not send response from APIShortenImportHandle:", zap.Error(encodeErr))
+				return
+			}
+		}
+		_ = controller.Flush()
+		if err != nil || readErr != nil {
+			return
+		}
+	}
+}
+
+// listOptionsFromQuery - parse pagination, sorting and filtering of user's short URLs list from query
+func listOptionsFromQuery(query url.Values) (options models.ListOptions, err error) {
+	if limit := query.Get("limit"); limit != "" {
+		options.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return options, fmt.Errorf("%w: limit must be a number", models.ErrInvalidListOptions)
+		}
+	}
+	options.Cursor = query.Get("cursor")
+	options.SortBy = query.Get("sort")
+	options.Status = query.Get("status")
+	options.Search = query.Get("q")
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		options.Desc = true
+	default:
+		return options, fmt.Errorf("%w: order must be asc or desc", models.ErrInvalidListOptions)
+	}
+	return options.Normalize()
+}
+
+// UserUrlsHandle - handler for page of short URLs of authorized user, cursor of next page is sent in X-Next-Cursor header
 func (s Server) UserUrlsHandle(res http.ResponseWriter, req *http.Request) {
-	shortURLs, err := s.storage.GetAllUsers(req.Context())
+	options, err := listOptionsFromQuery(req.URL.Query())
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.storage.ListUserURLs(req.Context(), options)
+	if errors.Is(err, models.ErrInvalidListOptions) {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(page.URLs) == 0 {
 		res.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	if page.NextCursor != "" {
+		res.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
 	var responseData []dto.UsersURLsResponse
-	for _, shortURL := range shortURLs {
+	for _, shortURL := range page.URLs {
 		responseData = append(responseData, dto.UsersURLsResponse{
 			OriginalURL: shortURL.OriginalURL,
 			ShortURL:    fmt.Sprintf("%s/%s", s.options.BaseURL, shortURL.UUID),
@@ -211,6 +370,205 @@ func (s Server) UserUrlsHandle(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// UserURLsExportHandle - handler for export of all short URLs of authorized user in JSON, NDJSON or CSV format,
+// short URLs are streamed page by page and deleted ones are exported only when include_deleted is requested
+func (s Server) UserURLsExportHandle(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = ExportFormatJSON
+	}
+	includeDeleted := false
+	if value := query.Get("include_deleted"); value != "" {
+		var err error
+		if includeDeleted, err = strconv.ParseBool(value); err != nil {
+			http.Error(res, "include_deleted must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+	writer, contentType, err := newExportWriter(format, res)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	controller := http.NewResponseController(res)
+	started := false
+	err = exportUserURLs(req.Context(), s.storage, includeDeleted, func(shortURLs []models.ShortenURL) error {
+		if !started {
+			started = true
+			res.Header().Set("Content-Type", contentType)
+			res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+			res.WriteHeader(http.StatusOK)
+		}
+		for _, shortURL := range shortURLs {
+			if err := writer.write(exportedURL(s.options.BaseURL, shortURL)); err != nil {
+				return err
+			}
+		}
+		_ = controller.Flush()
+		return nil
+	})
+	if err != nil && !started {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		// status is already sent, so client sees broken export by unfinished body
+		logger.Log().Error("Export of user's short URLs is broken", zap.Error(err))
+		return
+	}
+	if err = writer.close(); err != nil {
+		logger.Log().Error("Can not send response from UserURLsExportHandle:", zap.Error(err))
+	}
+}
+
+// UserURLStatsHandle - handler for per day clicks stats of authorized user's short URL
+func (s Server) UserURLStatsHandle(res http.ResponseWriter, req *http.Request) {
+	shortID := chi.URLParam(req, "id")
+	shortenURL, err := s.storage.FindByID(req.Context(), shortID)
+	if err != nil || shortenURL.UserID != req.Context().Value(auth.ContextUserKey).(string) {
+		http.Error(res, "shortened URL not found", http.StatusNotFound)
+		return
+	}
+
+	stats, err := s.storage.GetClickStats(req.Context(), shortID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responseData := make([]dto.ClickStatsResponse, 0, len(stats))
+	for _, dayStats := range stats {
+		responseData = append(responseData, dto.ClickStatsResponse{
+			Date:     dayStats.Day.Format(time.DateOnly),
+			Clicks:   dayStats.Clicks,
+			Visitors: dayStats.Visitors,
+		})
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	response, err := json.Marshal(responseData)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	_, err = res.Write(response)
+	if err != nil {
+		logger.Log().Error("Can not send response from UserURLStatsHandle:", zap.Error(err))
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserURLUpdateHandle - handler for change of authorized user's short URL destination
+func (s Server) UserURLUpdateHandle(res http.ResponseWriter, req *http.Request) {
+	shortID := chi.URLParam(req, "id")
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	requestData := &dto.UpdateURLRequest{}
+	if err = json.Unmarshal(body, requestData); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validateOriginalURL(requestData.URL); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.storage.UpdateURL(req.Context(), shortID, requestData.URL)
+	s.writeUpdateURLResponse(res, shortID, err)
+}
+
+// UserURLRestoreVersionHandle - handler for restoring destination of authorized user's short URL from its history
+func (s Server) UserURLRestoreVersionHandle(res http.ResponseWriter, req *http.Request) {
+	shortID := chi.URLParam(req, "id")
+	version, err := strconv.Atoi(chi.URLParam(req, "version"))
+	if err != nil || version <= 0 {
+		http.Error(res, "version must be positive integer", http.StatusBadRequest)
+		return
+	}
+
+	err = restoreURLVersion(req.Context(), s.storage, shortID, version)
+	s.writeUpdateURLResponse(res, shortID, err)
+}
+
+// writeUpdateURLResponse - write result of short URL destination change
+func (s Server) writeUpdateURLResponse(res http.ResponseWriter, shortID string, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound) || errors.Is(err, ErrUnknownVersion):
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(res, "user already has short URL with such destination", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	response, err := json.Marshal(dto.ShortenResponse{Result: fmt.Sprintf("%s/%s", s.options.BaseURL, shortID)})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	_, err = res.Write(response)
+	if err != nil {
+		logger.Log().Error("Can not send response from writeUpdateURLResponse:", zap.Error(err))
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserURLHistoryHandle - handler for previous destinations of authorized user's short URL
+func (s Server) UserURLHistoryHandle(res http.ResponseWriter, req *http.Request) {
+	shortID := chi.URLParam(req, "id")
+	shortenURL, err := s.storage.FindByID(req.Context(), shortID)
+	if err != nil || shortenURL.UserID != req.Context().Value(auth.ContextUserKey).(string) {
+		http.Error(res, "shortened URL not found", http.StatusNotFound)
+		return
+	}
+
+	versions, err := s.storage.GetURLHistory(req.Context(), shortID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responseData := make([]dto.URLVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		responseData = append(responseData, dto.URLVersionResponse{
+			Version:     version.Version,
+			OriginalURL: version.OriginalURL,
+			ReplacedAt:  version.ReplacedAt,
+		})
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	response, err := json.Marshal(responseData)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	_, err = res.Write(response)
+	if err != nil {
+		logger.Log().Error("Can not send response from UserURLHistoryHandle:", zap.Error(err))
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // APIDeleteUsersUrlsHandle - handler for delete short URLs
 func (s Server) APIDeleteUsersUrlsHandle(res http.ResponseWriter, req *http.Request) {
 	body, err := io.ReadAll(req.Body)
@@ -232,20 +590,65 @@ func (s Server) APIDeleteUsersUrlsHandle(res http.ResponseWriter, req *http.Requ
 
 	channels := async.DeletionFanOut(userID, s.storage, inputCh)
 	addResultCh := async.DeletionFanIn(channels...)
-	async.Delete(s.storage, addResultCh)
+	if err = async.Delete(s.storage, addResultCh); err != nil {
+		http.Error(res, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 
 	res.WriteHeader(http.StatusAccepted)
 }
 
+// APIRestoreUsersUrlsHandle - handler for undo of short URLs deletion
+func (s Server) APIRestoreUsersUrlsHandle(res http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	requestData := dto.RestoreURLsRequest{}
+	if err = json.Unmarshal(body, &requestData); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	restored, err := s.storage.RestoreShortURLs(req.Context(), requestData)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	response, err := json.Marshal(dto.RestoreURLsResponse(restored))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	_, err = res.Write(response)
+	if err != nil {
+		logger.Log().Error("Can not send response from APIRestoreUsersUrlsHandle:", zap.Error(err))
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // APIInternalStatsHandle - handler to check internal service stats
 func (s Server) APIInternalStatsHandle(res http.ResponseWriter, req *http.Request) {
-	urls, users, err := s.storage.GetStats(req.Context())
+	stats, err := s.storage.GetStats(req.Context())
 	if err != nil {
 		res.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	responseData := dto.StatsResponse{Users: users, Urls: urls}
+	responseData := dto.StatsResponse{
+		Users:   stats.Users,
+		Urls:    stats.URLs,
+		Active:  stats.Active,
+		Deleted: stats.Deleted,
+		Purged:  stats.Purged,
+	}
 
 	res.Header().Set("Content-Type", "application/json")
 	response, err := json.Marshal(responseData)
@@ -265,13 +668,16 @@ func (s Server) APIInternalStatsHandle(res http.ResponseWriter, req *http.Reques
 }
 
 // NewRouter - creates instance of Server
-func NewRouter(options *config.Options, storage *storage.Repository) *chi.Mux {
+func NewRouter(options *config.Options, storage *storage.Repository, clickRecorder *async.ClickRecorder, clickHub *async.ClickHub) *chi.Mux {
 	r := chi.NewRouter()
 
 	s := Server{
-		options: options,
-		storage: *storage,
+		options:       options,
+		storage:       *storage,
+		clickRecorder: clickRecorder,
+		clickHub:      clickHub,
 	}
+	r.Use(metrics.HTTPMiddleware)
 	r.Use(middlewares.GzipMiddleware)
 	r.Use(logger.LoggerMiddleware)
 
@@ -282,6 +688,7 @@ func NewRouter(options *config.Options, storage *storage.Repository) *chi.Mux {
 		r.Get("/ping", s.PingHandle)
 		r.Post("/api/shorten", s.APIShortenHandle)
 		r.Post("/api/shorten/batch", s.APIShortenBatchHandle)
+		r.Post("/api/shorten/import", s.APIShortenImportHandle)
 		r.MethodNotAllowed(
 			func(writer http.ResponseWriter, request *http.Request) {
 				writer.WriteHeader(http.StatusBadRequest)
@@ -291,7 +698,13 @@ func NewRouter(options *config.Options, storage *storage.Repository) *chi.Mux {
 	r.Group(func(r chi.Router) {
 		r.Use(auth.AuthorizedMiddleware)
 		r.Get("/api/user/urls", s.UserUrlsHandle)
+		r.Get("/api/user/urls/export", s.UserURLsExportHandle)
+		r.Get("/api/user/urls/{id}/stats", s.UserURLStatsHandle)
+		r.Patch("/api/user/urls/{id}", s.UserURLUpdateHandle)
+		r.Get("/api/user/urls/{id}/history", s.UserURLHistoryHandle)
+		r.Post("/api/user/urls/{id}/history/{version}/restore", s.UserURLRestoreVersionHandle)
 		r.Delete("/api/user/urls", s.APIDeleteUsersUrlsHandle)
+		r.Post("/api/user/urls/restore", s.APIRestoreUsersUrlsHandle)
 	})
 	if options.TrustedSubnet != "" {
 		_, trustedNet, err := net.ParseCIDR(options.TrustedSubnet)
@@ -302,6 +715,7 @@ func NewRouter(options *config.Options, storage *storage.Repository) *chi.Mux {
 		r.Group(func(r chi.Router) {
 			r.Use(middlewares.IPWhiteListMiddleware(trustedNet))
 			r.Get("/api/internal/stats", s.APIInternalStatsHandle)
+			r.Handle("/metrics", metrics.Handler())
 		})
 	}
 	return r
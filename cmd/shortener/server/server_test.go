@@ -1,18 +1,28 @@
 package server
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/PaBah/url-shortener.git/internal/async"
 	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/config"
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/metrics"
 	"github.com/PaBah/url-shortener.git/internal/mock"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -30,10 +40,19 @@ func TestServer(t *testing.T) {
 		{method: http.MethodPost, path: "/", requestBody: "http://prjdzevto8.yandex", expectedCode: http.StatusConflict, expectedBody: "http://localhost:8080/a033a480"},
 		{method: http.MethodGet, path: "/2187b119", requestBody: "", expectedCode: http.StatusTemporaryRedirect, expectedBody: ""},
 		{method: http.MethodGet, path: "/2a49568d", requestBody: "", expectedCode: http.StatusTemporaryRedirect, expectedBody: ""},
+		{method: http.MethodGet, path: "/expired1", requestBody: "", expectedCode: http.StatusGone, expectedBody: ""},
+		{method: http.MethodGet, path: "/limited1", requestBody: "", expectedCode: http.StatusGone, expectedBody: ""},
 		{method: http.MethodPut, path: "/2187b119", requestBody: "https://practicum.yandex.ru/", expectedCode: http.StatusBadRequest, expectedBody: ""},
 		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.kz/"}`, expectedCode: http.StatusCreated, expectedBody: `{"result":"http://localhost:8080/2a49568d"}`},
 		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yande`, expectedCode: http.StatusInternalServerError, expectedBody: ""},
 		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "http://prjdzevto8.yandex"}`, expectedCode: http.StatusConflict, expectedBody: `{"result":"http://localhost:8080/a033a480"}`},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "alias": "spring-sale"}`, expectedCode: http.StatusCreated, expectedBody: `{"result":"http://localhost:8080/spring-sale"}`},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "alias": "ping"}`, expectedCode: http.StatusBadRequest, expectedBody: ""},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "alias": "metrics"}`, expectedCode: http.StatusBadRequest, expectedBody: ""},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "alias": "sale!"}`, expectedCode: http.StatusBadRequest, expectedBody: ""},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "alias": "taken-alias"}`, expectedCode: http.StatusConflict, expectedBody: ""},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "expires_at": "2020-01-01T00:00:00Z"}`, expectedCode: http.StatusBadRequest, expectedBody: ""},
+		{method: http.MethodPost, path: "/api/shorten", requestBody: `{"url": "https://practicum.yandex.ru/sale", "max_clicks": -1}`, expectedCode: http.StatusBadRequest, expectedBody: ""},
 		{method: http.MethodGet, path: "/ping", requestBody: "", expectedCode: http.StatusInternalServerError, expectedBody: ""},
 		{method: http.MethodPost, path: "/api/shorten/batch", requestBody: `[x.kz/"}]`, expectedCode: http.StatusInternalServerError, expectedBody: ""},
 		{
@@ -41,7 +60,7 @@ func TestServer(t *testing.T) {
 			path:         "/api/shorten/batch",
 			requestBody:  `[{"correlation_id": "1","original_url": "https://practicum.yandex.kz/"}]`,
 			expectedCode: http.StatusCreated,
-			expectedBody: `[{"correlation_id":"1","short_url":"http://localhost:8080/2a49568d"}]`,
+			expectedBody: `[{"correlation_id":"1","short_url":"http://localhost:8080/2a49568d","status":"created"}]`,
 		},
 		{
 			method:       http.MethodPost,
@@ -64,6 +83,16 @@ func TestServer(t *testing.T) {
 			expectedCode: http.StatusNoContent,
 			expectedBody: "",
 		},
+		{
+			method:       http.MethodGet,
+			path:         "/api/user/urls?limit=many",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			method:       http.MethodGet,
+			path:         "/api/user/urls?order=sideways",
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			method:       http.MethodDelete,
 			path:         "/api/user/urls",
@@ -82,7 +111,13 @@ func TestServer(t *testing.T) {
 			method:       http.MethodGet,
 			path:         "/api/internal/stats",
 			expectedCode: http.StatusOK,
-			expectedBody: `{"users":1,"urls":2}`,
+			expectedBody: `{"users":1,"urls":2,"active":2,"deleted":0,"purged":0}`,
+		},
+		{
+			method:       http.MethodGet,
+			path:         "/metrics",
+			expectedCode: http.StatusOK,
+			expectedBody: "",
 		},
 	}
 
@@ -100,49 +135,74 @@ func TestServer(t *testing.T) {
 
 	rm.
 		EXPECT().
-		Store(gomock.Any(), gomock.Eq(models.NewShortURL("https://practicum.yandex.ru/", "1"))).
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(newTestShortURL(t, "https://practicum.yandex.ru/", "1")))).
 		Return(nil).
 		AnyTimes()
 	rm.
 		EXPECT().
-		Store(gomock.Any(), gomock.Eq(models.NewShortURL("http://prjdzevto8.yandex", "1"))).
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(newTestShortURL(t, "http://prjdzevto8.yandex", "1")))).
 		Return(storage.ErrConflict).
 		AnyTimes()
+	rm.
+		EXPECT().
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(models.NewAliasShortURL("spring-sale", "https://practicum.yandex.ru/sale", "1")))).
+		Return(nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(models.NewAliasShortURL("taken-alias", "https://practicum.yandex.ru/sale", "1")))).
+		Return(storage.ErrAliasTaken).
+		AnyTimes()
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "expired1").
+		Return(models.ShortenURL{UUID: "expired1", OriginalURL: "https://practicum.yandex.ru/", ExpiresAt: time.Now().Add(-time.Hour)}, nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "limited1").
+		Return(models.ShortenURL{UUID: "limited1", OriginalURL: "https://practicum.yandex.ru/", MaxClicks: 1}, nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		RegisterClick(gomock.Any(), "limited1").
+		Return(storage.ErrLinkExpired).
+		AnyTimes()
 	rm.
 		EXPECT().
 		FindByID(gomock.Any(), "2187b119").
-		Return(models.NewShortURL("https://practicum.yandex.ru/", "1"), nil).
+		Return(newTestShortURL(t, "https://practicum.yandex.ru/", "1"), nil).
 		AnyTimes()
 	rm.
 		EXPECT().
-		Store(gomock.Any(), gomock.Eq(models.NewShortURL("https://practicum.yandex.kz/", "1"))).
+		Store(gomock.Any(), gomock.Eq(shortURLPtr(newTestShortURL(t, "https://practicum.yandex.kz/", "1")))).
 		Return(nil).
 		AnyTimes()
 	rm.
 		EXPECT().
 		FindByID(gomock.Any(), "2a49568d").
-		Return(models.NewShortURL("https://practicum.yandex.kz/", "1"), nil).
+		Return(newTestShortURL(t, "https://practicum.yandex.kz/", "1"), nil).
 		AnyTimes()
 	rm.
 		EXPECT().
-		StoreBatch(gomock.Any(), gomock.Eq(map[string]models.ShortenURL{"1": models.NewShortURL("https://practicum.yandex.kz/", "1")})).
-		Return(nil).
+		StoreBatch(gomock.Any(), gomock.Eq([]models.ShortenURL{newTestShortURL(t, "https://practicum.yandex.kz/", "1")})).
+		Return([]models.BatchResult{{Status: models.BatchCreated, ShortURL: newTestShortURL(t, "https://practicum.yandex.kz/", "1")}}, nil).
 		AnyTimes()
 	err := errors.New("Error")
 	rm.
 		EXPECT().
-		StoreBatch(gomock.Any(), gomock.Eq(map[string]models.ShortenURL{"1": models.NewShortURL("https://practicum.kz/", "1")})).
-		Return(err).
+		StoreBatch(gomock.Any(), gomock.Eq([]models.ShortenURL{newTestShortURL(t, "https://practicum.kz/", "1")})).
+		Return(nil, err).
 		AnyTimes()
 	rm.
 		EXPECT().
-		GetAllUsers(gomock.Any()).
-		Return([]models.ShortenURL{models.NewShortURL("https://practicum.kz/", "1")}, nil).
+		ListUserURLs(gomock.Any(), gomock.Any()).
+		Return(models.URLsPage{URLs: []models.ShortenURL{newTestShortURL(t, "https://practicum.kz/", "1")}}, nil).
 		Times(1)
 	rm.
 		EXPECT().
-		GetAllUsers(gomock.Any()).
-		Return([]models.ShortenURL{}, err).
+		ListUserURLs(gomock.Any(), gomock.Any()).
+		Return(models.URLsPage{URLs: []models.ShortenURL{}}, nil).
 		Times(1)
 	rm.
 		EXPECT().
@@ -151,10 +211,10 @@ func TestServer(t *testing.T) {
 	rm.
 		EXPECT().
 		GetStats(gomock.Any()).
-		Return(2, 1, nil).
+		Return(models.Stats{URLs: 2, Users: 1, Active: 2}, nil).
 		Times(1)
 
-	sh := NewRouter(options, &store)
+	sh := NewRouter(options, &store, nil, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.method, func(t *testing.T) {
@@ -176,3 +236,499 @@ func TestServer(t *testing.T) {
 		})
 	}
 }
+
+func shortURLPtr(shortURL models.ShortenURL) *models.ShortenURL {
+	return &shortURL
+}
+
+// newTestShortURL - create ShortenURL by configured generator which is not expected to fail
+func newTestShortURL(t testing.TB, originalURL string, userID string) models.ShortenURL {
+	t.Helper()
+	shortURL, err := models.NewShortURL(originalURL, userID)
+	assert.NoError(t, err)
+	return shortURL
+}
+
+func TestServer_clicks(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "2187b119").
+		Return(newTestShortURL(t, "https://practicum.yandex.ru/", "1"), nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "other123").
+		Return(newTestShortURL(t, "https://practicum.yandex.ru/", "2"), nil).
+		AnyTimes()
+	rm.
+		EXPECT().
+		GetClickStats(gomock.Any(), "2187b119").
+		Return([]models.ClickStats{{Day: day, Clicks: 3, Visitors: 2}}, nil).
+		Times(1)
+	rm.
+		EXPECT().
+		StoreClicks(gomock.Any(), gomock.Len(1)).
+		Return(nil).
+		Times(1)
+
+	clickRecorder := async.NewClickRecorder(store, time.Hour)
+	clickHub := async.NewClickHub()
+	subscription := clickHub.Subscribe("1", nil)
+	sh := NewRouter(options, &store, clickRecorder, clickHub)
+	JWTToken, _ := auth.BuildJWTString("1")
+
+	testCases := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{method: http.MethodGet, path: "/2187b119", expectedCode: http.StatusTemporaryRedirect},
+		{method: http.MethodGet, path: "/api/user/urls/2187b119/stats", expectedCode: http.StatusOK, expectedBody: `[{"date":"2024-05-01","clicks":3,"visitors":2}]`},
+		{method: http.MethodGet, path: "/api/user/urls/other123/stats", expectedCode: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			r.Header.Set("Cookie", "Authorization="+JWTToken)
+			w := httptest.NewRecorder()
+
+			sh.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+	clickRecorder.Close()
+	assert.Len(t, subscription.Clicks(), 1, "redirect published to live clicks feed")
+}
+
+func TestServer_updateURL(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	replacedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	history := []models.URLVersion{{ShortURL: "2187b119", Version: 1, OriginalURL: "https://practicum.yandex.ru/", ReplacedAt: replacedAt}}
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "2187b119", "https://practicum.yandex.kz/").
+		Return(nil).
+		Times(1)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "2187b119", "https://ya.ru/").
+		Return(storage.ErrConflict).
+		Times(1)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "other123", gomock.Any()).
+		Return(storage.ErrNotFound).
+		Times(1)
+	rm.
+		EXPECT().
+		UpdateURL(gomock.Any(), "2187b119", "https://practicum.yandex.ru/").
+		Return(nil).
+		Times(1)
+	rm.
+		EXPECT().
+		FindByID(gomock.Any(), "2187b119").
+		Return(newTestShortURL(t, "https://practicum.yandex.kz/", "1"), nil).
+		Times(1)
+	rm.
+		EXPECT().
+		GetURLHistory(gomock.Any(), "2187b119").
+		Return(history, nil).
+		Times(3)
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+
+	testCases := []struct {
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{method: http.MethodPatch, path: "/api/user/urls/2187b119", body: `{"url":"https://practicum.yandex.kz/"}`, expectedCode: http.StatusOK, expectedBody: `{"result":"http://localhost:8080/2187b119"}`},
+		{method: http.MethodPatch, path: "/api/user/urls/2187b119", body: `{"url":"https://ya.ru/"}`, expectedCode: http.StatusConflict},
+		{method: http.MethodPatch, path: "/api/user/urls/other123", body: `{"url":"https://ya.ru/"}`, expectedCode: http.StatusNotFound},
+		{method: http.MethodPatch, path: "/api/user/urls/2187b119", body: `{"url":"not a url"}`, expectedCode: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/api/user/urls/2187b119/history", expectedCode: http.StatusOK, expectedBody: `[{"version":1,"original_url":"https://practicum.yandex.ru/","replaced_at":"2024-05-01T00:00:00Z"}]`},
+		{method: http.MethodPost, path: "/api/user/urls/2187b119/history/1/restore", expectedCode: http.StatusOK, expectedBody: `{"result":"http://localhost:8080/2187b119"}`},
+		{method: http.MethodPost, path: "/api/user/urls/2187b119/history/2/restore", expectedCode: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/user/urls/2187b119/history/zero/restore", expectedCode: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			r.Header.Set("Cookie", "Authorization="+JWTToken)
+			w := httptest.NewRecorder()
+
+			sh.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_restore(t *testing.T) {
+	options := &config.Options{
+		ServerAddress: ":8080",
+		BaseURL:       "http://localhost:8080",
+	}
+
+	var store storage.Repository
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	store = rm
+
+	rm.
+		EXPECT().
+		RestoreShortURLs(gomock.Any(), []string{"2187b119", "other123"}).
+		Return([]string{"2187b119"}, nil).
+		Times(1)
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+
+	r := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", strings.NewReader(`["2187b119","other123"]`))
+	r.Header.Set("Cookie", "Authorization="+JWTToken)
+	w := httptest.NewRecorder()
+	sh.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `["2187b119"]`, w.Body.String(), "only restored short IDs returned")
+
+	r = httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", strings.NewReader(`not json`))
+	r.Header.Set("Cookie", "Authorization="+JWTToken)
+	w = httptest.NewRecorder()
+	sh.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestServer_batchStatuses(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	var store storage.Repository = storage.NewMemoryStorage()
+	stored := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	require.NoError(t, store.Store(context.Background(), &stored))
+	taken := models.NewAliasShortURL("taken-alias", "https://ya.ru/", "2")
+	require.NoError(t, store.Store(context.Background(), &taken))
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+
+	r := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", strings.NewReader(`[
+		{"correlation_id": "new", "original_url": "https://practicum.yandex.kz/"},
+		{"correlation_id": "old", "original_url": "https://practicum.yandex.ru/"},
+		{"correlation_id": "limit", "original_url": "https://practicum.yandex.ru/sale", "max_clicks": -1},
+		{"correlation_id": "alias", "original_url": "https://practicum.yandex.ru/sale", "alias": "taken-alias"},
+		{"correlation_id": "new", "original_url": "https://practicum.yandex.ru/other"}
+	]`))
+	r.Header.Set("Cookie", "Authorization="+JWTToken)
+	w := httptest.NewRecorder()
+	sh.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code, "partial success")
+	assert.JSONEq(t, `[
+		{"correlation_id": "new", "short_url": "http://localhost:8080/2a49568d", "status": "created"},
+		{"correlation_id": "old", "short_url": "http://localhost:8080/2187b119", "status": "existing"},
+		{"correlation_id": "limit", "status": "invalid", "error": "max_clicks must not be negative"},
+		{"correlation_id": "alias", "status": "invalid", "error": "alias is already taken"},
+		{"correlation_id": "new", "status": "invalid", "error": "correlation_id is already used in batch"}
+	]`, w.Body.String(), "result of every URL in request order")
+}
+
+func TestServer_APIShortenImportHandle(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	var store storage.Repository = storage.NewMemoryStorage()
+	stored := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	require.NoError(t, store.Store(context.Background(), &stored))
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+
+	testCases := []struct {
+		name         string
+		contentType  string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body: `{"correlation_id": "new", "original_url": "https://practicum.yandex.kz/"}` + "\n" +
+				`{"original_url": "https://practicum.yandex.ru/"}` + "\n" +
+				`{"original_url": ` + "\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"line":1,"correlation_id":"new","short_url":"http://localhost:8080/2a49568d","status":"created"}` + "\n" +
+				`{"line":2,"correlation_id":"2","short_url":"http://localhost:8080/2187b119","status":"existing"}` + "\n" +
+				`{"line":3,"correlation_id":"3","status":"invalid","error":"line can not be parsed: unexpected end of JSON input"}` + "\n",
+		},
+		{
+			name:         "CSV",
+			contentType:  "text/csv",
+			body:         "original_url,max_clicks\nhttps://practicum.yandex.kz/,-1\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"line":2,"correlation_id":"2","status":"invalid","error":"max_clicks must not be negative"}` + "\n",
+		},
+		{
+			name:         "CSV without original_url column",
+			contentType:  "text/csv",
+			body:         "url\nhttps://practicum.yandex.kz/\n",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "CSV header must have original_url column\n",
+		},
+		{
+			name:         "unsupported content type",
+			contentType:  "application/json",
+			body:         `[{"original_url": "https://practicum.yandex.kz/"}]`,
+			expectedCode: http.StatusUnsupportedMediaType,
+			expectedBody: "import accepts only application/x-ndjson or text/csv\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/shorten/import", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			r.Header.Set("Cookie", "Authorization="+JWTToken)
+			w := httptest.NewRecorder()
+			sh.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestServer_UserURLsExportHandle(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	var store storage.Repository = storage.NewMemoryStorage()
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err := store.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", CreatedAt: createdAt, MaxClicks: 10},
+		{UUID: "sale", OriginalURL: "https://ya.ru/?a=1,b=2", UserID: "1", IsAlias: true, CreatedAt: createdAt.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.RegisterClick(ctx, "2187b119"))
+	require.NoError(t, store.DeleteShortURLs(ctx, []string{"sale"}))
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+	emptyUserToken, _ := auth.BuildJWTString("2")
+
+	testCases := []struct {
+		name                string
+		query               string
+		token               string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "JSON by default without deleted",
+			token:               JWTToken,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: `[{"short_id":"2187b119","short_url":"http://localhost:8080/2187b119","original_url":"https://practicum.yandex.ru/",` +
+				`"is_alias":false,"created_at":"2024-05-01T00:00:00Z","max_clicks":10,"clicks":1,"is_deleted":false}]`,
+		},
+		{
+			name:                "empty JSON",
+			token:               emptyUserToken,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `[]`,
+		},
+		{
+			name:                "NDJSON",
+			query:               "?format=ndjson&include_deleted=false",
+			token:               JWTToken,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody: `{"short_id":"2187b119","short_url":"http://localhost:8080/2187b119","original_url":"https://practicum.yandex.ru/",` +
+				`"is_alias":false,"created_at":"2024-05-01T00:00:00Z","max_clicks":10,"clicks":1,"is_deleted":false}` + "\n",
+		},
+		{
+			name:                "CSV",
+			query:               "?format=csv",
+			token:               emptyUserToken,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "short_id,short_url,original_url,is_alias,created_at,expires_at,max_clicks,clicks,is_deleted,deleted_at\n",
+		},
+		{
+			name:         "unknown format",
+			query:        "?format=xml",
+			token:        JWTToken,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "export format must be json, ndjson or csv\n",
+		},
+		{
+			name:         "broken include_deleted",
+			query:        "?include_deleted=maybe",
+			token:        JWTToken,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "include_deleted must be a boolean\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/user/urls/export"+tc.query, nil)
+			r.Header.Set("Cookie", "Authorization="+tc.token)
+			w := httptest.NewRecorder()
+			sh.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+
+	t.Run("CSV with deleted", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=csv&include_deleted=1", nil)
+		r.Header.Set("Cookie", "Authorization="+JWTToken)
+		w := httptest.NewRecorder()
+		sh.ServeHTTP(w, r)
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"sale", "http://localhost:8080/sale", "https://ya.ru/?a=1,b=2", "true", "2024-05-01T01:00:00Z", "", "0", "0", "true"}, records[2][:9])
+		assert.NotEmpty(t, records[2][9], "moment of deletion exported")
+	})
+}
+
+func TestServer_UserURLsExportHandle_redirectClicks(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	var store storage.Repository = storage.NewMemoryStorage()
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	_, err := store.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"},
+	})
+	require.NoError(t, err)
+
+	recorder := async.NewClickRecorder(store, time.Hour)
+	sh := NewRouter(options, &store, recorder, nil)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		sh.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/2187b119", nil))
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	}
+	recorder.Close()
+
+	JWTToken, _ := auth.BuildJWTString("1")
+	r := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=ndjson", nil)
+	r.Header.Set("Cookie", "Authorization="+JWTToken)
+	w := httptest.NewRecorder()
+	sh.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var exported dto.ExportURLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+	assert.Equal(t, 0, exported.MaxClicks)
+	assert.Equal(t, 2, exported.Clicks, "redirects of URL without clicks limit are exported")
+}
+
+func TestServer_UserURLsExportHandle_storageFailure(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	rm.EXPECT().ListUserURLs(gomock.Any(), gomock.Any()).Return(models.URLsPage{}, errors.New("connection refused"))
+	var store storage.Repository = rm
+
+	sh := NewRouter(options, &store, nil, nil)
+	JWTToken, _ := auth.BuildJWTString("1")
+	r := httptest.NewRequest(http.MethodGet, "/api/user/urls/export", nil)
+	r.Header.Set("Cookie", "Authorization="+JWTToken)
+	w := httptest.NewRecorder()
+	sh.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "failure before first page is reported by status")
+}
+
+func TestServer_PingHandle_decoratedStorage(t *testing.T) {
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	sqliteStore, err := storage.NewSQLiteStorage(context.Background(), filepath.Join(t.TempDir(), "store.sqlite"))
+	require.NoError(t, err)
+	var store storage.Repository = storage.NewCachedRepository(metrics.NewInstrumentedRepository(sqliteStore), storage.DefaultCacheSize, storage.DefaultCacheTTL)
+
+	ping := func(store storage.Repository) int {
+		w := httptest.NewRecorder()
+		NewRouter(options, &store, nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, ping(store), "storage is pinged through decorators")
+
+	require.NoError(t, sqliteStore.Close())
+	assert.Equal(t, http.StatusInternalServerError, ping(store), "closed storage")
+
+	assert.Equal(t, http.StatusInternalServerError, ping(metrics.NewInstrumentedRepository(storage.NewMemoryStorage())), "storage without connection")
+}
+
+func BenchmarkGetShortURLHandle(b *testing.B) {
+	sqliteStore, err := storage.NewSQLiteStorage(context.Background(), filepath.Join(b.TempDir(), "store.sqlite"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sqliteStore.Close()
+
+	const IDs = 1000
+	shortURLs := make([]models.ShortenURL, 0, IDs)
+	for i := 0; i < IDs; i++ {
+		ID := fmt.Sprintf("%08d", i)
+		shortURLs = append(shortURLs, models.ShortenURL{UUID: ID, OriginalURL: "https://practicum.yandex.ru/" + ID, UserID: "1"})
+	}
+	if _, err = sqliteStore.StoreBatch(context.Background(), shortURLs); err != nil {
+		b.Fatal(err)
+	}
+
+	options := &config.Options{ServerAddress: ":8080", BaseURL: "http://localhost:8080"}
+	for _, bm := range []struct {
+		name  string
+		store storage.Repository
+	}{
+		{name: "without cache", store: sqliteStore},
+		{name: "with cache", store: storage.NewCachedRepository(sqliteStore, storage.DefaultCacheSize, storage.DefaultCacheTTL)},
+	} {
+		sh := NewRouter(options, &bm.store, nil, nil)
+		b.Run(bm.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					w := httptest.NewRecorder()
+					sh.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%08d", i%IDs), nil))
+					if w.Code != http.StatusTemporaryRedirect {
+						b.Fatalf("unexpected status %d", w.Code)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// Errors of shortened URL limits validation
+var (
+	// ErrInvalidExpiration - error when shortened URL expiration date is in the past
+	ErrInvalidExpiration = errors.New("expires_at must be in the future")
+	// ErrInvalidMaxClicks - error when shortened URL clicks limit is negative
+	ErrInvalidMaxClicks = errors.New("max_clicks must not be negative")
+)
+
+// shortenParams - user provided params of shortened URL creation
+type shortenParams struct {
+	originalURL string
+	userID      string
+	alias       string
+	expiresAt   time.Time
+	maxClicks   int
+}
+
+// newShortURL - validate params and create ShortenURL from them, generation failure is reported by models.ErrIDGeneration
+func newShortURL(params shortenParams) (shortURL models.ShortenURL, err error) {
+	if !params.expiresAt.IsZero() && !params.expiresAt.After(time.Now()) {
+		return shortURL, ErrInvalidExpiration
+	}
+	if params.maxClicks < 0 {
+		return shortURL, ErrInvalidMaxClicks
+	}
+
+	if params.alias != "" {
+		if err = validateAlias(params.alias); err != nil {
+			return
+		}
+		shortURL = models.NewAliasShortURL(params.alias, params.originalURL, params.userID)
+	} else {
+		if shortURL, err = models.NewShortURL(params.originalURL, params.userID); err != nil {
+			return
+		}
+	}
+	shortURL.ExpiresAt = params.expiresAt
+	shortURL.MaxClicks = params.maxClicks
+	return
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newShortURL(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		params  shortenParams
+		want    models.ShortenURL
+		wantErr error
+	}{
+		{
+			name:   "generated ID",
+			params: shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1"},
+			want:   newTestShortURL(t, "https://practicum.yandex.ru/", "1"),
+		},
+		{
+			name:   "alias with limits",
+			params: shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1", alias: "spring-sale", expiresAt: expiresAt, maxClicks: 10},
+			want: models.ShortenURL{
+				UUID: "spring-sale", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", IsAlias: true, ExpiresAt: expiresAt, MaxClicks: 10,
+			},
+		},
+		{
+			name:    "expiration in the past",
+			params:  shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1", expiresAt: time.Now().Add(-time.Hour)},
+			wantErr: ErrInvalidExpiration,
+		},
+		{
+			name:    "negative clicks limit",
+			params:  shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1", maxClicks: -1},
+			wantErr: ErrInvalidMaxClicks,
+		},
+		{
+			name:    "reserved alias",
+			params:  shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1", alias: "ping"},
+			wantErr: ErrReservedAlias,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortURL, err := newShortURL(tt.params)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, shortURL)
+		})
+	}
+}
+
+// failingIDGenerator - IDGenerator whose random source is broken
+type failingIDGenerator struct{}
+
+func (g failingIDGenerator) Generate(_ string, _ string, _ int) (string, error) {
+	return "", errors.Join(models.ErrIDGeneration, errors.New("entropy is exhausted"))
+}
+
+func Test_newShortURL_generationFailure(t *testing.T) {
+	models.SetIDGenerator(failingIDGenerator{})
+	defer models.SetIDGenerator(models.HashIDGenerator{})
+
+	_, err := newShortURL(shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1"})
+	assert.ErrorIs(t, err, models.ErrIDGeneration)
+
+	shortURL, err := newShortURL(shortenParams{originalURL: "https://practicum.yandex.ru/", userID: "1", alias: "spring-sale"})
+	assert.NoError(t, err, "alias does not need generated ID")
+	assert.Equal(t, "spring-sale", shortURL.UUID)
+}
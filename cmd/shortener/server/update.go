@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// Errors of shortened URL destination change
+var (
+	// ErrInvalidURL - error when new original URL is not absolute http or https URL
+	ErrInvalidURL = errors.New("url must be absolute http or https URL")
+	// ErrUnknownVersion - error when restored version is absent in shortened URL history
+	ErrUnknownVersion = errors.New("no such version in shortened URL history")
+)
+
+// validateOriginalURL - check if original URL can become new destination of shortened URL
+func validateOriginalURL(originalURL string) error {
+	parsed, err := url.ParseRequestURI(originalURL)
+	if err != nil || parsed.Host == "" || parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// restoreURLVersion - make original URL of history version current destination of shortened URL
+func restoreURLVersion(ctx context.Context, repository storage.Repository, ID string, version int) error {
+	versions, err := repository.GetURLHistory(ctx, ID)
+	if err != nil {
+		return err
+	}
+	for _, previous := range versions {
+		if previous.Version == version {
+			return repository.UpdateURL(ctx, ID, previous.OriginalURL)
+		}
+	}
+	return ErrUnknownVersion
+}
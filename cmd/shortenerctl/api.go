@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
+)
+
+// batchMessageSize - amount of URLs sent in one message of import stream by batch command
+const batchMessageSize = 100
+
+// apiCall - call of API by command with connected client and arguments left after flags
+type apiCall func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error
+
+// apiCommand - build run of command which calls API: setup registers flags of command and returns its call,
+// run parses flags, checks amount of arguments, negative maxArgs is unlimited, and connects to API
+func apiCommand(name string, arguments string, minArgs int, maxArgs int, setup func(flags *flag.FlagSet) apiCall,
+) func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	return func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (err error) {
+		var connection clientFlags
+		flags := newFlagSet(name, arguments, stderr)
+		connection.register(flags)
+		call := setup(flags)
+		if err = parseFlags(flags, args); err != nil {
+			return
+		}
+		if flags.NArg() < minArgs || (maxArgs >= 0 && flags.NArg() > maxArgs) {
+			flags.Usage()
+			return errUsage
+		}
+
+		ctx, client, closeClient, err := connection.connect(ctx, stdout)
+		if err != nil {
+			return
+		}
+		defer func() {
+			err = errors.Join(err, closeClient())
+		}()
+		return apiError(call(ctx, client, flags.Args(), stderr))
+	}
+}
+
+// setupShort - shorten URL, server registers new user when token is not set
+func setupShort(flags *flag.FlagSet) apiCall {
+	request := &pb.ShortRequest{}
+	alias := flags.String("alias", "", "user defined short ID")
+	expiresAt := flags.String("expires-at", "", "RFC 3339 moment after which short URL expires")
+	maxClicks := flags.Int64("max-clicks", 0, "amount of clicks after which short URL expires, 0 is unlimited")
+
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		request.Url, request.Alias, request.MaxClicks = args[0], *alias, *maxClicks
+		if *expiresAt != "" {
+			moment, err := time.Parse(time.RFC3339, *expiresAt)
+			if err != nil {
+				return fmt.Errorf("wrong -expires-at: %w", err)
+			}
+			request.ExpiresAt = timestamppb.New(moment)
+		}
+
+		var header metadata.MD
+		response, err := client.Short(ctx, request, grpc.Header(&header))
+		if err != nil {
+			return err
+		}
+		reportIssuedToken(header, stderr)
+		return client.print(response, []string{"SHORT URL"}, func(add func(cells ...any)) {
+			add(response.Result)
+		})
+	}
+}
+
+// setupExpand - show original URL of short ID
+func setupExpand(flags *flag.FlagSet) apiCall {
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		response, err := client.Expand(ctx, &pb.ExpandRequest{ShortId: args[0]})
+		if err != nil {
+			return err
+		}
+		return client.print(response, []string{"ORIGINAL URL"}, func(add func(cells ...any)) {
+			add(response.Url)
+		})
+	}
+}
+
+// setupList - show page of user's short URLs, -all follows cursors until the last page
+func setupList(flags *flag.FlagSet) apiCall {
+	request := &pb.GetUserBucketRequest{}
+	limit := flags.Int("limit", 0, "page size, 100 when not set")
+	flags.StringVar(&request.Cursor, "cursor", "", "next cursor of previous page")
+	flags.StringVar(&request.SortBy, "sort", "", "sort field: created_at or original_url")
+	flags.BoolVar(&request.Desc, "desc", false, "sort in descending order")
+	flags.StringVar(&request.Status, "status", "", "filter by status: all, active or deleted")
+	flags.StringVar(&request.Search, "q", "", "case-insensitive substring of original or short URL")
+	all := flags.Bool("all", false, "list every page")
+
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		request.Limit = int32(*limit)
+		listed := &pb.GetUserBucketResponse{}
+		for {
+			page, err := client.GetUserBucket(ctx, request)
+			if err != nil {
+				return err
+			}
+			listed.Data = append(listed.Data, page.Data...)
+			listed.NextCursor = page.NextCursor
+			if !*all || page.NextCursor == "" {
+				break
+			}
+			request.Cursor = page.NextCursor
+		}
+
+		if listed.NextCursor != "" && client.profile.Output == outputTable {
+			fmt.Fprintf(stderr, "Next page: -cursor %s\n", listed.NextCursor)
+		}
+		return client.print(listed, []string{"SHORT URL", "ORIGINAL URL"}, func(add func(cells ...any)) {
+			for _, shortURL := range listed.Data {
+				add(shortURL.ShortUrl, shortURL.OriginalUrl)
+			}
+		})
+	}
+}
+
+// setupDelete - queue deletion of user's short URLs, server deletes them asynchronously
+func setupDelete(flags *flag.FlagSet) apiCall {
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		response, err := client.Delete(ctx, &pb.DeleteRequest{Id: args})
+		if err != nil {
+			return err
+		}
+		return client.print(response, []string{"SHORT ID", "STATUS"}, func(add func(cells ...any)) {
+			for _, shortID := range args {
+				add(shortID, "deletion queued")
+			}
+		})
+	}
+}
+
+// readBatchFile - read URLs of batch file, it is JSON array of /api/shorten/batch request or stream of its items,
+// items without correlation ID get their position in file
+func readBatchFile(r io.Reader) (originals []*pb.CorrelatedOriginalURL, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	var items []dto.BatchShortenRequest
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &items)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for decoder.More() {
+			var item dto.BatchShortenRequest
+			if err = decoder.Decode(&item); err != nil {
+				break
+			}
+			items = append(items, item)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	originals = make([]*pb.CorrelatedOriginalURL, 0, len(items))
+	for i, item := range items {
+		if item.CorrelationID == "" {
+			item.CorrelationID = strconv.Itoa(i + 1)
+		}
+		original := &pb.CorrelatedOriginalURL{
+			CorrelationId: item.CorrelationID,
+			OriginalUrl:   item.URL,
+			Alias:         item.Alias,
+			MaxClicks:     int64(item.MaxClicks),
+		}
+		if !item.ExpiresAt.IsZero() {
+			original.ExpiresAt = timestamppb.New(item.ExpiresAt)
+		}
+		originals = append(originals, original)
+	}
+	return originals, nil
+}
+
+// setupBatch - shorten URLs of file, "-" is stdin, by import stream; results of stored chunks are collected
+// and invalid URLs fail command after results are printed
+func setupBatch(flags *flag.FlagSet) apiCall {
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		file := os.Stdin
+		if args[0] != "-" {
+			var err error
+			if file, err = os.Open(args[0]); err != nil {
+				return err
+			}
+			defer file.Close()
+		}
+		originals, err := readBatchFile(file)
+		if err != nil {
+			return fmt.Errorf("batch file is broken: %w", err)
+		}
+
+		stream, err := client.ImportURLs(ctx)
+		if err != nil {
+			return err
+		}
+		// results are received while URLs are sent, otherwise server blocks on results and stops reading
+		go func() {
+			for start := 0; start < len(originals); start += batchMessageSize {
+				// failed Send means that server ended stream, its status is returned by Recv
+				if err := stream.Send(&pb.ImportURLsRequest{Original: originals[start:min(start+batchMessageSize, len(originals))]}); err != nil {
+					return
+				}
+			}
+			_ = stream.CloseSend()
+		}()
+		response := &pb.ImportURLsResponse{}
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			response.Short = append(response.Short, chunk.Short...)
+			response.Created, response.Existing, response.Invalid = chunk.Created, chunk.Existing, chunk.Invalid
+		}
+		if header, err := stream.Header(); err == nil {
+			reportIssuedToken(header, stderr)
+		}
+
+		if client.profile.Output == outputTable {
+			fmt.Fprintf(stderr, "Created %d, existing %d, invalid %d\n", response.Created, response.Existing, response.Invalid)
+		}
+		err = client.print(response, []string{"CORRELATION ID", "STATUS", "SHORT URL", "ERROR"}, func(add func(cells ...any)) {
+			for _, shortURL := range response.Short {
+				itemStatus := strings.ToLower(strings.TrimPrefix(shortURL.Status.String(), "BATCH_ITEM_STATUS_"))
+				add(shortURL.CorrelationId, itemStatus, shortURL.ShortUrl, shortURL.Error)
+			}
+		})
+		if err == nil && response.Invalid > 0 {
+			err = fmt.Errorf("%d of %d URLs are not shortened", response.Invalid, len(response.Short))
+		}
+		return err
+	}
+}
+
+// setupStats - show amounts of users and short URLs, server allows it only from trusted subnet
+func setupStats(flags *flag.FlagSet) apiCall {
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		response, err := client.Stats(ctx, &pb.StatsRequest{})
+		if err != nil {
+			return err
+		}
+		return client.print(response, []string{"URLS", "USERS", "ACTIVE", "DELETED", "PURGED"}, func(add func(cells ...any)) {
+			add(response.Urls, response.Users, response.Active, response.Deleted, response.Purged)
+		})
+	}
+}
+
+// setupMigrations - show schema migrations of server storage, server allows it only from trusted subnet
+func setupMigrations(flags *flag.FlagSet) apiCall {
+	return func(ctx context.Context, client *apiClient, args []string, stderr io.Writer) error {
+		if args[0] != "status" {
+			return fmt.Errorf("unknown migrations action %q, only status is supported", args[0])
+		}
+		response, err := client.MigrationStatus(ctx, &pb.MigrationStatusRequest{})
+		if err != nil {
+			return err
+		}
+		return client.print(response, []string{"VERSION", "LATEST", "PENDING", "DIRTY"}, func(add func(cells ...any)) {
+			add(response.Version, response.Latest, response.Pending, response.Dirty)
+		})
+	}
+}
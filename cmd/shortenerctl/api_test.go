@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PaBah/url-shortener.git/cmd/shortener/server"
+	"github.com/PaBah/url-shortener.git/internal/async"
+	"github.com/PaBah/url-shortener.git/internal/config"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// startTestServer - run gRPC API over memory storage on random local port, loopback is trusted proxy
+// which passes client IP from 10.0.0.0/8 trusted subnet
+func startTestServer(t *testing.T) string {
+	options := &config.Options{
+		BaseURL:        "http://localhost:8080",
+		TrustedSubnet:  "10.0.0.0/8",
+		TrustedProxies: "127.0.0.0/8",
+	}
+	var store storage.Repository = storage.NewMemoryStorage()
+	s, err := server.NewGRPCServer(server.NewShortenerServer(options, &store, async.NewClickHub()))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+	return listener.Addr().String()
+}
+
+func TestAPICommands(t *testing.T) {
+	t.Setenv(envConfig, filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv(envProfile, "")
+	addr := startTestServer(t)
+
+	code, stdout, stderr := runCommand("short", "-addr", addr, "-alias", "sale", "https://practicum.yandex.ru/")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "SHORT URL\nsale\n", stdout)
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	token := lines[len(lines)-1]
+	require.NotEmpty(t, token, "token of new user is printed")
+
+	code, stdout, stderr = runCommand("expand", "-addr", addr, "sale")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "https://practicum.yandex.ru/")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token+"\n"), 0600))
+	batchFile := filepath.Join(t.TempDir(), "batch.ndjson")
+	require.NoError(t, os.WriteFile(batchFile, []byte(
+		`{"correlation_id": "ya", "original_url": "https://ya.ru/"}
+{"original_url": "https://ya.ru/"}
+{"original_url": "https://practicum.yandex.ru/"}
+`), 0600))
+	code, stdout, stderr = runCommand("batch", "-addr", addr, "-token-file", tokenFile, batchFile)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "ya ")
+	assert.Contains(t, stdout, "created")
+	assert.Contains(t, stdout, "existing", "URL is repeated in file")
+	assert.Contains(t, stdout, "3 ", "correlation ID of item without it is its position")
+	assert.Contains(t, stderr, "Created 2, existing 1, invalid 0")
+
+	require.NoError(t, os.WriteFile(batchFile, []byte(`[{"correlation_id": "taken", "original_url": "https://ya.ru/sale", "alias": "sale"}]`), 0600))
+	code, stdout, stderr = runCommand("batch", "-addr", addr, "-token", token, batchFile)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stdout, "invalid")
+	assert.Contains(t, stderr, "1 of 1 URLs are not shortened")
+
+	code, stdout, stderr = runCommand("list", "-addr", addr, "-token", token, "-o", "json", "-all", "-limit", "1")
+	require.Equal(t, exitOK, code, stderr)
+	var listed struct {
+		Data []struct {
+			ShortURL string `json:"short_url"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &listed))
+	assert.Len(t, listed.Data, 3, "every page listed")
+
+	code, _, stderr = runCommand("list", "-addr", addr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "Unauthenticated", "token is required")
+
+	code, stdout, stderr = runCommand("delete", "-addr", addr, "-token", token, "sale")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "deletion queued")
+
+	code, _, stderr = runCommand("stats", "-addr", addr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "PermissionDenied")
+	code, stdout, stderr = runCommand("stats", "-addr", addr, "-real-ip", "10.0.0.1")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "URLS")
+
+	code, _, stderr = runCommand("migrations", "-addr", addr, "-real-ip", "10.0.0.1", "status")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "storage backend has no schema migrations")
+
+	code, _, _ = runCommand("migrations", "-addr", addr)
+	assert.Equal(t, exitUsage, code, "action is required")
+}
+
+func TestClientFlags_profile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"default_profile": "prod",
+		"profiles": {
+			"prod": {"address": "shortener.example.com:3200", "token": "prod-token", "tls": true, "output": "json"},
+			"local": {"real_ip": "127.0.0.1"}
+		}
+	}`), 0600))
+	t.Setenv(envConfig, configPath)
+	t.Setenv(envProfile, "")
+
+	resolve := func(args ...string) (profile, error) {
+		var connection clientFlags
+		flags := newFlagSet("test", "", &strings.Builder{})
+		connection.register(flags)
+		require.NoError(t, flags.Parse(args))
+		return connection.profile()
+	}
+
+	p, err := resolve()
+	require.NoError(t, err)
+	assert.Equal(t, profile{Address: "shortener.example.com:3200", Token: "prod-token", TLS: true, Output: outputJSON}, p, "default profile of config")
+
+	p, err = resolve("-profile", "local", "-o", "table")
+	require.NoError(t, err)
+	assert.Equal(t, profile{Address: "localhost:3200", RealIP: "127.0.0.1", Output: outputTable}, p, "defaults filled")
+
+	p, err = resolve("-addr", "localhost:3300", "-tls=false")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:3300", p.Address, "flags override profile")
+	assert.False(t, p.TLS)
+
+	t.Setenv(envToken, "env-token")
+	p, err = resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", p.Token, "environment overrides profile")
+
+	_, err = resolve("-profile", "staging")
+	assert.ErrorContains(t, err, `profile "staging" is not found`)
+	_, err = resolve("-o", "yaml")
+	assert.ErrorContains(t, err, `unknown output format "yaml"`)
+
+	_, err = resolve("-config", filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, err, "config file is optional")
+	_, err = resolve("-config", filepath.Join(t.TempDir(), "missing.json"), "-profile", "prod")
+	assert.Error(t, err, "chosen profile has to exist")
+
+	_, err = profile{TLS: true, CACert: configPath}.transportCredentials()
+	assert.ErrorContains(t, err, "no CA certificates")
+}
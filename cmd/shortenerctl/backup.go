@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/backup"
+)
+
+// progressPrinter - returns backup.Progress which prints amount of processed URLs of operation
+func progressPrinter(w io.Writer, operation string) backup.Progress {
+	return func(processed int) {
+		fmt.Fprintf(w, "%s: %d URLs\n", operation, processed)
+	}
+}
+
+// runBackup - write archive of all short URLs of storage to file, archive is written to temporary file in the same directory,
+// read back to verify counts and only then renamed to requested path. Storage is only read, so server may keep running
+func runBackup(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	var source storageFlags
+	flags := newFlagSet("backup", "ARCHIVE", stderr)
+	source.register(flags)
+	if err = parseFlags(flags, args); err != nil {
+		return
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	path := flags.Arg(0)
+
+	store, closeStore, err := source.open(ctx, true)
+	if err != nil {
+		return fmt.Errorf("storage can not be opened: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, closeStore())
+	}()
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".shortenerctl-backup-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = file.Close()
+		if err != nil {
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	counts, err := backup.Create(ctx, store, file, source.backend, progressPrinter(stderr, "backup"))
+	if err != nil {
+		return fmt.Errorf("backup failed after %d URLs: %w", counts.URLs, err)
+	}
+	if err = file.Sync(); err != nil {
+		return
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	_, checked, err := backup.Check(file)
+	if err != nil {
+		return fmt.Errorf("written archive is broken: %w", err)
+	}
+	if checked != counts {
+		return fmt.Errorf("%w: %s are written, but %s are read back", backup.ErrCountsMismatch, counts, checked)
+	}
+	if err = os.Rename(file.Name(), path); err != nil {
+		return
+	}
+
+	fmt.Fprintf(stdout, "Backup of %s storage is written to %s: %s\n", source.backend, path, counts)
+	return nil
+}
+
+// runRestore - load archive into storage and verify that storage keeps archived counts.
+// Archive is read twice: it is checked completely before anything is stored
+func runRestore(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (err error) {
+	var target storageFlags
+	flags := newFlagSet("restore", "ARCHIVE", stderr)
+	target.register(flags)
+	force := flags.Bool("force", false, "restore into storage which already has short URLs, archived ones replace stored with the same short IDs")
+	if err = parseFlags(flags, args); err != nil {
+		return
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	header, counts, err := backup.Check(file)
+	if err != nil {
+		return fmt.Errorf("archive is broken: %w", err)
+	}
+	fmt.Fprintf(stderr, "Archive of %s storage created at %s: %s\n", header.Backend, header.CreatedAt.Format(time.RFC3339), counts)
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+
+	store, closeStore, err := target.open(ctx, false)
+	if err != nil {
+		return fmt.Errorf("storage can not be opened: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, closeStore())
+	}()
+
+	stats, err := store.GetStats(ctx)
+	if err != nil {
+		return
+	}
+	if stats.URLs > 0 && !*force {
+		return fmt.Errorf("storage already has %d short URLs, use -force to restore into it", stats.URLs)
+	}
+
+	if _, err = backup.Restore(ctx, store, file, progressPrinter(stderr, "restore")); err != nil {
+		return fmt.Errorf("restore is interrupted, restored part is kept: %w", err)
+	}
+	if stats.URLs > 0 {
+		fmt.Fprintf(stdout, "Archive is restored into %s storage: %s, counts are not verified as storage was not empty\n", target.backend, counts)
+		return nil
+	}
+	if err = backup.Verify(ctx, store, counts); err != nil {
+		return
+	}
+	fmt.Fprintf(stdout, "Archive is restored into %s storage and verified: %s\n", target.backend, counts)
+	return nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
+)
+
+// Output formats of API commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// Environment variables which are used when matching flags are not set
+const (
+	envConfig  = "SHORTENERCTL_CONFIG"
+	envProfile = "SHORTENERCTL_PROFILE"
+	envToken   = "SHORTENERCTL_TOKEN"
+)
+
+// defaultProfileName - profile used when neither -profile nor config chooses one
+const defaultProfileName = "default"
+
+// profile - connection settings of shortener gRPC API, flags of command override them
+type profile struct {
+	Address            string `json:"address"`
+	Token              string `json:"token,omitempty"`
+	TokenFile          string `json:"token_file,omitempty"`
+	RealIP             string `json:"real_ip,omitempty"`
+	TLS                bool   `json:"tls,omitempty"`
+	CACert             string `json:"ca_cert,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	Output             string `json:"output,omitempty"`
+}
+
+// profilesConfig - content of config file with named profiles
+type profilesConfig struct {
+	DefaultProfile string             `json:"default_profile,omitempty"`
+	Profiles       map[string]profile `json:"profiles"`
+}
+
+// defaultConfigPath - config file in user config directory, empty when the directory is unknown
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "shortenerctl", "config.json")
+}
+
+// withDefaults - fill settings which are not set in profile
+func (p profile) withDefaults() profile {
+	if p.Address == "" {
+		p.Address = "localhost:3200"
+	}
+	if p.Output == "" {
+		p.Output = outputTable
+	}
+	return p
+}
+
+// loadProfile - read named profile from config file, default profile is used when name is empty.
+// Missing config file or default profile give default settings, explicitly chosen profile has to exist
+func loadProfile(path string, name string) (p profile, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && name == "" {
+		return p.withDefaults(), nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("config can not be read: %w", err)
+	}
+	var config profilesConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return p, fmt.Errorf("config %s is broken: %w", path, err)
+	}
+
+	explicit := name != "" || config.DefaultProfile != ""
+	if name == "" {
+		name = config.DefaultProfile
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+	p, found := config.Profiles[name]
+	if !found && explicit {
+		return p, fmt.Errorf("profile %q is not found in %s", name, path)
+	}
+	return p.withDefaults(), nil
+}
+
+// clientFlags - flags of commands which call shortener gRPC API
+type clientFlags struct {
+	configPath  string
+	profileName string
+	timeout     time.Duration
+	override    profile
+	flags       *flag.FlagSet
+}
+
+// register - add connection and output flags to flag set
+func (f *clientFlags) register(flags *flag.FlagSet) {
+	f.flags = flags
+	flags.StringVar(&f.configPath, "config", "", "path to config file with profiles, $"+envConfig+" or "+defaultConfigPath()+" when not set")
+	flags.StringVar(&f.profileName, "profile", "", "name of profile in config file, $"+envProfile+" or default profile of config when not set")
+	flags.DurationVar(&f.timeout, "timeout", 30*time.Second, "timeout of command")
+	flags.StringVar(&f.override.Address, "addr", "", "host:port of shortener gRPC API")
+	flags.StringVar(&f.override.Token, "token", "", "JWT token of user, $"+envToken+" when not set")
+	flags.StringVar(&f.override.TokenFile, "token-file", "", "path to file with JWT token of user")
+	flags.StringVar(&f.override.RealIP, "real-ip", "", "client IP sent to server, it is used only when server trusts connection as proxy and has to be from trusted subnet for stats and migrations")
+	flags.BoolVar(&f.override.TLS, "tls", false, "connect over TLS")
+	flags.StringVar(&f.override.CACert, "ca-cert", "", "path to PEM file with CA certificates which verify server, system ones when not set")
+	flags.StringVar(&f.override.ServerName, "server-name", "", "server name checked in certificate, host of -addr when not set")
+	flags.BoolVar(&f.override.InsecureSkipVerify, "insecure-skip-verify", false, "do not verify server certificate")
+	flags.StringVar(&f.override.Output, "o", "", "output format: table or json")
+}
+
+// profile - resolve profile of config file and override it with set flags and environment
+func (f *clientFlags) profile() (p profile, err error) {
+	configPath, profileName := f.configPath, f.profileName
+	if configPath == "" {
+		configPath = os.Getenv(envConfig)
+	}
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+	if profileName == "" {
+		profileName = os.Getenv(envProfile)
+	}
+	if p, err = loadProfile(configPath, profileName); err != nil {
+		return
+	}
+	if token := os.Getenv(envToken); token != "" {
+		p.Token, p.TokenFile = token, ""
+	}
+
+	f.flags.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "addr":
+			p.Address = f.override.Address
+		case "token":
+			p.Token, p.TokenFile = f.override.Token, ""
+		case "token-file":
+			p.TokenFile, p.Token = f.override.TokenFile, ""
+		case "real-ip":
+			p.RealIP = f.override.RealIP
+		case "tls":
+			p.TLS = f.override.TLS
+		case "ca-cert":
+			p.CACert = f.override.CACert
+		case "server-name":
+			p.ServerName = f.override.ServerName
+		case "insecure-skip-verify":
+			p.InsecureSkipVerify = f.override.InsecureSkipVerify
+		case "o":
+			p.Output = f.override.Output
+		}
+	})
+
+	if p.Output != outputTable && p.Output != outputJSON {
+		return p, fmt.Errorf("unknown output format %q", p.Output)
+	}
+	if p.TokenFile != "" {
+		var token []byte
+		if token, err = os.ReadFile(p.TokenFile); err != nil {
+			return p, fmt.Errorf("token can not be read: %w", err)
+		}
+		p.Token = strings.TrimSpace(string(token))
+	}
+	return p, nil
+}
+
+// transportCredentials - TLS credentials of profile, plain connection when TLS is not enabled
+func (p profile) transportCredentials() (credentials.TransportCredentials, error) {
+	if !p.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         p.ServerName,
+		InsecureSkipVerify: p.InsecureSkipVerify,
+	}
+	if p.CACert != "" {
+		pem, err := os.ReadFile(p.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("CA certificates can not be read: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates are found in %s", p.CACert)
+		}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// apiClient - connected client of shortener gRPC API with output settings of profile
+type apiClient struct {
+	pb.ShortenerServiceClient
+	profile profile
+	stdout  io.Writer
+}
+
+// connect - resolve profile and open connection to API, returned context carries token and real IP metadata
+// and is cancelled after timeout, both context and connection are released by returned function
+func (f *clientFlags) connect(ctx context.Context, stdout io.Writer) (context.Context, *apiClient, func() error, error) {
+	p, err := f.profile()
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	creds, err := p.transportCredentials()
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	conn, err := grpc.Dial(p.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return ctx, nil, nil, fmt.Errorf("connection to %s failed: %w", p.Address, err)
+	}
+
+	if p.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.AuthorizationMetadataKey, "Bearer "+p.Token)
+	}
+	if p.RealIP != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.RealIPMetadataKey, p.RealIP)
+	}
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+
+	client := &apiClient{ShortenerServiceClient: pb.NewShortenerServiceClient(conn), profile: p, stdout: stdout}
+	return ctx, client, func() error {
+		cancel()
+		return conn.Close()
+	}, nil
+}
+
+// print - write response in JSON output format or as table of header and rows made by rows function
+func (c *apiClient) print(response proto.Message, header []string, rows func(add func(cells ...any))) error {
+	if c.profile.Output == outputJSON {
+		data, err := protojson.MarshalOptions{Multiline: true, UseProtoNames: true, EmitUnpopulated: true}.Marshal(response)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, string(data))
+		return err
+	}
+
+	table := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	rows(func(cells ...any) {
+		values := make([]string, 0, len(cells))
+		for _, cell := range cells {
+			values = append(values, fmt.Sprint(cell))
+		}
+		fmt.Fprintln(table, strings.Join(values, "\t"))
+	})
+	return table.Flush()
+}
+
+// reportIssuedToken - print token which server issued to new user, it happens when public method is called without token
+func reportIssuedToken(header metadata.MD, stderr io.Writer) {
+	if tokens := header.Get(auth.AuthorizationMetadataKey); len(tokens) > 0 {
+		fmt.Fprintf(stderr, "New user is registered, pass its token with -token to manage its short URLs:\n%s\n", tokens[0])
+	}
+}
+
+// apiError - replace gRPC status error with its code and message
+func apiError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if apiStatus, ok := status.FromError(err); ok {
+		return fmt.Errorf("%s: %s", apiStatus.Code(), apiStatus.Message())
+	}
+	return err
+}
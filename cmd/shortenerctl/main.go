@@ -0,0 +1,141 @@
+// Command shortenerctl - administration tool of URL shortener.
+//
+// Usage:
+//
+//	shortenerctl <command> [flags] [arguments]
+//
+// Run shortenerctl help to list commands, shortenerctl <command> -h shows flags of command.
+//
+// backup and restore work with storage directly, other commands call gRPC API of running shortener.
+// backup only reads storage, so it may run next to server, restore into file storage refuses to run while
+// server holds lock of the file.
+// API commands take connection settings from profile of JSON config file, by default
+// $XDG_CONFIG_HOME/shortenerctl/config.json, flags override them:
+//
+//	{
+//	  "default_profile": "prod",
+//	  "profiles": {
+//	    "prod": {
+//	      "address": "shortener.example.com:3200",
+//	      "token_file": "/etc/shortenerctl/token",
+//	      "real_ip": "10.0.0.5",
+//	      "tls": true,
+//	      "ca_cert": "/etc/shortenerctl/ca.pem",
+//	      "output": "json"
+//	    }
+//	  }
+//	}
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// Exit codes of shortenerctl
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// command - subcommand of shortenerctl, run gets arguments after command name
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+// commands - subcommands of shortenerctl by name
+var commands = map[string]command{
+	"backup":     {summary: "write archive of all short URLs of storage", run: runBackup},
+	"restore":    {summary: "load archive into storage and verify counts", run: runRestore},
+	"short":      {summary: "shorten URL", run: apiCommand("short", "URL", 1, 1, setupShort)},
+	"expand":     {summary: "show original URL of short ID", run: apiCommand("expand", "SHORT_ID", 1, 1, setupExpand)},
+	"list":       {summary: "list short URLs of user", run: apiCommand("list", "", 0, 0, setupList)},
+	"delete":     {summary: "delete short URLs of user", run: apiCommand("delete", "SHORT_ID...", 1, -1, setupDelete)},
+	"batch":      {summary: "shorten URLs of JSON or NDJSON file", run: apiCommand("batch", "FILE", 1, 1, setupBatch)},
+	"stats":      {summary: "show amounts of users and short URLs", run: apiCommand("stats", "", 0, 0, setupStats)},
+	"migrations": {summary: "show status of storage schema migrations", run: apiCommand("migrations", "status", 1, 1, setupMigrations)},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run - execute command of arguments and returns exit code, command is stopped by cancellation of ctx
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd, found := commands[args[0]]
+	if !found {
+		fmt.Fprintf(stderr, "shortenerctl: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+	err := cmd.run(ctx, args[1:], stdout, stderr)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "shortenerctl %s: %s\n", args[0], err)
+		return exitError
+	}
+}
+
+// errUsage - error of command when its flags or arguments are wrong, usage is already printed
+var errUsage = errors.New("wrong usage")
+
+// usage - print list of commands
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: shortenerctl <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet - create flag set of command which prints its usage to stderr
+func newFlagSet(name string, arguments string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: shortenerctl %s [flags] %s\n\nFlags:\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags - parse arguments of command, wrong flags are reported as errUsage
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+func runCommand(args ...string) (code int, stdout string, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_usage(t *testing.T) {
+	code, _, stderr := runCommand()
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "backup")
+
+	code, _, stderr = runCommand("unknown")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `unknown command "unknown"`)
+
+	code, _, _ = runCommand("backup", "-storage-backend", "file")
+	assert.Equal(t, exitUsage, code, "archive path is required")
+
+	code, _, stderr = runCommand("backup", "-storage-backend", "memory", filepath.Join(t.TempDir(), "backup.gz"))
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "memory storage backend keeps no data")
+
+	code, _, _ = runCommand("restore", "-h")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	shortURLs := []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", CreatedAt: createdAt, Clicks: 7},
+		{UUID: "sale", OriginalURL: "https://ya.ru/", UserID: "1", IsAlias: true, CreatedAt: createdAt, MaxClicks: 100},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "2", CreatedAt: createdAt, DeletedFlag: true, DeletedAt: createdAt},
+	}
+	filePath := filepath.Join(dir, "store.json")
+	fileStore := storage.NewInFileStorage(filePath)
+	require.NoError(t, fileStore.UpsertURLs(context.Background(), shortURLs))
+	require.NoError(t, fileStore.Close())
+
+	archive := filepath.Join(dir, "backup.gz")
+	code, stdout, stderr := runCommand("backup", "-storage-backend", "file", "-f", filePath, archive)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "3 URLs of 2 users, 1 deleted")
+	assert.Contains(t, stderr, "backup: 3 URLs", "progress reported")
+
+	sqlitePath := filepath.Join(dir, "store.sqlite")
+	code, stdout, stderr = runCommand("restore", "-storage-backend", "sqlite", "-sqlite-path", sqlitePath, archive)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "verified: 3 URLs of 2 users, 1 deleted")
+
+	code, _, stderr = runCommand("restore", "-storage-backend", "sqlite", "-sqlite-path", sqlitePath, archive)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "use -force", "not empty storage is protected")
+	code, stdout, stderr = runCommand("restore", "-storage-backend", "sqlite", "-sqlite-path", sqlitePath, "-force", archive)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "not verified")
+
+	sqliteStore, err := storage.NewSQLiteStorage(context.Background(), sqlitePath)
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+	for _, shortURL := range shortURLs {
+		found, err := sqliteStore.FindByID(context.Background(), shortURL.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, shortURL.OriginalURL, found.OriginalURL)
+		assert.Equal(t, shortURL.DeletedFlag, found.DeletedFlag, "deletion restored")
+		assert.Equal(t, shortURL.Clicks, found.Clicks, "clicks restored")
+	}
+}
+
+func TestRestore_brokenArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "backup.gz")
+	require.NoError(t, os.WriteFile(archive, []byte("not an archive"), 0600))
+
+	sqlitePath := filepath.Join(dir, "store.sqlite")
+	code, _, stderr := runCommand("restore", "-storage-backend", "sqlite", "-sqlite-path", sqlitePath, archive)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "archive is broken")
+	_, err := os.Stat(sqlitePath)
+	assert.True(t, os.IsNotExist(err), "storage is not touched before archive is checked")
+}
+
+func TestBackupAndRestore_fileStorageOfRunningServer(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "store.json")
+	running, err := storage.NewInFileStorageWithOptions(filePath, storage.InFileOptions{SyncPolicy: storage.SyncAlways})
+	require.NoError(t, err)
+	defer running.Close()
+	shortURL := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"}
+	require.NoError(t, running.UpsertURLs(context.Background(), []models.ShortenURL{shortURL}))
+	shortURL.OriginalURL = "https://ya.ru/"
+	require.NoError(t, running.UpsertURLs(context.Background(), []models.ShortenURL{shortURL}))
+	logBefore, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	archive := filepath.Join(dir, "backup.gz")
+	code, stdout, stderr := runCommand("backup", "-storage-backend", "file", "-f", filePath, archive)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "1 URLs of 1 users", "snapshot of log has the latest state of URL")
+	logAfter, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, logBefore, logAfter, "log of running server is not compacted by backup")
+
+	code, _, stderr = runCommand("restore", "-storage-backend", "file", "-f", filePath, "-force", archive)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "storage file is locked by another process")
+
+	require.NoError(t, running.UpsertURLs(context.Background(), []models.ShortenURL{{UUID: "sale", OriginalURL: "https://ya.ru/sale", UserID: "1"}}))
+	found, err := running.FindByID(context.Background(), "sale")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ya.ru/sale", found.OriginalURL, "server keeps writing its storage")
+}
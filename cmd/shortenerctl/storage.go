@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// storageFlags - flags which choose storage backend, named as flags of shortener server
+type storageFlags struct {
+	backend         string
+	databaseDSN     string
+	fileStoragePath string
+	sqlitePath      string
+}
+
+// register - add storage flags to flag set
+func (f *storageFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.backend, "storage-backend", "", "storage backend: file, postgres or sqlite")
+	flags.StringVar(&f.databaseDSN, "d", "", "database DSN address of postgres storage backend")
+	flags.StringVar(&f.fileStoragePath, "f", "/tmp/short-url-db.json", "path to file.json of file storage backend")
+	flags.StringVar(&f.sqlitePath, "sqlite-path", "/tmp/short-url.sqlite", "path to SQLite database file of sqlite storage backend")
+}
+
+// open - open chosen storage backend, returned function closes it. File backend opened for reading is snapshot
+// of its files which is safe to take while server writes them, for writing it is locked and fails while server runs
+func (f *storageFlags) open(ctx context.Context, readOnly bool) (storage.Repository, func() error, error) {
+	switch f.backend {
+	case storage.BackendFile:
+		options := storage.DefaultInFileOptions()
+		options.ReadOnly = readOnly
+		inFileStore, err := storage.NewInFileStorageWithOptions(f.fileStoragePath, options)
+		if errors.Is(err, storage.ErrStorageLocked) {
+			return nil, nil, fmt.Errorf("%w, stop shortener server which uses %s first", err, f.fileStoragePath)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return inFileStore, inFileStore.Close, nil
+	case storage.BackendPostgres:
+		if f.databaseDSN == "" {
+			return nil, nil, errors.New("database DSN is required for postgres storage backend")
+		}
+		dbStore, err := storage.NewDBStorage(ctx, f.databaseDSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &dbStore, dbStore.Close, nil
+	case storage.BackendSQLite:
+		sqliteStore, err := storage.NewSQLiteStorage(ctx, f.sqlitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqliteStore, sqliteStore.Close, nil
+	case storage.BackendMemory:
+		return nil, nil, errors.New("memory storage backend keeps no data between runs")
+	case "":
+		return nil, nil, errors.New("storage backend is required")
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", storage.ErrUnknownBackend, f.backend)
+	}
+}
@@ -4,3 +4,6 @@ import "embed"
 
 //go:embed migrations/*.sql
 var MigrationsFS embed.FS
+
+//go:embed sqlite/*.sql
+var SQLiteMigrationsFS embed.FS
@@ -0,0 +1,2 @@
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_url_user_id_key;
+ALTER TABLE urls ADD CONSTRAINT urls_url_key UNIQUE (url);
\ No newline at end of file
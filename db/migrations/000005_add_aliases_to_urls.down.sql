@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS urls_url_user_id_key;
+ALTER TABLE urls ADD CONSTRAINT urls_url_user_id_key UNIQUE (url, user_id);
+ALTER TABLE urls DROP COLUMN IF EXISTS is_alias;
+ALTER TABLE urls ALTER COLUMN short_url TYPE VARCHAR(8);
\ No newline at end of file
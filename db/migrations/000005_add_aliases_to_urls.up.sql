@@ -0,0 +1,4 @@
+ALTER TABLE urls ALTER COLUMN short_url TYPE VARCHAR(64);
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS is_alias BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_url_user_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urls_url_user_id_key ON urls (url, user_id) WHERE NOT is_alias;
\ No newline at end of file
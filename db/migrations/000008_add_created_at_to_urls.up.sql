@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS urls_user_id_created_at_idx ON urls (user_id, created_at, short_url);
+CREATE INDEX IF NOT EXISTS urls_user_id_url_idx ON urls (user_id, url, short_url);
\ No newline at end of file
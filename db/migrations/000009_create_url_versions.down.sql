@@ -0,0 +1 @@
+DROP TABLE IF EXISTS url_versions;
\ No newline at end of file
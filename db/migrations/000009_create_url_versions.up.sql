@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS url_versions (
+    id BIGSERIAL PRIMARY KEY,
+    short_url VARCHAR(64) NOT NULL,
+    version INT NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (short_url, version)
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS url_counters;
+DROP TABLE IF EXISTS url_versions;
+DROP TABLE IF EXISTS clicks;
+DROP TABLE IF EXISTS urls;
\ No newline at end of file
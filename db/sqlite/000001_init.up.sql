@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS urls (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    short_url VARCHAR(64) NOT NULL UNIQUE,
+    url VARCHAR(2048) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    is_alias BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    max_clicks INTEGER NOT NULL DEFAULT 0,
+    clicks INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS urls_url_user_id_key ON urls (url, user_id) WHERE NOT is_alias;
+CREATE INDEX IF NOT EXISTS urls_user_id_created_at_idx ON urls (user_id, created_at, short_url);
+CREATE INDEX IF NOT EXISTS urls_user_id_url_idx ON urls (user_id, url, short_url);
+CREATE INDEX IF NOT EXISTS urls_deleted_at_idx ON urls (deleted_at) WHERE is_deleted;
+CREATE TABLE IF NOT EXISTS clicks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    short_url VARCHAR(64) NOT NULL,
+    clicked_at TIMESTAMP NOT NULL,
+    referrer VARCHAR(2048) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS clicks_short_url_clicked_at_idx ON clicks (short_url, clicked_at);
+CREATE TABLE IF NOT EXISTS url_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    short_url VARCHAR(64) NOT NULL,
+    version INTEGER NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    replaced_at TIMESTAMP NOT NULL,
+    UNIQUE (short_url, version)
+);
+CREATE TABLE IF NOT EXISTS url_counters (
+    name VARCHAR(64) PRIMARY KEY,
+    value INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO url_counters (name) VALUES ('purged') ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,83 @@
+package async
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// ClickSubscriptionBufferSize - amount of clicks waiting for slow subscriber before new ones are dropped
+const ClickSubscriptionBufferSize = 64
+
+// ClickSubscription - live feed of clicks on shortened URLs of the user
+type ClickSubscription struct {
+	userID    string
+	shortURLs []string
+	clicksCh  chan models.Click
+	dropped   atomic.Int64
+}
+
+// Clicks - returns channel with clicks of subscription
+func (s *ClickSubscription) Clicks() <-chan models.Click {
+	return s.clicksCh
+}
+
+// Dropped - returns amount of clicks dropped because subscriber was too slow
+func (s *ClickSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *ClickSubscription) matches(userID string, shortURL string) bool {
+	return s.userID == userID && (len(s.shortURLs) == 0 || slices.Contains(s.shortURLs, shortURL))
+}
+
+// ClickHub - in-process pub/sub of clicks which never blocks publisher
+type ClickHub struct {
+	mu          sync.RWMutex
+	subscribers map[*ClickSubscription]struct{}
+}
+
+// Subscribe - subscribe to clicks on user's shortened URLs, all of them when shortURLs is empty
+func (h *ClickHub) Subscribe(userID string, shortURLs []string) *ClickSubscription {
+	subscription := &ClickSubscription{
+		userID:    userID,
+		shortURLs: shortURLs,
+		clicksCh:  make(chan models.Click, ClickSubscriptionBufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe - stop delivering clicks to subscription
+func (h *ClickHub) Unsubscribe(subscription *ClickSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, subscription)
+}
+
+// Publish - deliver click on user's shortened URL to matching subscribers, drops it for subscribers with full buffer
+func (h *ClickHub) Publish(userID string, click models.Click) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscription := range h.subscribers {
+		if !subscription.matches(userID, click.ShortURL) {
+			continue
+		}
+		select {
+		case subscription.clicksCh <- click:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+// NewClickHub - create instance of ClickHub
+func NewClickHub() *ClickHub {
+	return &ClickHub{subscribers: make(map[*ClickSubscription]struct{})}
+}
@@ -0,0 +1,39 @@
+package async
+
+import (
+	"testing"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickHub(t *testing.T) {
+	hub := NewClickHub()
+	all := hub.Subscribe("1", nil)
+	filtered := hub.Subscribe("1", []string{"2187b119"})
+	other := hub.Subscribe("2", nil)
+
+	hub.Publish("1", models.Click{ShortURL: "bc2c0be9"})
+	hub.Publish("1", models.Click{ShortURL: "2187b119"})
+
+	assert.Equal(t, "bc2c0be9", (<-all.Clicks()).ShortURL)
+	assert.Equal(t, "2187b119", (<-all.Clicks()).ShortURL)
+	assert.Equal(t, "2187b119", (<-filtered.Clicks()).ShortURL, "only subscribed short URLs delivered")
+	assert.Len(t, other.Clicks(), 0, "clicks of another user are not delivered")
+
+	hub.Unsubscribe(all)
+	hub.Publish("1", models.Click{ShortURL: "bc2c0be9"})
+	assert.Len(t, all.Clicks(), 0, "unsubscribed subscription gets nothing")
+}
+
+func TestClickHub_slow_subscriber(t *testing.T) {
+	hub := NewClickHub()
+	slow := hub.Subscribe("1", nil)
+
+	for i := 0; i < ClickSubscriptionBufferSize+10; i++ {
+		hub.Publish("1", models.Click{ShortURL: "bc2c0be9"})
+	}
+
+	assert.Len(t, slow.Clicks(), ClickSubscriptionBufferSize, "buffer is full")
+	assert.Equal(t, int64(10), slow.Dropped(), "clicks over buffer dropped instead of blocking publisher")
+}
@@ -0,0 +1,104 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Parameters of clicks recording pipeline
+const (
+	// ClicksBufferSize - amount of clicks waiting for write before new ones are dropped
+	ClicksBufferSize = 1024
+	// ClicksBatchSize - amount of clicks written to storage at once
+	ClicksBatchSize = 100
+	// ClicksFlushInterval - max time click waits in not full batch
+	ClicksFlushInterval = time.Second
+)
+
+// ClickRecorder - async buffered batch writer of clicks
+type ClickRecorder struct {
+	repository storage.Repository
+	clicksCh   chan models.Click
+	done       chan struct{}
+	mu         sync.RWMutex // mu - guards closed, so clicks are not sent to closed queue
+	closed     bool
+}
+
+// Record - put click to write queue without waiting, returns false when click was dropped or recorder is closed
+func (r *ClickRecorder) Record(click models.Click) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return false
+	}
+
+	select {
+	case r.clicksCh <- click:
+		return true
+	default:
+		logger.Log().Warn("clicks queue is full, click dropped", zap.String("short_url", click.ShortURL))
+		return false
+	}
+}
+
+// Close - stop accepting clicks and wait until queued ones are written, clicks recorded after it are dropped
+func (r *ClickRecorder) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.clicksCh)
+	}
+	r.mu.Unlock()
+	<-r.done
+}
+
+func (r *ClickRecorder) run(flushInterval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var clicksBuffer []models.Click
+	flush := func() {
+		if len(clicksBuffer) == 0 {
+			return
+		}
+		if err := r.repository.StoreClicks(context.Background(), clicksBuffer); err != nil {
+			logger.Log().Error("can not store clicks", zap.Error(err), zap.Int("amount", len(clicksBuffer)))
+		}
+		clicksBuffer = nil
+	}
+
+	for {
+		select {
+		case click, ok := <-r.clicksCh:
+			if !ok {
+				flush()
+				return
+			}
+			clicksBuffer = append(clicksBuffer, click)
+			if len(clicksBuffer) == ClicksBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// NewClickRecorder - create instance of ClickRecorder and start its writer
+func NewClickRecorder(repository storage.Repository, flushInterval time.Duration) *ClickRecorder {
+	recorder := &ClickRecorder{
+		repository: repository,
+		clicksCh:   make(chan models.Click, ClicksBufferSize),
+		done:       make(chan struct{}),
+	}
+	go recorder.run(flushInterval)
+	return recorder
+}
@@ -0,0 +1,42 @@
+package async
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickRecorder(t *testing.T) {
+	fs := storage.NewInFileStorage("/tmp/.test_clicks_store")
+	defer os.Remove("/tmp/.test_clicks_store")
+	defer os.Remove("/tmp/.test_clicks_store.clicks")
+	defer fs.Close()
+
+	recorder := NewClickRecorder(fs, time.Hour)
+	for i := 0; i < ClicksBatchSize+1; i++ {
+		assert.True(t, recorder.Record(models.NewClick("bc2c0be9", "", "", "10.0.0.1")), "click queued")
+	}
+	recorder.Close()
+
+	stats, err := fs.GetClickStats(context.Background(), "bc2c0be9")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, ClicksBatchSize+1, stats[0].Clicks, "full batch and rest flushed on close")
+	assert.Equal(t, 1, stats[0].Visitors)
+}
+
+func TestClickRecorder_RecordAfterClose(t *testing.T) {
+	recorder := NewClickRecorder(storage.NewMemoryStorage(), time.Hour)
+	recorder.Close()
+
+	assert.NotPanics(t, func() {
+		assert.False(t, recorder.Record(models.NewClick("bc2c0be9", "", "", "10.0.0.1")), "click dropped after close")
+	})
+	assert.NotPanics(t, recorder.Close, "repeated close")
+}
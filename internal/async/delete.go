@@ -2,14 +2,88 @@ package async
 
 import (
 	"context"
+	"errors"
+	"sync"
 
+	"github.com/PaBah/url-shortener.git/internal/metrics"
 	"github.com/PaBah/url-shortener.git/internal/storage"
 )
 
-// Delete - async deletion of URLs
-func Delete(repository storage.Repository, inputCh chan string) {
+// ErrDeletionsStopped - error when deletion is requested after WaitDeletions started shutdown
+var ErrDeletionsStopped = errors.New("deletions are stopped by shutdown")
+
+// deletionTracker - counts running deletions, new ones are rejected once waiting for them has started
+type deletionTracker struct {
+	mu       sync.Mutex
+	running  int
+	stopped  bool
+	finished chan struct{}
+}
+
+func newDeletionTracker() *deletionTracker {
+	return &deletionTracker{finished: make(chan struct{})}
+}
+
+// start - register deletion, fails when tracker is stopped
+func (d *deletionTracker) start() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+	d.running++
+	return true
+}
+
+// done - unregister finished deletion
+func (d *deletionTracker) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running--
+	if d.stopped && d.running == 0 {
+		close(d.finished)
+	}
+}
+
+// wait - stop accepting deletions and wait for running ones
+func (d *deletionTracker) wait(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		if d.running == 0 {
+			close(d.finished)
+		}
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deletions - running deletions, WaitDeletions waits for them on shutdown
+var deletions = newDeletionTracker()
+
+// Delete - async deletion of URLs, it is running until inputCh is closed and the last batch is deleted.
+// Every received item leaves deletion queue after its batch is deleted, empty ones are URLs rejected by ownership check.
+// After WaitDeletions is called it fails with ErrDeletionsStopped, inputCh is drained then, so its producers stop
+func Delete(repository storage.Repository, inputCh chan string) error {
 	deletionBatchSize := 10
+	tracker := deletions
+	if !tracker.start() {
+		go func() {
+			for range inputCh {
+				metrics.DeletionQueueDepth.Dec()
+			}
+		}()
+		return ErrDeletionsStopped
+	}
+
 	go func() {
+		defer tracker.done()
 		ctx := context.Background()
 		var deletionBuffer []string
 		for data := range inputCh {
@@ -17,9 +91,18 @@ func Delete(repository storage.Repository, inputCh chan string) {
 
 			if len(deletionBuffer) == deletionBatchSize {
 				_ = repository.DeleteShortURLs(ctx, deletionBuffer)
+				metrics.DeletionQueueDepth.Sub(float64(len(deletionBuffer)))
 				deletionBuffer = []string{}
 			}
 		}
 		_ = repository.DeleteShortURLs(ctx, deletionBuffer)
+		metrics.DeletionQueueDepth.Sub(float64(len(deletionBuffer)))
 	}()
+	return nil
+}
+
+// WaitDeletions - stop accepting deletions and wait until every started one is finished,
+// fails with error of ctx when it is done earlier
+func WaitDeletions(ctx context.Context) error {
+	return deletions.wait(ctx)
 }
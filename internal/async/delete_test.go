@@ -4,21 +4,106 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/PaBah/url-shortener.git/internal/metrics"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// newTestShortURL - create ShortenURL by configured generator which is not expected to fail
+func newTestShortURL(t testing.TB, originalURL string, userID string) models.ShortenURL {
+	t.Helper()
+	shortURL, err := models.NewShortURL(originalURL, userID)
+	assert.NoError(t, err)
+	return shortURL
+}
+
 func TestDelete(t *testing.T) {
 	fs := storage.NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
 	//"bc2c0be9"
-	_ = fs.Store(context.Background(), models.NewShortURL("test", "test"))
+	shortURL := newTestShortURL(t, "test", "test")
+	_ = fs.Store(context.Background(), &shortURL)
 	inputCh := make(chan string)
-	Delete(&fs, inputCh)
+	Delete(fs, inputCh)
 	inputCh <- "bc2c0be9"
+	close(inputCh)
 	_, err := fs.FindByID(context.Background(), "bc2c0be9")
 	assert.NoError(t, err, "no error URL")
 	_ = os.Remove("/tmp/.test_store")
 }
+
+func TestWaitDeletions(t *testing.T) {
+	t.Cleanup(func() { deletions = newDeletionTracker() })
+	ms := storage.NewMemoryStorage()
+	shortURL := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	require.NoError(t, ms.Store(context.Background(), &shortURL))
+
+	inputCh := make(chan string, 1)
+	require.NoError(t, Delete(ms, inputCh))
+	inputCh <- shortURL.UUID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, WaitDeletions(ctx), context.DeadlineExceeded, "deletion waits for the rest of URLs")
+
+	close(inputCh)
+	require.NoError(t, WaitDeletions(context.Background()))
+	stored, err := ms.FindByID(context.Background(), shortURL.UUID)
+	require.NoError(t, err)
+	assert.True(t, stored.DeletedFlag, "URL is deleted when deletion is finished")
+
+	other := newTestShortURL(t, "https://ya.ru/", "1")
+	require.NoError(t, ms.Store(context.Background(), &other))
+	rejectedCh := make(chan string)
+	assert.ErrorIs(t, Delete(ms, rejectedCh), ErrDeletionsStopped, "no deletions after shutdown started")
+	rejectedCh <- other.UUID
+	close(rejectedCh)
+	stored, err = ms.FindByID(context.Background(), other.UUID)
+	require.NoError(t, err)
+	assert.False(t, stored.DeletedFlag, "rejected deletion only drains its input")
+}
+
+// blockingDeletions - repository which deletes URLs only after release is closed
+type blockingDeletions struct {
+	storage.Repository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingDeletions) DeleteShortURLs(ctx context.Context, shortURLs []string) error {
+	r.started <- struct{}{}
+	<-r.release
+	return r.Repository.DeleteShortURLs(ctx, shortURLs)
+}
+
+func TestDelete_queueDepth(t *testing.T) {
+	t.Cleanup(func() { deletions = newDeletionTracker() })
+	ms := storage.NewMemoryStorage()
+	owned := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	foreign := newTestShortURL(t, "https://ya.ru/", "2")
+	require.NoError(t, ms.Store(context.Background(), &owned))
+	require.NoError(t, ms.Store(context.Background(), &foreign))
+	repository := &blockingDeletions{Repository: ms, started: make(chan struct{}, 1), release: make(chan struct{})}
+
+	queued := testutil.ToFloat64(metrics.DeletionQueueDepth)
+	inputCh := BulkDeletionDataGenerator([]string{owned.UUID, foreign.UUID})
+	require.NoError(t, Delete(repository, DeletionFanIn(DeletionFanOut("1", repository, inputCh)...)))
+
+	<-repository.started
+	assert.Equal(t, queued+2, testutil.ToFloat64(metrics.DeletionQueueDepth), "URLs are queued until they are deleted")
+	close(repository.release)
+	require.NoError(t, WaitDeletions(context.Background()))
+	assert.Equal(t, queued, testutil.ToFloat64(metrics.DeletionQueueDepth), "deleted and rejected URLs leave queue")
+
+	stored, err := ms.FindByID(context.Background(), owned.UUID)
+	require.NoError(t, err)
+	assert.True(t, stored.DeletedFlag)
+	stored, err = ms.FindByID(context.Background(), foreign.UUID)
+	require.NoError(t, err)
+	assert.False(t, stored.DeletedFlag, "URL of another user is kept")
+}
@@ -0,0 +1,40 @@
+package async
+
+import (
+	"context"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"go.uber.org/zap"
+)
+
+// ExpirationSweepInterval - default interval between expired shortened URLs sweeps
+const ExpirationSweepInterval = time.Minute
+
+// ExpirationSweeper - async periodic soft deletion of expired URLs until ctx is done, returned channel closes on stop
+func ExpirationSweeper(ctx context.Context, repository storage.Repository, interval time.Duration) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				deleted, err := repository.DeleteExpired(ctx, now)
+				if err != nil {
+					logger.Log().Error("can not delete expired URLs", zap.Error(err))
+					continue
+				}
+				if deleted > 0 {
+					logger.Log().Info("expired URLs deleted", zap.Int("amount", deleted))
+				}
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,30 @@
+package async
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpirationSweeper(t *testing.T) {
+	fs := storage.NewInFileStorage("/tmp/.test_sweeper_store")
+	defer os.Remove("/tmp/.test_sweeper_store")
+	defer fs.Close()
+	shortURL := newTestShortURL(t, "test", "test")
+	shortURL.ExpiresAt = time.Now().Add(-time.Minute)
+	_ = fs.Store(context.Background(), &shortURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := ExpirationSweeper(ctx, fs, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	found, err := fs.FindByID(context.Background(), shortURL.UUID)
+	assert.NoError(t, err)
+	assert.True(t, found.DeletedFlag, "expired URL deleted by sweeper")
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"testing"
 
-	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/PaBah/url-shortener.git/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,9 +14,10 @@ func TestDeletionFanIn_and_FanOut(t *testing.T) {
 	userID := "test"
 	fs := storage.NewInFileStorage("/tmp/.test_store")
 	//"bc2c0be9"
-	_ = fs.Store(context.Background(), models.NewShortURL("test", userID))
+	shortURL := newTestShortURL(t, "test", userID)
+	_ = fs.Store(context.Background(), &shortURL)
 
-	channels := DeletionFanOut(userID, &fs, inputCh)
+	channels := DeletionFanOut(userID, fs, inputCh)
 	addResultCh := DeletionFanIn(channels...)
 	data := <-addResultCh
 	assert.Equal(t, "", data, "fanIn and fanOut works correctly")
@@ -1,10 +1,15 @@
 package async
 
-import "github.com/PaBah/url-shortener.git/internal/dto"
+import (
+	"github.com/PaBah/url-shortener.git/internal/dto"
+	"github.com/PaBah/url-shortener.git/internal/metrics"
+)
 
-// BulkDeletionDataGenerator - initiate shortened URLs batch deletion process via generation through channels
+// BulkDeletionDataGenerator - initiate shortened URLs batch deletion process via generation through channels,
+// generated URLs are counted in deletion queue until Delete finishes their batch
 func BulkDeletionDataGenerator(input dto.DeleteURLsRequest) chan string {
 	inputCh := make(chan string)
+	metrics.DeletionQueueDepth.Add(float64(len(input)))
 
 	go func() {
 		defer close(inputCh)
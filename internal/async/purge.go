@@ -0,0 +1,40 @@
+package async
+
+import (
+	"context"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"go.uber.org/zap"
+)
+
+// PurgeInterval - default interval between purges of soft deleted shortened URLs
+const PurgeInterval = time.Hour
+
+// DeletedPurger - async periodic removal of URLs soft deleted longer than retention ago until ctx is done, returned channel closes on stop
+func DeletedPurger(ctx context.Context, repository storage.Repository, interval time.Duration, retention time.Duration) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				purged, err := repository.PurgeDeleted(ctx, now.Add(-retention))
+				if err != nil {
+					logger.Log().Error("can not purge deleted URLs", zap.Error(err))
+					continue
+				}
+				if purged > 0 {
+					logger.Log().Info("deleted URLs purged", zap.Int("amount", purged))
+				}
+			}
+		}
+	}()
+	return done
+}
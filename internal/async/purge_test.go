@@ -0,0 +1,31 @@
+package async
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletedPurger(t *testing.T) {
+	fs := storage.NewInFileStorage(filepath.Join(t.TempDir(), "store"))
+	defer fs.Close()
+	shortURL := newTestShortURL(t, "test", "test")
+	_ = fs.Store(context.Background(), &shortURL)
+	_ = fs.DeleteShortURLs(context.Background(), []string{shortURL.UUID})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := DeletedPurger(ctx, fs, 10*time.Millisecond, 0)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	_, err := fs.FindByID(context.Background(), shortURL.UUID)
+	assert.Error(t, err, "deleted URL purged")
+	stats, err := fs.GetStats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Purged)
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	uuid "github.com/satori/go.uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys used for gRPC authorization
+const (
+	// AuthorizationMetadataKey - metadata key with JWT token, "Bearer " prefix is optional
+	AuthorizationMetadataKey = "authorization"
+	// RealIPMetadataKey - metadata key with client IP set by proxy, it is trusted only from trusted proxies
+	RealIPMetadataKey = "x-real-ip"
+)
+
+// GRPCAuthorization - interceptors which put UserID from JWT in "authorization" metadata to context
+type GRPCAuthorization struct {
+	publicMethods  map[string]bool
+	trustedMethods map[string]bool
+	trustedSubnet  *net.IPNet
+	trustedProxies []*net.IPNet
+}
+
+// Unary - unary server interceptor which authorizes caller of method
+func (a *GRPCAuthorization) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream - stream server interceptor which authorizes caller of method
+func (a *GRPCAuthorization) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize - returns context with UserID of caller, the same way as HTTP middlewares do:
+// trusted methods are allowed only from trusted subnet, public methods register new user when token is absent
+func (a *GRPCAuthorization) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if a.trustedMethods[method] {
+		ip := a.callerIP(ctx, md)
+		if a.trustedSubnet == nil || !a.trustedSubnet.Contains(ip) {
+			return ctx, status.Errorf(codes.PermissionDenied, "ip %s is not from trusted subnet", ip.String())
+		}
+		return ctx, nil
+	}
+
+	token := strings.TrimPrefix(firstMetadataValue(md, AuthorizationMetadataKey), "Bearer ")
+	if token == "" && a.publicMethods[method] {
+		userID := uuid.NewV4().String()
+		JWTToken, err := BuildJWTString(userID)
+		if err != nil {
+			return ctx, status.Errorf(codes.Internal, "can not build auth token")
+		}
+		if err = grpc.SetHeader(ctx, metadata.Pairs(AuthorizationMetadataKey, JWTToken)); err != nil {
+			return ctx, status.Errorf(codes.Internal, err.Error())
+		}
+		return context.WithValue(ctx, ContextUserKey, userID), nil
+	}
+
+	userID := GetUserID(token)
+	if userID == "" {
+		return ctx, status.Errorf(codes.Unauthenticated, "unauthorized requests forbidden")
+	}
+	return context.WithValue(ctx, ContextUserKey, userID), nil
+}
+
+// callerIP - returns IP of connection peer, x-real-ip metadata replaces it only when peer is trusted proxy,
+// so clients can not claim trusted IP themselves
+func (a *GRPCAuthorization) callerIP(ctx context.Context, md metadata.MD) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	for _, proxy := range a.trustedProxies {
+		if proxy.Contains(ip) {
+			if realIP := net.ParseIP(firstMetadataValue(md, RealIPMetadataKey)); realIP != nil {
+				return realIP
+			}
+			break
+		}
+	}
+	return ip
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type authorizedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context - returns context with authorized UserID
+func (s *authorizedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// NewGRPCAuthorization - create instance of GRPCAuthorization, public methods can be called without token,
+// trusted methods are allowed only from trustedSubnet and never when it is nil.
+// Caller IP is connection address, x-real-ip metadata is used instead only on connections from trustedProxies
+func NewGRPCAuthorization(publicMethods []string, trustedMethods []string, trustedSubnet *net.IPNet, trustedProxies []*net.IPNet) *GRPCAuthorization {
+	a := &GRPCAuthorization{
+		publicMethods:  make(map[string]bool, len(publicMethods)),
+		trustedMethods: make(map[string]bool, len(trustedMethods)),
+		trustedSubnet:  trustedSubnet,
+		trustedProxies: trustedProxies,
+	}
+	for _, method := range publicMethods {
+		a.publicMethods[method] = true
+	}
+	for _, method := range trustedMethods {
+		a.trustedMethods[method] = true
+	}
+	return a
+}
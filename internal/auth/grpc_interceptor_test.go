@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPCAuthorization(t *testing.T) {
+	a := NewGRPCAuthorization([]string{"/public"}, []string{"/trusted"}, nil, nil)
+	JWTToken, _ := BuildJWTString("1")
+	authorized := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationMetadataKey, JWTToken))
+
+	var userID interface{}
+	err := a.Stream(nil, &testServerStream{ctx: authorized}, &grpc.StreamServerInfo{FullMethod: "/private"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			userID = stream.Context().Value(ContextUserKey)
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", userID, "UserID passed to stream context")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return ctx.Value(ContextUserKey), nil
+	}
+	_, err = a.Unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/private"}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "Token required for private methods")
+
+	_, err = a.Unary(authorized, nil, &grpc.UnaryServerInfo{FullMethod: "/trusted"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "Trusted methods forbidden without trusted subnet")
+}
+
+func TestGRPCAuthorization_trustedSubnet(t *testing.T) {
+	_, trustedSubnet, _ := net.ParseCIDR("10.0.0.0/8")
+	_, proxySubnet, _ := net.ParseCIDR("192.168.1.0/24")
+	a := NewGRPCAuthorization(nil, []string{"/trusted"}, trustedSubnet, []*net.IPNet{proxySubnet})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	call := func(peerIP string, realIP string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(peerIP), Port: 41000}})
+		if realIP != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RealIPMetadataKey, realIP))
+		}
+		_, err := a.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/trusted"}, handler)
+		return err
+	}
+
+	assert.NoError(t, call("10.1.2.3", ""), "connection from trusted subnet")
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("203.0.113.5", "10.1.2.3")), "spoofed x-real-ip of direct client rejected")
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("203.0.113.5", "")), "connection from untrusted subnet")
+	assert.NoError(t, call("192.168.1.10", "10.1.2.3"), "x-real-ip of trusted proxy")
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("192.168.1.10", "203.0.113.5")), "trusted proxy forwards untrusted client")
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("192.168.1.10", "")), "proxy itself is not trusted subnet")
+
+	_, err := a.Unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/trusted"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "unknown peer rejected")
+}
@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/PaBah/url-shortener.git/internal/logger"
@@ -15,39 +14,24 @@ type Claims struct {
 	UserID string
 }
 
-// Parameters for JWT tokens generation/parsing
+// Defaults for JWT tokens generation/parsing when keys are not configured
 const (
-	// TokenExp - JWT token expiration time in microseconds
+	// TokenExp - JWT token expiration time
 	TokenExp = time.Hour * 3
 
-	// SecretKey - key for JWT encryption
+	// SecretKey - insecure HS256 key used until KeySet is configured
 	SecretKey = "supersecretkey"
 )
 
-// BuildJWTString - generate JWT string from UserID
+// BuildJWTString - generate JWT string from UserID signed with primary key of KeySet
 func BuildJWTString(userID string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenExp)),
-		},
-		UserID: userID,
-	})
-
-	tokenString, err := token.SignedString([]byte(SecretKey))
-
-	return tokenString, err
+	return GetKeySet().Sign(Claims{UserID: userID})
 }
 
-// GetUserID - parse JWT string and return UserID
+// GetUserID - parse JWT string with keys of KeySet and return UserID
 func GetUserID(tokenString string) string {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return []byte(SecretKey), nil
-		})
+	token, err := GetKeySet().Verify(tokenString, claims)
 	if err != nil {
 		return ""
 	}
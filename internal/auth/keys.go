@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported JWT signing algorithms
+const (
+	// HS256 - HMAC SHA-256 with shared secret
+	HS256 = "HS256"
+	// RS256 - RSA PKCS#1 v1.5 SHA-256, tokens can be verified with public key only
+	RS256 = "RS256"
+	// EdDSA - Ed25519, tokens can be verified with public key only
+	EdDSA = "EdDSA"
+
+	// DefaultKeyID - kid of key built from single configured secret
+	DefaultKeyID = "default"
+)
+
+// ErrUnknownKey - error when token is signed with key which is not in KeySet
+var ErrUnknownKey = errors.New("unknown JWT signing key")
+
+// Key - JWT signing key identified by kid header
+type Key struct {
+	ID        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign - check if key has private part and can sign new tokens
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey - create HS256 key from shared secret
+func NewHMACKey(ID string, secret string) *Key {
+	return &Key{ID: ID, method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+}
+
+// NewPEMKey - create RS256 or EdDSA key from PEM encoded private or public key, public key can only verify tokens
+func NewPEMKey(ID string, algorithm string, pemData []byte) (*Key, error) {
+	switch algorithm {
+	case RS256:
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(pemData); err == nil {
+			return &Key{ID: ID, method: jwt.SigningMethodRS256, signKey: private, verifyKey: &private.PublicKey}, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", ID, err)
+		}
+		return &Key{ID: ID, method: jwt.SigningMethodRS256, verifyKey: public}, nil
+	case EdDSA:
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(pemData); err == nil {
+			return &Key{ID: ID, method: jwt.SigningMethodEdDSA, signKey: private, verifyKey: private.(ed25519.PrivateKey).Public()}, nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", ID, err)
+		}
+		return &Key{ID: ID, method: jwt.SigningMethodEdDSA, verifyKey: public}, nil
+	}
+	return nil, fmt.Errorf("key %q: unsupported algorithm %q", ID, algorithm)
+}
+
+// KeySet - active JWT keys, new tokens are signed with primary key while all keys verify tokens until they expire
+type KeySet struct {
+	primary  *Key
+	keys     map[string]*Key
+	tokenExp time.Duration
+}
+
+// Sign - build JWT string signed with primary key and its kid header
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(ks.tokenExp))
+	}
+	token := jwt.NewWithClaims(ks.primary.method, claims)
+	token.Header["kid"] = ks.primary.ID
+	return token.SignedString(ks.primary.signKey)
+}
+
+// Verify - parse JWT string into claims with key from kid header, tokens without kid are checked with primary key
+func (ks *KeySet) Verify(tokenString string, claims *Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			key := ks.primary
+			if kid, ok := t.Header["kid"].(string); ok {
+				key, ok = ks.keys[kid]
+				if !ok {
+					return nil, ErrUnknownKey
+				}
+			}
+			if t.Method.Alg() != key.method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+			return key.verifyKey, nil
+		})
+}
+
+// NewKeySet - create KeySet which signs tokens with key primaryID and verifies with all of keys
+func NewKeySet(primaryID string, keys []*Key, tokenExp time.Duration) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key, len(keys)), tokenExp: tokenExp}
+	for _, key := range keys {
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated key %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	primary, ok := ks.keys[primaryID]
+	if !ok {
+		return nil, fmt.Errorf("primary key %q is not defined", primaryID)
+	}
+	if !primary.CanSign() {
+		return nil, fmt.Errorf("primary key %q has no private key", primaryID)
+	}
+	ks.primary = primary
+	return ks, nil
+}
+
+// keysFile - format of JSON file with JWT keys, key files paths are relative to it
+type keysFile struct {
+	Primary string `json:"primary"`
+	Keys    []struct {
+		ID        string `json:"kid"`
+		Algorithm string `json:"alg"`
+		Secret    string `json:"secret"`
+		KeyFile   string `json:"key_file"`
+	} `json:"keys"`
+}
+
+// LoadKeySet - create KeySet from JSON keys file
+func LoadKeySet(path string, tokenExp time.Duration) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config keysFile
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("can not parse keys file: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(config.Keys))
+	for _, keyConfig := range config.Keys {
+		if keyConfig.Algorithm == HS256 {
+			if keyConfig.Secret == "" {
+				return nil, fmt.Errorf("key %q: empty secret", keyConfig.ID)
+			}
+			keys = append(keys, NewHMACKey(keyConfig.ID, keyConfig.Secret))
+			continue
+		}
+
+		keyPath := keyConfig.KeyFile
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+		pemData, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", keyConfig.ID, err)
+		}
+		key, err := NewPEMKey(keyConfig.ID, keyConfig.Algorithm, pemData)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(config.Primary, keys, tokenExp)
+}
+
+var keySet atomic.Pointer[KeySet]
+
+// SetKeySet - replace KeySet used to sign and verify tokens
+func SetKeySet(ks *KeySet) {
+	keySet.Store(ks)
+}
+
+// GetKeySet - returns KeySet used to sign and verify tokens, HS256 with SecretKey until configured
+func GetKeySet() *KeySet {
+	if ks := keySet.Load(); ks != nil {
+		return ks
+	}
+	ks, _ := NewKeySet(DefaultKeyID, []*Key{NewHMACKey(DefaultKeyID, SecretKey)}, TokenExp)
+	keySet.CompareAndSwap(nil, ks)
+	return keySet.Load()
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
+
+func TestKeySet_rotation(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "ed.pem"), "PRIVATE KEY", edDER)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "rsa.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate))
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "rsa.pub.pem"), "PUBLIC KEY", rsaPublicDER)
+
+	writeKeys := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	oldSet, err := LoadKeySet(writeKeys("old.json", `{"primary": "2024-01", "keys": [
+		{"kid": "2024-01", "alg": "RS256", "key_file": "rsa.pem"},
+		{"kid": "legacy", "alg": "HS256", "secret": "supersecretkey"}
+	]}`), time.Hour)
+	require.NoError(t, err)
+	newSet, err := LoadKeySet(writeKeys("new.json", `{"primary": "2024-06", "keys": [
+		{"kid": "2024-06", "alg": "EdDSA", "key_file": "ed.pem"},
+		{"kid": "2024-01", "alg": "RS256", "key_file": "rsa.pub.pem"}
+	]}`), time.Hour)
+	require.NoError(t, err)
+
+	oldToken, err := oldSet.Sign(Claims{UserID: "1"})
+	require.NoError(t, err)
+	newToken, err := newSet.Sign(Claims{UserID: "2"})
+	require.NoError(t, err)
+
+	claims := &Claims{}
+	token, err := newSet.Verify(oldToken, claims)
+	require.NoError(t, err, "token of retired key still verified")
+	assert.Equal(t, "1", claims.UserID)
+	assert.Equal(t, "2024-01", token.Header["kid"])
+
+	claims = &Claims{}
+	token, err = newSet.Verify(newToken, claims)
+	require.NoError(t, err)
+	assert.Equal(t, "2", claims.UserID)
+	assert.Equal(t, "2024-06", token.Header["kid"], "new tokens signed with primary key")
+	assert.Equal(t, EdDSA, token.Method.Alg())
+
+	_, err = oldSet.Verify(newToken, &Claims{})
+	assert.ErrorIs(t, err, ErrUnknownKey, "token of unknown kid rejected")
+
+	expiredToken, err := oldSet.Sign(Claims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}, UserID: "1"})
+	require.NoError(t, err)
+	_, err = newSet.Verify(expiredToken, &Claims{})
+	assert.Error(t, err, "expired token rejected")
+
+	forgedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: "1"})
+	forgedToken.Header["kid"] = "2024-01"
+	forged, err := forgedToken.SignedString(rsaPublicDER)
+	require.NoError(t, err)
+	_, err = newSet.Verify(forged, &Claims{})
+	assert.Error(t, err, "algorithm of key can not be substituted")
+
+	_, err = LoadKeySet(writeKeys("public.json", `{"primary": "2024-01", "keys": [
+		{"kid": "2024-01", "alg": "RS256", "key_file": "rsa.pub.pem"}
+	]}`), time.Hour)
+	assert.Error(t, err, "public key can not sign new tokens")
+}
+
+func TestGetKeySet(t *testing.T) {
+	defer keySet.Store(nil)
+
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: "1"})
+	legacyToken, err := legacy.SignedString([]byte(SecretKey))
+	require.NoError(t, err)
+	assert.Equal(t, "1", GetUserID(legacyToken), "tokens without kid verified with default key")
+
+	ks, err := NewKeySet("custom", []*Key{NewHMACKey("custom", "anothersecret")}, time.Hour)
+	require.NoError(t, err)
+	SetKeySet(ks)
+
+	token, err := BuildJWTString("2")
+	require.NoError(t, err)
+	assert.Equal(t, "2", GetUserID(token))
+	assert.Equal(t, "", GetUserID(legacyToken), "tokens of replaced key rejected")
+}
@@ -0,0 +1,244 @@
+// Package backup - versioned archive of all shortened URLs of storage.Repository, it moves data between storage backends
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// FormatName - name of archive format written to archive header
+const FormatName = "url-shortener-backup"
+
+// Version - version of archive format written by Writer, Reader accepts archives up to this version
+const Version = 1
+
+// Errors of archive reading
+var (
+	// ErrNotArchive - error when data is not backup archive
+	ErrNotArchive = errors.New("not a backup archive")
+	// ErrUnsupportedVersion - error when archive is written by newer format version
+	ErrUnsupportedVersion = errors.New("unsupported backup archive version")
+	// ErrTruncated - error when archive ends before its counts entry
+	ErrTruncated = errors.New("backup archive is truncated")
+	// ErrCountsMismatch - error when amount of archived data differs from expected counts
+	ErrCountsMismatch = errors.New("backup counts mismatch")
+)
+
+// Header - first entry of archive
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Backend   string    `json:"backend,omitempty"`
+}
+
+// URL - shortened URL entry of archive, it keeps owner, clicks and deletion of URL
+type URL struct {
+	ShortID     string     `json:"short_id"`
+	OriginalURL string     `json:"original_url"`
+	UserID      string     `json:"user_id"`
+	IsAlias     bool       `json:"is_alias,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	MaxClicks   int        `json:"max_clicks,omitempty"`
+	Clicks      int        `json:"clicks,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	IsDeleted   bool       `json:"is_deleted,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Counts - amounts of archived shortened URLs, their distinct users and deleted ones, written as last entry of archive
+type Counts struct {
+	URLs    int `json:"urls"`
+	Users   int `json:"users"`
+	Deleted int `json:"deleted"`
+}
+
+// String - human readable counts
+func (c Counts) String() string {
+	return fmt.Sprintf("%d URLs of %d users, %d deleted", c.URLs, c.Users, c.Deleted)
+}
+
+// entry - line of archive, exactly one field is set. Archive is gzipped JSON lines of header, URLs and counts
+type entry struct {
+	Header *Header `json:"header,omitempty"`
+	URL    *URL    `json:"url,omitempty"`
+	Counts *Counts `json:"counts,omitempty"`
+}
+
+// counter - counts shortened URLs passed through archive
+type counter struct {
+	counts Counts
+	users  map[string]struct{}
+}
+
+func (c *counter) add(shortURL models.ShortenURL) {
+	if c.users == nil {
+		c.users = make(map[string]struct{})
+	}
+	c.counts.URLs++
+	if shortURL.DeletedFlag {
+		c.counts.Deleted++
+	}
+	if _, found := c.users[shortURL.UserID]; !found {
+		c.users[shortURL.UserID] = struct{}{}
+		c.counts.Users++
+	}
+}
+
+// newURL - build archive entry of shortened URL
+func newURL(shortURL models.ShortenURL) *URL {
+	optionalTime := func(moment time.Time) *time.Time {
+		if moment.IsZero() {
+			return nil
+		}
+		moment = moment.UTC()
+		return &moment
+	}
+	return &URL{
+		ShortID:     shortURL.UUID,
+		OriginalURL: shortURL.OriginalURL,
+		UserID:      shortURL.UserID,
+		IsAlias:     shortURL.IsAlias,
+		ExpiresAt:   optionalTime(shortURL.ExpiresAt),
+		MaxClicks:   shortURL.MaxClicks,
+		Clicks:      shortURL.Clicks,
+		CreatedAt:   shortURL.CreatedAt.UTC(),
+		IsDeleted:   shortURL.DeletedFlag,
+		DeletedAt:   optionalTime(shortURL.DeletedAt),
+	}
+}
+
+// shortenURL - returns shortened URL of archive entry
+func (u *URL) shortenURL() models.ShortenURL {
+	shortURL := models.ShortenURL{
+		UUID:        u.ShortID,
+		OriginalURL: u.OriginalURL,
+		UserID:      u.UserID,
+		IsAlias:     u.IsAlias,
+		MaxClicks:   u.MaxClicks,
+		Clicks:      u.Clicks,
+		CreatedAt:   u.CreatedAt,
+		DeletedFlag: u.IsDeleted,
+	}
+	if u.ExpiresAt != nil {
+		shortURL.ExpiresAt = *u.ExpiresAt
+	}
+	if u.DeletedAt != nil {
+		shortURL.DeletedAt = *u.DeletedAt
+	}
+	return shortURL
+}
+
+// Writer - writes archive of shortened URLs, archive is complete only after Close
+type Writer struct {
+	zip     *gzip.Writer
+	encoder *json.Encoder
+	counter counter
+}
+
+// NewWriter - create Writer and write archive header with name of source storage backend
+func NewWriter(w io.Writer, backend string) (*Writer, error) {
+	zip := gzip.NewWriter(w)
+	writer := &Writer{zip: zip, encoder: json.NewEncoder(zip)}
+	header := Header{Format: FormatName, Version: Version, CreatedAt: time.Now().UTC(), Backend: backend}
+	if err := writer.encoder.Encode(entry{Header: &header}); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write - append shortened URL to archive
+func (w *Writer) Write(shortURL models.ShortenURL) error {
+	if err := w.encoder.Encode(entry{URL: newURL(shortURL)}); err != nil {
+		return err
+	}
+	w.counter.add(shortURL)
+	return nil
+}
+
+// Counts - counts of shortened URLs written so far
+func (w *Writer) Counts() Counts {
+	return w.counter.counts
+}
+
+// Close - write counts entry and flush archive, underlying io.Writer is not closed
+func (w *Writer) Close() error {
+	counts := w.counter.counts
+	if err := w.encoder.Encode(entry{Counts: &counts}); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+// Reader - reads archive of shortened URLs and checks them against counts entry at the end
+type Reader struct {
+	zip     *gzip.Reader
+	decoder *json.Decoder
+	header  Header
+	counter counter
+	done    bool
+}
+
+// NewReader - create Reader and read archive header, fails with ErrNotArchive or ErrUnsupportedVersion
+func NewReader(r io.Reader) (*Reader, error) {
+	zip, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotArchive, err)
+	}
+	reader := &Reader{zip: zip, decoder: json.NewDecoder(zip)}
+	var first entry
+	if err = reader.decoder.Decode(&first); err != nil || first.Header == nil || first.Header.Format != FormatName {
+		return nil, ErrNotArchive
+	}
+	if first.Header.Version < 1 || first.Header.Version > Version {
+		return nil, fmt.Errorf("%w: %d, at most %d is supported", ErrUnsupportedVersion, first.Header.Version, Version)
+	}
+	reader.header = *first.Header
+	return reader, nil
+}
+
+// Header - returns archive header
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next - returns next shortened URL of archive, err is io.EOF after counts entry matched amount of read URLs.
+// Archive cut off before counts entry fails with ErrTruncated
+func (r *Reader) Next() (shortURL models.ShortenURL, err error) {
+	if r.done {
+		return shortURL, io.EOF
+	}
+	var next entry
+	err = r.decoder.Decode(&next)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return shortURL, ErrTruncated
+	}
+	if err != nil {
+		return shortURL, fmt.Errorf("backup archive can not be read: %w", err)
+	}
+	switch {
+	case next.URL != nil:
+		shortURL = next.URL.shortenURL()
+		r.counter.add(shortURL)
+		return shortURL, nil
+	case next.Counts != nil:
+		r.done = true
+		if *next.Counts != r.counter.counts {
+			return shortURL, fmt.Errorf("%w: archive has %s, but %s are read", ErrCountsMismatch, *next.Counts, r.counter.counts)
+		}
+		return shortURL, io.EOF
+	default:
+		return shortURL, fmt.Errorf("%w: unknown entry", ErrNotArchive)
+	}
+}
+
+// Counts - counts of shortened URLs read so far
+func (r *Reader) Counts() Counts {
+	return r.counter.counts
+}
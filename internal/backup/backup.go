@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// ProgressStep - amount of shortened URLs between Progress calls
+const ProgressStep = 1000
+
+// Progress - called with amount of shortened URLs processed so far every ProgressStep URLs and once at the end
+type Progress func(processed int)
+
+// Create - write archive of every shortened URL of repository to w, URLs are read from one consistent snapshot of repository.
+// Returns counts of written archive
+func Create(ctx context.Context, repository storage.Repository, w io.Writer, backend string, progress Progress) (Counts, error) {
+	writer, err := NewWriter(w, backend)
+	if err != nil {
+		return Counts{}, err
+	}
+	err = repository.ScanURLs(ctx, func(shortURL models.ShortenURL) error {
+		if err := writer.Write(shortURL); err != nil {
+			return err
+		}
+		if processed := writer.Counts().URLs; progress != nil && processed%ProgressStep == 0 {
+			progress(processed)
+		}
+		return nil
+	})
+	if err != nil {
+		return writer.Counts(), err
+	}
+	if progress != nil {
+		progress(writer.Counts().URLs)
+	}
+	return writer.Counts(), writer.Close()
+}
+
+// Check - read whole archive without storing it, so broken archive is found before restoration starts
+func Check(r io.Reader) (Header, Counts, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Header{}, Counts{}, err
+	}
+	for {
+		if _, err = reader.Next(); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		return reader.Header(), reader.Counts(), err
+	}
+	return reader.Header(), reader.Counts(), nil
+}
+
+// Restore - store every shortened URL of archive into repository by chunks of storage.MaxBatchInsertRows,
+// stored URLs with the same short IDs are replaced. Returns counts of archive, chunks stored before error are kept
+func Restore(ctx context.Context, repository storage.Repository, r io.Reader, progress Progress) (Counts, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Counts{}, err
+	}
+	restored := 0
+	chunk := make([]models.ShortenURL, 0, storage.MaxBatchInsertRows)
+	store := func() error {
+		if err := repository.UpsertURLs(ctx, chunk); err != nil {
+			return err
+		}
+		restored += len(chunk)
+		chunk = chunk[:0]
+		if progress != nil {
+			progress(restored)
+		}
+		return nil
+	}
+	for {
+		shortURL, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return reader.Counts(), err
+		}
+		chunk = append(chunk, shortURL)
+		if len(chunk) == storage.MaxBatchInsertRows {
+			if err = store(); err != nil {
+				return reader.Counts(), err
+			}
+		}
+	}
+	if err = store(); err != nil {
+		return reader.Counts(), err
+	}
+	return reader.Counts(), nil
+}
+
+// Verify - check that repository keeps exactly counted shortened URLs, users and deleted URLs
+func Verify(ctx context.Context, repository storage.Repository, counts Counts) error {
+	stats, err := repository.GetStats(ctx)
+	if err != nil {
+		return err
+	}
+	stored := Counts{URLs: stats.URLs, Users: stats.Users, Deleted: stats.Deleted}
+	if stored != counts {
+		return fmt.Errorf("%w: archive has %s, but storage has %s", ErrCountsMismatch, counts, stored)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/PaBah/url-shortener.git/internal/mock"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+func testURLs() []models.ShortenURL {
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", CreatedAt: createdAt, Clicks: 7, MaxClicks: 10},
+		{UUID: "sale", OriginalURL: "https://ya.ru/", UserID: "1", IsAlias: true, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "2", CreatedAt: createdAt, DeletedFlag: true, DeletedAt: createdAt.Add(time.Minute)},
+	}
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	source := storage.NewMemoryStorage()
+	require.NoError(t, source.UpsertURLs(context.Background(), testURLs()))
+
+	var archive bytes.Buffer
+	var progress []int
+	counts, err := Create(context.Background(), source, &archive, storage.BackendMemory, func(processed int) { progress = append(progress, processed) })
+	require.NoError(t, err)
+	assert.Equal(t, Counts{URLs: 3, Users: 2, Deleted: 1}, counts)
+	assert.Equal(t, []int{3}, progress, "progress reported at the end")
+
+	header, checked, err := Check(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, counts, checked)
+	assert.Equal(t, Version, header.Version)
+	assert.Equal(t, storage.BackendMemory, header.Backend)
+
+	target := storage.NewMemoryStorage()
+	restored, err := Restore(context.Background(), target, bytes.NewReader(archive.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, counts, restored)
+	require.NoError(t, Verify(context.Background(), target, restored))
+
+	for _, shortURL := range testURLs() {
+		found, err := target.FindByID(context.Background(), shortURL.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, shortURL, found, "URL restored as it was")
+	}
+
+	require.NoError(t, target.DeleteShortURLs(context.Background(), []string{"2187b119"}))
+	assert.ErrorIs(t, Verify(context.Background(), target, restored), ErrCountsMismatch)
+}
+
+func TestRestore_chunks(t *testing.T) {
+	var archive bytes.Buffer
+	writer, err := NewWriter(&archive, storage.BackendFile)
+	require.NoError(t, err)
+	for i := 0; i < storage.MaxBatchInsertRows+1; i++ {
+		require.NoError(t, writer.Write(models.ShortenURL{UUID: fmt.Sprintf("%08d", i), UserID: "1"}))
+	}
+	require.NoError(t, writer.Close())
+
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	gomock.InOrder(
+		rm.EXPECT().UpsertURLs(gomock.Any(), gomock.Len(storage.MaxBatchInsertRows)).Return(nil),
+		rm.EXPECT().UpsertURLs(gomock.Any(), gomock.Len(1)).Return(errors.New("connection refused")),
+	)
+	var progress []int
+	counts, err := Restore(context.Background(), rm, &archive, func(processed int) { progress = append(progress, processed) })
+	assert.Error(t, err)
+	assert.Equal(t, storage.MaxBatchInsertRows+1, counts.URLs)
+	assert.Equal(t, []int{storage.MaxBatchInsertRows}, progress, "only stored chunks reported")
+}
+
+func TestReader_broken(t *testing.T) {
+	var archive bytes.Buffer
+	writer, err := NewWriter(&archive, storage.BackendSQLite)
+	require.NoError(t, err)
+	for _, shortURL := range testURLs() {
+		require.NoError(t, writer.Write(shortURL))
+	}
+	require.NoError(t, writer.Close())
+
+	rewrite := func(entries ...entry) []byte {
+		var data bytes.Buffer
+		zip := gzip.NewWriter(&data)
+		for _, e := range entries {
+			require.NoError(t, json.NewEncoder(zip).Encode(e))
+		}
+		require.NoError(t, zip.Close())
+		return data.Bytes()
+	}
+	header := Header{Format: FormatName, Version: Version}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "not gzip", data: []byte(`{"header": {}}`), wantErr: ErrNotArchive},
+		{name: "foreign format", data: rewrite(entry{Header: &Header{Format: "other", Version: 1}}), wantErr: ErrNotArchive},
+		{name: "newer version", data: rewrite(entry{Header: &Header{Format: FormatName, Version: Version + 1}}), wantErr: ErrUnsupportedVersion},
+		{name: "cut off", data: archive.Bytes()[:archive.Len()/2], wantErr: ErrTruncated},
+		{name: "without counts", data: rewrite(entry{Header: &header}, entry{URL: newURL(testURLs()[0])}), wantErr: ErrTruncated},
+		{name: "wrong counts", data: rewrite(entry{Header: &header}, entry{URL: newURL(testURLs()[0])}, entry{Counts: &Counts{URLs: 2, Users: 1}}), wantErr: ErrCountsMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Check(bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	reader, err := NewReader(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	for range testURLs() {
+		_, err = reader.Next()
+		require.NoError(t, err)
+	}
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF, "end of archive is kept")
+}
@@ -2,12 +2,26 @@ package config
 
 // Options - shortener server configurations
 type Options struct {
-	ServerAddress   string `json:"server_address"` // ServerAddress - address which system use to run shortener server
-	BaseURL         string `json:"base_url"`       // BaseURL - host for shortened URLs
-	LogsLevel       string // LogsLevel - level of logger
-	FileStoragePath string `json:"file_storage_path"` // FileStoragePath - path to file where InFileStorage
-	EnableHTTPS     bool   `json:"enable_https"`      // EnableHTTPS - flag to enable HTTPS server mode
-	DatabaseDSN     string `json:"database_dsn"`      // DatabaseDSN - DSN path for DB connection
-	TrustedSubnet   string `json:"trusted_subnet"`    // TrustedSubnet - CIDR address of allowed subnet
-	GRPCAddress     string `json:"grpc_address"`      // GRPCAddress - address which system use to run gRPC server
+	ServerAddress       string `json:"server_address"` // ServerAddress - address which system use to run shortener server
+	BaseURL             string `json:"base_url"`       // BaseURL - host for shortened URLs
+	LogsLevel           string // LogsLevel - level of logger
+	FileStoragePath     string `json:"file_storage_path"`     // FileStoragePath - path to file where InFileStorage
+	EnableHTTPS         bool   `json:"enable_https"`          // EnableHTTPS - flag to enable HTTPS server mode
+	DatabaseDSN         string `json:"database_dsn"`          // DatabaseDSN - DSN path for DB connection
+	TrustedSubnet       string `json:"trusted_subnet"`        // TrustedSubnet - CIDR address of allowed subnet
+	GRPCAddress         string `json:"grpc_address"`          // GRPCAddress - address which system use to run gRPC server
+	IDStrategy          string `json:"id_strategy"`           // IDStrategy - strategy of short ID generation (hash | random | sequence)
+	IDLength            int    `json:"id_length"`             // IDLength - length of randomly generated short IDs
+	JWTSecret           string `json:"jwt_secret"`            // JWTSecret - HS256 secret for JWT tokens when JWTKeysFile is not set
+	JWTKeysFile         string `json:"jwt_keys_file"`         // JWTKeysFile - path to JSON file with rotated JWT keys
+	JWTTokenExp         string `json:"jwt_token_exp"`         // JWTTokenExp - lifetime of issued JWT tokens, e.g. 3h
+	DeletedRetention    string `json:"deleted_retention"`     // DeletedRetention - period after which soft deleted URLs are purged, 0 disables purge
+	FileSyncPolicy      string `json:"file_sync_policy"`      // FileSyncPolicy - fsync policy of InFileStorage log (always | interval | never)
+	FileCompactInterval string `json:"file_compact_interval"` // FileCompactInterval - min period between InFileStorage log compactions, 0 compacts only on shutdown
+	StorageBackend      string `json:"storage_backend"`       // StorageBackend - storage backend (memory | file | postgres | sqlite), empty is postgres when DatabaseDSN is set and file otherwise
+	SQLitePath          string `json:"sqlite_path"`           // SQLitePath - path to SQLite Data Base file of SQLiteStorage
+	CacheSize           int    `json:"cache_size"`            // CacheSize - max amount of short IDs in redirect lookups cache, 0 disables cache
+	CacheTTL            string `json:"cache_ttl"`             // CacheTTL - lifetime of redirect lookups cache entries, e.g. 1m
+	ShutdownTimeout     string `json:"shutdown_timeout"`      // ShutdownTimeout - max period of graceful shutdown, e.g. 30s
+	TrustedProxies      string `json:"trusted_proxies"`       // TrustedProxies - comma separated CIDR addresses of proxies whose x-real-ip gRPC metadata is trusted
 }
@@ -1,10 +1,25 @@
 package dto
 
+import "time"
+
+// Statuses of shortened URL in /api/shorten/batch response
+const (
+	// BatchStatusCreated - URL was shortened
+	BatchStatusCreated = "created"
+	// BatchStatusExisting - URL was already shortened by user, stored short URL is returned
+	BatchStatusExisting = "existing"
+	// BatchStatusInvalid - URL was not shortened, reason is in error
+	BatchStatusInvalid = "invalid"
+)
+
 // Data Transfer Objects for Server handlers
 type (
 	// ShortenRequest - request params for /api/shorten handler
 	ShortenRequest struct {
-		URL string `json:"url"`
+		URL       string    `json:"url"`
+		Alias     string    `json:"alias,omitempty"`
+		ExpiresAt time.Time `json:"expires_at,omitempty"`
+		MaxClicks int       `json:"max_clicks,omitempty"`
 	}
 
 	// ShortenResponse - response params for /api/shorten handler
@@ -14,14 +29,28 @@ type (
 
 	// BatchShortenRequest - request params for /api/shorten/batch handler
 	BatchShortenRequest struct {
-		CorrelationID string `json:"correlation_id"`
-		URL           string `json:"original_url"`
+		CorrelationID string    `json:"correlation_id"`
+		URL           string    `json:"original_url"`
+		Alias         string    `json:"alias,omitempty"`
+		ExpiresAt     time.Time `json:"expires_at,omitempty"`
+		MaxClicks     int       `json:"max_clicks,omitempty"`
 	}
 
 	// BatchShortenResponse - response params for /api/shorten/batch handlers
 	BatchShortenResponse struct {
 		CorrelationID string `json:"correlation_id"`
-		ShortURL      string `json:"short_url"`
+		ShortURL      string `json:"short_url,omitempty"`
+		Status        string `json:"status"`
+		Error         string `json:"error,omitempty"`
+	}
+
+	// ImportURLResponse - result line of /api/shorten/import handler
+	ImportURLResponse struct {
+		Line          int    `json:"line"`
+		CorrelationID string `json:"correlation_id"`
+		ShortURL      string `json:"short_url,omitempty"`
+		Status        string `json:"status"`
+		Error         string `json:"error,omitempty"`
 	}
 
 	// UsersURLsResponse - response params for /api/user/urls handlers
@@ -30,12 +59,54 @@ type (
 		OriginalURL string `json:"original_url"`
 	}
 
+	// ExportURLResponse - short URL of user in /api/user/urls/export handler, empty moments are omitted
+	ExportURLResponse struct {
+		ShortID     string     `json:"short_id"`
+		ShortURL    string     `json:"short_url"`
+		OriginalURL string     `json:"original_url"`
+		IsAlias     bool       `json:"is_alias"`
+		CreatedAt   *time.Time `json:"created_at,omitempty"`
+		ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+		MaxClicks   int        `json:"max_clicks"`
+		Clicks      int        `json:"clicks"`
+		IsDeleted   bool       `json:"is_deleted"`
+		DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	}
+
+	// UpdateURLRequest - request params for /api/user/urls/{id} PATCH handler
+	UpdateURLRequest struct {
+		URL string `json:"url"`
+	}
+
+	// URLVersionResponse - response params for /api/user/urls/{id}/history handler
+	URLVersionResponse struct {
+		Version     int       `json:"version"`
+		OriginalURL string    `json:"original_url"`
+		ReplacedAt  time.Time `json:"replaced_at"`
+	}
+
+	// ClickStatsResponse - response params for /api/user/urls/{id}/stats handler
+	ClickStatsResponse struct {
+		Date     string `json:"date"`
+		Clicks   int    `json:"clicks"`
+		Visitors int    `json:"visitors"`
+	}
+
 	// StatsResponse - response params for /api/internal/stats handlers
 	StatsResponse struct {
-		Users int `json:"users"`
-		Urls  int `json:"urls"`
+		Users   int `json:"users"`
+		Urls    int `json:"urls"`
+		Active  int `json:"active"`
+		Deleted int `json:"deleted"`
+		Purged  int `json:"purged"`
 	}
 
 	// DeleteURLsRequest - request params for shortened URLs deletion handlers
 	DeleteURLsRequest []string
+
+	// RestoreURLsRequest - request params for /api/user/urls/restore handler
+	RestoreURLsRequest []string
+
+	// RestoreURLsResponse - response params for /api/user/urls/restore handler, short IDs which were restored
+	RestoreURLsResponse []string
 )
@@ -20,12 +20,19 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ShortenerService_Short_FullMethodName         = "/proto.shortener.v1.ShortenerService/Short"
-	ShortenerService_Expand_FullMethodName        = "/proto.shortener.v1.ShortenerService/Expand"
-	ShortenerService_Delete_FullMethodName        = "/proto.shortener.v1.ShortenerService/Delete"
-	ShortenerService_GetUserBucket_FullMethodName = "/proto.shortener.v1.ShortenerService/GetUserBucket"
-	ShortenerService_ShortBatch_FullMethodName    = "/proto.shortener.v1.ShortenerService/ShortBatch"
-	ShortenerService_Stats_FullMethodName         = "/proto.shortener.v1.ShortenerService/Stats"
+	ShortenerService_Short_FullMethodName           = "/proto.shortener.v1.ShortenerService/Short"
+	ShortenerService_Expand_FullMethodName          = "/proto.shortener.v1.ShortenerService/Expand"
+	ShortenerService_Delete_FullMethodName          = "/proto.shortener.v1.ShortenerService/Delete"
+	ShortenerService_Restore_FullMethodName         = "/proto.shortener.v1.ShortenerService/Restore"
+	ShortenerService_GetUserBucket_FullMethodName   = "/proto.shortener.v1.ShortenerService/GetUserBucket"
+	ShortenerService_ShortBatch_FullMethodName      = "/proto.shortener.v1.ShortenerService/ShortBatch"
+	ShortenerService_ImportURLs_FullMethodName      = "/proto.shortener.v1.ShortenerService/ImportURLs"
+	ShortenerService_ExportURLs_FullMethodName      = "/proto.shortener.v1.ShortenerService/ExportURLs"
+	ShortenerService_Stats_FullMethodName           = "/proto.shortener.v1.ShortenerService/Stats"
+	ShortenerService_MigrationStatus_FullMethodName = "/proto.shortener.v1.ShortenerService/MigrationStatus"
+	ShortenerService_StreamClicks_FullMethodName    = "/proto.shortener.v1.ShortenerService/StreamClicks"
+	ShortenerService_UpdateURL_FullMethodName       = "/proto.shortener.v1.ShortenerService/UpdateURL"
+	ShortenerService_GetURLHistory_FullMethodName   = "/proto.shortener.v1.ShortenerService/GetURLHistory"
 )
 
 // ShortenerServiceClient is the client API for ShortenerService service.
@@ -35,9 +42,20 @@ type ShortenerServiceClient interface {
 	Short(ctx context.Context, in *ShortRequest, opts ...grpc.CallOption) (*ShortResponse, error)
 	Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (*ExpandResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
 	GetUserBucket(ctx context.Context, in *GetUserBucketRequest, opts ...grpc.CallOption) (*GetUserBucketResponse, error)
 	ShortBatch(ctx context.Context, in *ShortBatchRequest, opts ...grpc.CallOption) (*ShortBatchResponse, error)
+	// ImportURLs stores streamed URLs by chunks and streams back results of every stored chunk,
+	// so caller has to receive while it sends. Messages violating validation rules abort import
+	ImportURLs(ctx context.Context, opts ...grpc.CallOption) (ShortenerService_ImportURLsClient, error)
+	// ExportURLs streams every short URL of user ordered by creation
+	ExportURLs(ctx context.Context, in *ExportURLsRequest, opts ...grpc.CallOption) (ShortenerService_ExportURLsClient, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// MigrationStatus reports schema migrations of storage, FAILED_PRECONDITION when storage keeps no schema
+	MigrationStatus(ctx context.Context, in *MigrationStatusRequest, opts ...grpc.CallOption) (*MigrationStatusResponse, error)
+	StreamClicks(ctx context.Context, in *StreamClicksRequest, opts ...grpc.CallOption) (ShortenerService_StreamClicksClient, error)
+	UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error)
+	GetURLHistory(ctx context.Context, in *GetURLHistoryRequest, opts ...grpc.CallOption) (*GetURLHistoryResponse, error)
 }
 
 type shortenerServiceClient struct {
@@ -78,6 +96,16 @@ func (c *shortenerServiceClient) Delete(ctx context.Context, in *DeleteRequest,
 	return out, nil
 }
 
+func (c *shortenerServiceClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shortenerServiceClient) GetUserBucket(ctx context.Context, in *GetUserBucketRequest, opts ...grpc.CallOption) (*GetUserBucketResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetUserBucketResponse)
@@ -98,6 +126,71 @@ func (c *shortenerServiceClient) ShortBatch(ctx context.Context, in *ShortBatchR
 	return out, nil
 }
 
+func (c *shortenerServiceClient) ImportURLs(ctx context.Context, opts ...grpc.CallOption) (ShortenerService_ImportURLsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShortenerService_ServiceDesc.Streams[0], ShortenerService_ImportURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerServiceImportURLsClient{ClientStream: stream}
+	return x, nil
+}
+
+type ShortenerService_ImportURLsClient interface {
+	Send(*ImportURLsRequest) error
+	Recv() (*ImportURLsResponse, error)
+	grpc.ClientStream
+}
+
+type shortenerServiceImportURLsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerServiceImportURLsClient) Send(m *ImportURLsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *shortenerServiceImportURLsClient) Recv() (*ImportURLsResponse, error) {
+	m := new(ImportURLsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortenerServiceClient) ExportURLs(ctx context.Context, in *ExportURLsRequest, opts ...grpc.CallOption) (ShortenerService_ExportURLsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShortenerService_ServiceDesc.Streams[1], ShortenerService_ExportURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerServiceExportURLsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ShortenerService_ExportURLsClient interface {
+	Recv() (*ExportedURL, error)
+	grpc.ClientStream
+}
+
+type shortenerServiceExportURLsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerServiceExportURLsClient) Recv() (*ExportedURL, error) {
+	m := new(ExportedURL)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *shortenerServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsResponse)
@@ -108,6 +201,69 @@ func (c *shortenerServiceClient) Stats(ctx context.Context, in *StatsRequest, op
 	return out, nil
 }
 
+func (c *shortenerServiceClient) MigrationStatus(ctx context.Context, in *MigrationStatusRequest, opts ...grpc.CallOption) (*MigrationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MigrationStatusResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_MigrationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerServiceClient) StreamClicks(ctx context.Context, in *StreamClicksRequest, opts ...grpc.CallOption) (ShortenerService_StreamClicksClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShortenerService_ServiceDesc.Streams[2], ShortenerService_StreamClicks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerServiceStreamClicksClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ShortenerService_StreamClicksClient interface {
+	Recv() (*ClickEvent, error)
+	grpc.ClientStream
+}
+
+type shortenerServiceStreamClicksClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerServiceStreamClicksClient) Recv() (*ClickEvent, error) {
+	m := new(ClickEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortenerServiceClient) UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateURLResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_UpdateURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerServiceClient) GetURLHistory(ctx context.Context, in *GetURLHistoryRequest, opts ...grpc.CallOption) (*GetURLHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetURLHistoryResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_GetURLHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServiceServer is the server API for ShortenerService service.
 // All implementations must embed UnimplementedShortenerServiceServer
 // for forward compatibility.
@@ -115,9 +271,20 @@ type ShortenerServiceServer interface {
 	Short(context.Context, *ShortRequest) (*ShortResponse, error)
 	Expand(context.Context, *ExpandRequest) (*ExpandResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
 	GetUserBucket(context.Context, *GetUserBucketRequest) (*GetUserBucketResponse, error)
 	ShortBatch(context.Context, *ShortBatchRequest) (*ShortBatchResponse, error)
+	// ImportURLs stores streamed URLs by chunks and streams back results of every stored chunk,
+	// so caller has to receive while it sends. Messages violating validation rules abort import
+	ImportURLs(ShortenerService_ImportURLsServer) error
+	// ExportURLs streams every short URL of user ordered by creation
+	ExportURLs(*ExportURLsRequest, ShortenerService_ExportURLsServer) error
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// MigrationStatus reports schema migrations of storage, FAILED_PRECONDITION when storage keeps no schema
+	MigrationStatus(context.Context, *MigrationStatusRequest) (*MigrationStatusResponse, error)
+	StreamClicks(*StreamClicksRequest, ShortenerService_StreamClicksServer) error
+	UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error)
+	GetURLHistory(context.Context, *GetURLHistoryRequest) (*GetURLHistoryResponse, error)
 	mustEmbedUnimplementedShortenerServiceServer()
 }
 
@@ -137,15 +304,36 @@ func (UnimplementedShortenerServiceServer) Expand(context.Context, *ExpandReques
 func (UnimplementedShortenerServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedShortenerServiceServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
 func (UnimplementedShortenerServiceServer) GetUserBucket(context.Context, *GetUserBucketRequest) (*GetUserBucketResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserBucket not implemented")
 }
 func (UnimplementedShortenerServiceServer) ShortBatch(context.Context, *ShortBatchRequest) (*ShortBatchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ShortBatch not implemented")
 }
+func (UnimplementedShortenerServiceServer) ImportURLs(ShortenerService_ImportURLsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportURLs not implemented")
+}
+func (UnimplementedShortenerServiceServer) ExportURLs(*ExportURLsRequest, ShortenerService_ExportURLsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportURLs not implemented")
+}
 func (UnimplementedShortenerServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedShortenerServiceServer) MigrationStatus(context.Context, *MigrationStatusRequest) (*MigrationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrationStatus not implemented")
+}
+func (UnimplementedShortenerServiceServer) StreamClicks(*StreamClicksRequest, ShortenerService_StreamClicksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamClicks not implemented")
+}
+func (UnimplementedShortenerServiceServer) UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
+func (UnimplementedShortenerServiceServer) GetURLHistory(context.Context, *GetURLHistoryRequest) (*GetURLHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLHistory not implemented")
+}
 func (UnimplementedShortenerServiceServer) mustEmbedUnimplementedShortenerServiceServer() {}
 func (UnimplementedShortenerServiceServer) testEmbeddedByValue()                          {}
 
@@ -221,6 +409,24 @@ func _ShortenerService_Delete_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShortenerService_GetUserBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetUserBucketRequest)
 	if err := dec(in); err != nil {
@@ -257,6 +463,53 @@ func _ShortenerService_ShortBatch_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_ImportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServiceServer).ImportURLs(&shortenerServiceImportURLsServer{ServerStream: stream})
+}
+
+type ShortenerService_ImportURLsServer interface {
+	Send(*ImportURLsResponse) error
+	Recv() (*ImportURLsRequest, error)
+	grpc.ServerStream
+}
+
+type shortenerServiceImportURLsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerServiceImportURLsServer) Send(m *ImportURLsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *shortenerServiceImportURLsServer) Recv() (*ImportURLsRequest, error) {
+	m := new(ImportURLsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ShortenerService_ExportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportURLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServiceServer).ExportURLs(m, &shortenerServiceExportURLsServer{ServerStream: stream})
+}
+
+type ShortenerService_ExportURLsServer interface {
+	Send(*ExportedURL) error
+	grpc.ServerStream
+}
+
+type shortenerServiceExportURLsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerServiceExportURLsServer) Send(m *ExportedURL) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _ShortenerService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StatsRequest)
 	if err := dec(in); err != nil {
@@ -275,6 +528,81 @@ func _ShortenerService_Stats_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_MigrationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).MigrationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_MigrationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).MigrationStatus(ctx, req.(*MigrationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortenerService_StreamClicks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamClicksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServiceServer).StreamClicks(m, &shortenerServiceStreamClicksServer{ServerStream: stream})
+}
+
+type ShortenerService_StreamClicksServer interface {
+	Send(*ClickEvent) error
+	grpc.ServerStream
+}
+
+type shortenerServiceStreamClicksServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerServiceStreamClicksServer) Send(m *ClickEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ShortenerService_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).UpdateURL(ctx, req.(*UpdateURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortenerService_GetURLHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).GetURLHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_GetURLHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).GetURLHistory(ctx, req.(*GetURLHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShortenerService_ServiceDesc is the grpc.ServiceDesc for ShortenerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -294,6 +622,10 @@ var ShortenerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _ShortenerService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _ShortenerService_Restore_Handler,
+		},
 		{
 			MethodName: "GetUserBucket",
 			Handler:    _ShortenerService_GetUserBucket_Handler,
@@ -306,7 +638,36 @@ var ShortenerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stats",
 			Handler:    _ShortenerService_Stats_Handler,
 		},
+		{
+			MethodName: "MigrationStatus",
+			Handler:    _ShortenerService_MigrationStatus_Handler,
+		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _ShortenerService_UpdateURL_Handler,
+		},
+		{
+			MethodName: "GetURLHistory",
+			Handler:    _ShortenerService_GetURLHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportURLs",
+			Handler:       _ShortenerService_ImportURLs_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportURLs",
+			Handler:       _ShortenerService_ExportURLs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamClicks",
+			Handler:       _ShortenerService_StreamClicks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/shortener/v1/shortener.proto",
 }
@@ -0,0 +1,83 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bufbuild/protovalidate-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Validation - interceptors which check gRPC requests against buf.validate annotations of proto messages
+type Validation struct {
+	validator *protovalidate.Validator
+}
+
+// Unary - unary server interceptor which rejects invalid requests before handler
+func (v *Validation) Unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := v.validate(req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream - stream server interceptor which rejects invalid received messages
+func (v *Validation) Stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &validatedServerStream{ServerStream: ss, validation: v})
+}
+
+// validate - returns InvalidArgument status with field violations as BadRequest details
+func (v *Validation) validate(req interface{}) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := v.validator.Validate(msg)
+	if err == nil {
+		return nil
+	}
+	var validationErr *protovalidate.ValidationError
+	if !errors.As(err, &validationErr) {
+		return status.Errorf(codes.Internal, err.Error())
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range validationErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.GetFieldPath(),
+			Description: violation.GetMessage(),
+		})
+	}
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+type validatedServerStream struct {
+	grpc.ServerStream
+	validation *Validation
+}
+
+// RecvMsg - receive message from client and validate it
+func (s *validatedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.validation.validate(m)
+}
+
+// NewValidation - create instance of Validation interceptors
+func NewValidation() (*Validation, error) {
+	validator, err := protovalidate.New()
+	if err != nil {
+		return nil, err
+	}
+	return &Validation{validator: validator}, nil
+}
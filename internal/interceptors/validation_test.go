@@ -0,0 +1,33 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/PaBah/url-shortener.git/internal/gen/proto/shortener/v1"
+)
+
+func TestValidation_Unary(t *testing.T) {
+	validation, err := NewValidation()
+	require.NoError(t, err)
+
+	handled := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handled = true
+		return &pb.ExpandResponse{}, nil
+	}
+
+	_, err = validation.Unary(context.Background(), &pb.ExpandRequest{}, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Invalid request rejected")
+	assert.False(t, handled, "Handler not called for invalid request")
+
+	_, err = validation.Unary(context.Background(), &pb.ExpandRequest{ShortId: "2187b119"}, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	assert.True(t, handled, "Handler called for valid request")
+}
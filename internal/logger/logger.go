@@ -32,6 +32,11 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap - returns wrapped http.ResponseWriter, so http.ResponseController can flush streamed responses
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // Log - returns actual logger
 func Log() *zap.Logger {
 	return zap.L()
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shortener"
+
+// Registry - registry of all application metrics and Go runtime stats
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal - amount of handled HTTP requests by chi route, method and status
+	HTTPRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Amount of handled HTTP requests.",
+	}, []string{"method", "route", "status"})
+	// HTTPRequestDuration - latency of HTTP requests by chi route, method and status
+	HTTPRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	// GRPCRequestsTotal - amount of handled gRPC unary calls by method and status code
+	GRPCRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Amount of handled gRPC unary calls.",
+	}, []string{"method", "code"})
+	// GRPCRequestDuration - latency of gRPC unary calls by method
+	GRPCRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of gRPC unary calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	// StorageOperationDuration - latency of storage operations by Repository method and result
+	StorageOperationDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Latency of storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+	// DeletionQueueDepth - amount of short URLs waiting for deletion workers
+	DeletionQueueDepth = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "deletion_queue_depth",
+		Help:      "Amount of short URLs waiting for deletion workers.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler - returns HTTP handler which exposes metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/PaBah/url-shortener.git/internal/mock"
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(HTTPMiddleware)
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/2187b119", "/bc2c0be9", "/ping", "/api/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/{id}", "410")), "requests counted per route pattern")
+	assert.Equal(t, float64(1), testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/ping", "200")), "implicit status is 200")
+	assert.Equal(t, float64(1), testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues(http.MethodGet, notFoundRoute, "404")), "unmatched paths share one label")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.shortener.v1.ShortenerService/Expand"}
+
+	_, _ = UnaryServerInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	_, err := UnaryServerInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.FailedPrecondition, "expired")
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "handler error returned as is")
+	assert.Equal(t, float64(1), testutil.ToFloat64(GRPCRequestsTotal.WithLabelValues(info.FullMethod, codes.OK.String())))
+	assert.Equal(t, float64(1), testutil.ToFloat64(GRPCRequestsTotal.WithLabelValues(info.FullMethod, codes.FailedPrecondition.String())))
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	rm.EXPECT().FindByID(gomock.Any(), "2187b119").Return(models.ShortenURL{UUID: "2187b119"}, nil).Times(1)
+	rm.EXPECT().FindByID(gomock.Any(), "bc2c0be9").Return(models.ShortenURL{}, errors.New("not found")).Times(1)
+	rm.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(1, nil).Times(1)
+
+	repository := NewInstrumentedRepository(rm)
+	shortURL, err := repository.FindByID(context.Background(), "2187b119")
+	require.NoError(t, err)
+	assert.Equal(t, "2187b119", shortURL.UUID, "result of wrapped repository returned")
+	_, err = repository.FindByID(context.Background(), "bc2c0be9")
+	assert.Error(t, err, "error of wrapped repository returned")
+	deleted, _ := repository.DeleteExpired(context.Background(), time.Now())
+	assert.Equal(t, 1, deleted)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(StorageOperationDuration), "latency observed per operation and result")
+}
+
+func TestHandler(t *testing.T) {
+	DeletionQueueDepth.Set(3)
+	w := httptest.NewRecorder()
+
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "shortener_deletion_queue_depth 3")
+	assert.Contains(t, w.Body.String(), "go_goroutines", "Go runtime stats exposed")
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+)
+
+// InstrumentedRepository - storage.Repository decorator which observes latency of storage operations
+type InstrumentedRepository struct {
+	repository storage.Repository
+}
+
+func observe(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	StorageOperationDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
+
+// Store - instrumented storage.Repository Store
+func (r *InstrumentedRepository) Store(ctx context.Context, shortURL *models.ShortenURL) (err error) {
+	defer func(start time.Time) { observe("Store", start, err) }(time.Now())
+	return r.repository.Store(ctx, shortURL)
+}
+
+// FindByID - instrumented storage.Repository FindByID
+func (r *InstrumentedRepository) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
+	defer func(start time.Time) { observe("FindByID", start, err) }(time.Now())
+	return r.repository.FindByID(ctx, ID)
+}
+
+// ListUserURLs - instrumented storage.Repository ListUserURLs
+func (r *InstrumentedRepository) ListUserURLs(ctx context.Context, options models.ListOptions) (page models.URLsPage, err error) {
+	defer func(start time.Time) { observe("ListUserURLs", start, err) }(time.Now())
+	return r.repository.ListUserURLs(ctx, options)
+}
+
+// StoreBatch - instrumented storage.Repository StoreBatch
+func (r *InstrumentedRepository) StoreBatch(ctx context.Context, shortURLs []models.ShortenURL) (results []models.BatchResult, err error) {
+	defer func(start time.Time) { observe("StoreBatch", start, err) }(time.Now())
+	return r.repository.StoreBatch(ctx, shortURLs)
+}
+
+// AsyncCheckURLsUserID - storage.Repository AsyncCheckURLsUserID, not instrumented as it is asynchronous
+func (r *InstrumentedRepository) AsyncCheckURLsUserID(userID string, shortURL chan string) chan string {
+	return r.repository.AsyncCheckURLsUserID(userID, shortURL)
+}
+
+// DeleteShortURLs - instrumented storage.Repository DeleteShortURLs
+func (r *InstrumentedRepository) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
+	defer func(start time.Time) { observe("DeleteShortURLs", start, err) }(time.Now())
+	return r.repository.DeleteShortURLs(ctx, shortURLs)
+}
+
+// GetStats - instrumented storage.Repository GetStats
+func (r *InstrumentedRepository) GetStats(ctx context.Context) (stats models.Stats, err error) {
+	defer func(start time.Time) { observe("GetStats", start, err) }(time.Now())
+	return r.repository.GetStats(ctx)
+}
+
+// RegisterClick - instrumented storage.Repository RegisterClick
+func (r *InstrumentedRepository) RegisterClick(ctx context.Context, ID string) (err error) {
+	defer func(start time.Time) { observe("RegisterClick", start, err) }(time.Now())
+	return r.repository.RegisterClick(ctx, ID)
+}
+
+// DeleteExpired - instrumented storage.Repository DeleteExpired
+func (r *InstrumentedRepository) DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error) {
+	defer func(start time.Time) { observe("DeleteExpired", start, err) }(time.Now())
+	return r.repository.DeleteExpired(ctx, now)
+}
+
+// StoreClicks - instrumented storage.Repository StoreClicks
+func (r *InstrumentedRepository) StoreClicks(ctx context.Context, clicks []models.Click) (err error) {
+	defer func(start time.Time) { observe("StoreClicks", start, err) }(time.Now())
+	return r.repository.StoreClicks(ctx, clicks)
+}
+
+// GetClickStats - instrumented storage.Repository GetClickStats
+func (r *InstrumentedRepository) GetClickStats(ctx context.Context, shortURL string) (stats []models.ClickStats, err error) {
+	defer func(start time.Time) { observe("GetClickStats", start, err) }(time.Now())
+	return r.repository.GetClickStats(ctx, shortURL)
+}
+
+// CountClicks - instrumented storage.Repository CountClicks
+func (r *InstrumentedRepository) CountClicks(ctx context.Context, shortURLs []string) (counts map[string]int, err error) {
+	defer func(start time.Time) { observe("CountClicks", start, err) }(time.Now())
+	return r.repository.CountClicks(ctx, shortURLs)
+}
+
+// UpdateURL - instrumented storage.Repository UpdateURL
+func (r *InstrumentedRepository) UpdateURL(ctx context.Context, ID string, originalURL string) (err error) {
+	defer func(start time.Time) { observe("UpdateURL", start, err) }(time.Now())
+	return r.repository.UpdateURL(ctx, ID, originalURL)
+}
+
+// GetURLHistory - instrumented storage.Repository GetURLHistory
+func (r *InstrumentedRepository) GetURLHistory(ctx context.Context, ID string) (versions []models.URLVersion, err error) {
+	defer func(start time.Time) { observe("GetURLHistory", start, err) }(time.Now())
+	return r.repository.GetURLHistory(ctx, ID)
+}
+
+// RestoreShortURLs - instrumented storage.Repository RestoreShortURLs
+func (r *InstrumentedRepository) RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error) {
+	defer func(start time.Time) { observe("RestoreShortURLs", start, err) }(time.Now())
+	return r.repository.RestoreShortURLs(ctx, shortURLs)
+}
+
+// PurgeDeleted - instrumented storage.Repository PurgeDeleted
+func (r *InstrumentedRepository) PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error) {
+	defer func(start time.Time) { observe("PurgeDeleted", start, err) }(time.Now())
+	return r.repository.PurgeDeleted(ctx, before)
+}
+
+// ScanURLs - instrumented storage.Repository ScanURLs, duration includes scan callbacks
+func (r *InstrumentedRepository) ScanURLs(ctx context.Context, scan func(shortURL models.ShortenURL) error) (err error) {
+	defer func(start time.Time) { observe("ScanURLs", start, err) }(time.Now())
+	return r.repository.ScanURLs(ctx, scan)
+}
+
+// UpsertURLs - instrumented storage.Repository UpsertURLs
+func (r *InstrumentedRepository) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error) {
+	defer func(start time.Time) { observe("UpsertURLs", start, err) }(time.Now())
+	return r.repository.UpsertURLs(ctx, shortURLs)
+}
+
+// MigrationStatus - instrumented storage.Repository MigrationStatus
+func (r *InstrumentedRepository) MigrationStatus(ctx context.Context) (status models.MigrationStatus, err error) {
+	defer func(start time.Time) { observe("MigrationStatus", start, err) }(time.Now())
+	return r.repository.MigrationStatus(ctx)
+}
+
+// Ping - check connection of wrapped storage.Repository, it is not observed as repository operation
+func (r *InstrumentedRepository) Ping(ctx context.Context) error {
+	return storage.Ping(ctx, r.repository)
+}
+
+// NewInstrumentedRepository - wrap storage.Repository with operations latency metrics
+func NewInstrumentedRepository(repository storage.Repository) storage.Repository {
+	return &InstrumentedRepository{repository: repository}
+}
+
+// RegisterRepositoryCache - expose hits and misses of storage.CachedRepository lookups
+func RegisterRepositoryCache(cache *storage.CachedRepository) error {
+	for result, count := range map[string]func() uint64{"hit": cache.Hits, "miss": cache.Misses} {
+		count := count
+		err := Registry.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "storage_cache_lookups_total",
+			Help:        "Amount of short URL lookups by storage cache result.",
+			ConstLabels: prometheus.Labels{"result": result},
+		}, func() float64 { return float64(count()) }))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
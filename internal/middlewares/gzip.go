@@ -43,6 +43,18 @@ func (c *compressWriter) WriteHeader(statusCode int) {
 	c.writer.WriteHeader(statusCode)
 }
 
+// Flush - send data compressed so far to client, so streamed responses are not held in gzip.Writer buffer
+func (c *compressWriter) Flush() {
+	if err := c.zipWriter.Flush(); err == nil {
+		_ = http.NewResponseController(c.writer).Flush()
+	}
+}
+
+// Unwrap - returns wrapped http.ResponseWriter for http.ResponseController
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.writer
+}
+
 // Close - close gzip.Writer and send rest from buffer
 func (c *compressWriter) Close() error {
 	return c.zipWriter.Close()
@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/PaBah/url-shortener.git/internal/models"
 	"go.uber.org/mock/gomock"
@@ -49,6 +50,36 @@ func (mr *MockRepositoryMockRecorder) AsyncCheckURLsUserID(usedID, shortURL inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncCheckURLsUserID", reflect.TypeOf((*MockRepository)(nil).AsyncCheckURLsUserID), usedID, shortURL)
 }
 
+// CountClicks mocks base method.
+func (m *MockRepository) CountClicks(ctx context.Context, shortURLs []string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClicks", ctx, shortURLs)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClicks indicates an expected call of CountClicks.
+func (mr *MockRepositoryMockRecorder) CountClicks(ctx, shortURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClicks", reflect.TypeOf((*MockRepository)(nil).CountClicks), ctx, shortURLs)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockRepositoryMockRecorder) DeleteExpired(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRepository)(nil).DeleteExpired), ctx, now)
+}
+
 // DeleteShortURLs mocks base method.
 func (m *MockRepository) DeleteShortURLs(ctx context.Context, shortURLs []string) error {
 	m.ctrl.T.Helper()
@@ -78,29 +109,28 @@ func (mr *MockRepositoryMockRecorder) FindByID(ctx, ID interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), ctx, ID)
 }
 
-// GetAllUsers mocks base method.
-func (m *MockRepository) GetAllUsers(ctx context.Context) ([]models.ShortenURL, error) {
+// GetClickStats mocks base method.
+func (m *MockRepository) GetClickStats(ctx context.Context, shortURL string) ([]models.ClickStats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers", ctx)
-	ret0, _ := ret[0].([]models.ShortenURL)
+	ret := m.ctrl.Call(m, "GetClickStats", ctx, shortURL)
+	ret0, _ := ret[0].([]models.ClickStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllUsers indicates an expected call of GetAllUsers.
-func (mr *MockRepositoryMockRecorder) GetAllUsers(ctx interface{}) *gomock.Call {
+// GetClickStats indicates an expected call of GetClickStats.
+func (mr *MockRepositoryMockRecorder) GetClickStats(ctx, shortURL interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockRepository)(nil).GetAllUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickStats", reflect.TypeOf((*MockRepository)(nil).GetClickStats), ctx, shortURL)
 }
 
 // GetStats mocks base method.
-func (m *MockRepository) GetStats(ctx context.Context) (int, int, error) {
+func (m *MockRepository) GetStats(ctx context.Context) (models.Stats, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetStats", ctx)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(models.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetStats indicates an expected call of GetStats.
@@ -109,8 +139,111 @@ func (mr *MockRepositoryMockRecorder) GetStats(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockRepository)(nil).GetStats), ctx)
 }
 
+// GetURLHistory mocks base method.
+func (m *MockRepository) GetURLHistory(ctx context.Context, ID string) ([]models.URLVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLHistory", ctx, ID)
+	ret0, _ := ret[0].([]models.URLVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLHistory indicates an expected call of GetURLHistory.
+func (mr *MockRepositoryMockRecorder) GetURLHistory(ctx, ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLHistory", reflect.TypeOf((*MockRepository)(nil).GetURLHistory), ctx, ID)
+}
+
+// ListUserURLs mocks base method.
+func (m *MockRepository) ListUserURLs(ctx context.Context, options models.ListOptions) (models.URLsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserURLs", ctx, options)
+	ret0, _ := ret[0].(models.URLsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserURLs indicates an expected call of ListUserURLs.
+func (mr *MockRepositoryMockRecorder) ListUserURLs(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserURLs", reflect.TypeOf((*MockRepository)(nil).ListUserURLs), ctx, options)
+}
+
+// MigrationStatus mocks base method.
+func (m *MockRepository) MigrationStatus(ctx context.Context) (models.MigrationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationStatus", ctx)
+	ret0, _ := ret[0].(models.MigrationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrationStatus indicates an expected call of MigrationStatus.
+func (mr *MockRepositoryMockRecorder) MigrationStatus(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationStatus", reflect.TypeOf((*MockRepository)(nil).MigrationStatus), ctx)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockRepositoryMockRecorder) PurgeDeleted(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockRepository)(nil).PurgeDeleted), ctx, before)
+}
+
+// RegisterClick mocks base method.
+func (m *MockRepository) RegisterClick(ctx context.Context, ID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterClick", ctx, ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterClick indicates an expected call of RegisterClick.
+func (mr *MockRepositoryMockRecorder) RegisterClick(ctx, ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterClick", reflect.TypeOf((*MockRepository)(nil).RegisterClick), ctx, ID)
+}
+
+// RestoreShortURLs mocks base method.
+func (m *MockRepository) RestoreShortURLs(ctx context.Context, shortURLs []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreShortURLs", ctx, shortURLs)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreShortURLs indicates an expected call of RestoreShortURLs.
+func (mr *MockRepositoryMockRecorder) RestoreShortURLs(ctx, shortURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreShortURLs", reflect.TypeOf((*MockRepository)(nil).RestoreShortURLs), ctx, shortURLs)
+}
+
+// ScanURLs mocks base method.
+func (m *MockRepository) ScanURLs(ctx context.Context, scan func(models.ShortenURL) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanURLs", ctx, scan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanURLs indicates an expected call of ScanURLs.
+func (mr *MockRepositoryMockRecorder) ScanURLs(ctx, scan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanURLs", reflect.TypeOf((*MockRepository)(nil).ScanURLs), ctx, scan)
+}
+
 // Store mocks base method.
-func (m *MockRepository) Store(ctx context.Context, shortURL models.ShortenURL) error {
+func (m *MockRepository) Store(ctx context.Context, shortURL *models.ShortenURL) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Store", ctx, shortURL)
 	ret0, _ := ret[0].(error)
@@ -124,15 +257,95 @@ func (mr *MockRepositoryMockRecorder) Store(ctx, shortURL interface{}) *gomock.C
 }
 
 // StoreBatch mocks base method.
-func (m *MockRepository) StoreBatch(ctx context.Context, shortURLsMap map[string]models.ShortenURL) error {
+func (m *MockRepository) StoreBatch(ctx context.Context, shortURLs []models.ShortenURL) ([]models.BatchResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreBatch", ctx, shortURLsMap)
+	ret := m.ctrl.Call(m, "StoreBatch", ctx, shortURLs)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoreBatch indicates an expected call of StoreBatch.
+func (mr *MockRepositoryMockRecorder) StoreBatch(ctx, shortURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreBatch", reflect.TypeOf((*MockRepository)(nil).StoreBatch), ctx, shortURLs)
+}
+
+// StoreClicks mocks base method.
+func (m *MockRepository) StoreClicks(ctx context.Context, clicks []models.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreClicks", ctx, clicks)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// StoreBatch indicates an expected call of StoreBatch.
-func (mr *MockRepositoryMockRecorder) StoreBatch(ctx, shortURLsMap interface{}) *gomock.Call {
+// StoreClicks indicates an expected call of StoreClicks.
+func (mr *MockRepositoryMockRecorder) StoreClicks(ctx, clicks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreClicks", reflect.TypeOf((*MockRepository)(nil).StoreClicks), ctx, clicks)
+}
+
+// UpdateURL mocks base method.
+func (m *MockRepository) UpdateURL(ctx context.Context, ID, originalURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", ctx, ID, originalURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockRepositoryMockRecorder) UpdateURL(ctx, ID, originalURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockRepository)(nil).UpdateURL), ctx, ID, originalURL)
+}
+
+// UpsertURLs mocks base method.
+func (m *MockRepository) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertURLs", ctx, shortURLs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertURLs indicates an expected call of UpsertURLs.
+func (mr *MockRepositoryMockRecorder) UpsertURLs(ctx, shortURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertURLs", reflect.TypeOf((*MockRepository)(nil).UpsertURLs), ctx, shortURLs)
+}
+
+// MockPinger is a mock of Pinger interface.
+type MockPinger struct {
+	ctrl     *gomock.Controller
+	recorder *MockPingerMockRecorder
+}
+
+// MockPingerMockRecorder is the mock recorder for MockPinger.
+type MockPingerMockRecorder struct {
+	mock *MockPinger
+}
+
+// NewMockPinger creates a new mock instance.
+func NewMockPinger(ctrl *gomock.Controller) *MockPinger {
+	mock := &MockPinger{ctrl: ctrl}
+	mock.recorder = &MockPingerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPinger) EXPECT() *MockPingerMockRecorder {
+	return m.recorder
+}
+
+// Ping mocks base method.
+func (m *MockPinger) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockPingerMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreBatch", reflect.TypeOf((*MockRepository)(nil).StoreBatch), ctx, shortURLsMap)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockPinger)(nil).Ping), ctx)
 }
@@ -0,0 +1,21 @@
+package models
+
+// BatchStatus - outcome of storing one shortened URL of batch
+type BatchStatus string
+
+// Outcomes of storing shortened URL of batch
+const (
+	// BatchCreated - shortened URL stored
+	BatchCreated BatchStatus = "created"
+	// BatchExisted - same URL of the same user was already shortened, stored one is kept
+	BatchExisted BatchStatus = "existed"
+	// BatchFailed - shortened URL was not stored, see BatchResult.Err
+	BatchFailed BatchStatus = "failed"
+)
+
+// BatchResult - result of storing one shortened URL of batch
+type BatchResult struct {
+	Status   BatchStatus // Status - outcome of storing
+	ShortURL ShortenURL  // ShortURL - stored shortened URL, already existing one for BatchExisted
+	Err      error       // Err - reason of BatchFailed
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// Limits of stored click fields
+const (
+	// MaxReferrerLength - max length of stored click referrer
+	MaxReferrerLength = 2048
+	// MaxUserAgentLength - max length of stored click user agent
+	MaxUserAgentLength = 512
+)
+
+// Click - model entity of redirect by shortened URL
+type Click struct {
+	ShortURL  string    `json:"short_url"`
+	Timestamp time.Time `json:"timestamp"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+}
+
+// ClickStats - aggregated clicks of shortened URL per day
+type ClickStats struct {
+	Day      time.Time
+	Clicks   int
+	Visitors int
+}
+
+// NewClick - create instance of Click at the moment with anonymised client IP
+func NewClick(shortURL string, referrer string, userAgent string, ip string) Click {
+	return Click{
+		ShortURL:  shortURL,
+		Timestamp: time.Now().UTC(),
+		Referrer:  truncate(referrer, MaxReferrerLength),
+		UserAgent: truncate(userAgent, MaxUserAgentLength),
+		IP:        AnonymizeIP(ip),
+	}
+}
+
+// AnonymizeIP - zero host part of IP: last octet for IPv4 and last 80 bits for IPv6
+func AnonymizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
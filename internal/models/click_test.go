@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "192.168.10.42", want: "192.168.10.0"},
+		{ip: "2001:db8:85a3:8d3:1319:8a2e:370:7348", want: "2001:db8:85a3::"},
+		{ip: "not an ip", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, AnonymizeIP(tt.ip))
+		})
+	}
+}
+
+func TestNewClick(t *testing.T) {
+	click := NewClick("2187b119", "https://ya.ru/", "curl/8.0", "10.0.0.7")
+
+	assert.Equal(t, "2187b119", click.ShortURL)
+	assert.Equal(t, "10.0.0.0", click.IP, "IP anonymised")
+	assert.False(t, click.Timestamp.IsZero())
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Strategies of short ID generation
+const (
+	// HashIDStrategy - short ID is FNV-32 hash of original URL
+	HashIDStrategy = "hash"
+	// RandomIDStrategy - short ID is random base62 string
+	RandomIDStrategy = "random"
+	// SequenceIDStrategy - short ID is base62 encoded sequence number
+	SequenceIDStrategy = "sequence"
+
+	// MaxIDLength - max length of generated short ID
+	MaxIDLength = 8
+)
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// ErrIDGeneration - error when short ID can not be generated, it is failure of server and not of request
+var ErrIDGeneration = errors.New("short ID can not be generated")
+
+// IDGenerator - interface of short ID generation strategy
+type IDGenerator interface {
+	// Generate - returns short ID for original URL of user, attempt > 0 means previous ID collided
+	Generate(originalURL string, userID string, attempt int) (string, error)
+}
+
+// HashIDGenerator - generates short ID from hash of original URL
+type HashIDGenerator struct{}
+
+// Generate - returns FNV-32 hash of original URL, on collisions it is salted with user ID and attempt number,
+// so users who shorten the same URL retry with different IDs
+func (g HashIDGenerator) Generate(originalURL string, userID string, attempt int) (string, error) {
+	if attempt == 0 {
+		return buildID(originalURL), nil
+	}
+	return buildID(originalURL + "#" + userID + "#" + strconv.Itoa(attempt)), nil
+}
+
+// RandomIDGenerator - generates random base62 short ID
+type RandomIDGenerator struct {
+	Length int
+}
+
+// Generate - returns random base62 string of configured length, fails with ErrIDGeneration when system random source fails
+func (g RandomIDGenerator) Generate(_ string, _ string, _ int) (string, error) {
+	max := big.NewInt(int64(len(base62Alphabet)))
+	ID := make([]byte, g.Length)
+	for i := range ID {
+		n, err := rand.Int(randReader, max)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrIDGeneration, err)
+		}
+		ID[i] = base62Alphabet[n.Int64()]
+	}
+	return string(ID), nil
+}
+
+// SequenceIDGenerator - generates short ID from base62 encoded sequence
+type SequenceIDGenerator struct {
+	counter atomic.Uint64
+}
+
+// Generate - returns next base62 encoded sequence number
+func (g *SequenceIDGenerator) Generate(_ string, _ string, _ int) (string, error) {
+	return encodeBase62(g.counter.Add(1)), nil
+}
+
+// NewSequenceIDGenerator - create instance of SequenceIDGenerator which continues from start value
+func NewSequenceIDGenerator(start uint64) *SequenceIDGenerator {
+	g := &SequenceIDGenerator{}
+	g.counter.Store(start)
+	return g
+}
+
+// NewIDGenerator - create IDGenerator for strategy name
+func NewIDGenerator(strategy string, length int) (IDGenerator, error) {
+	switch strategy {
+	case "", HashIDStrategy:
+		return HashIDGenerator{}, nil
+	case RandomIDStrategy:
+		if length <= 0 || length > MaxIDLength {
+			return nil, fmt.Errorf("random ID length must be in range 1..%d, got %d", MaxIDLength, length)
+		}
+		return RandomIDGenerator{Length: length}, nil
+	case SequenceIDStrategy:
+		return NewSequenceIDGenerator(0), nil
+	}
+	return nil, fmt.Errorf("unknown ID generation strategy %q", strategy)
+}
+
+// randReader - source of random short IDs, replaced in tests
+var randReader io.Reader = rand.Reader
+
+var idGenerator atomic.Value
+
+// SetIDGenerator - replace IDGenerator used for new shortened URLs
+func SetIDGenerator(generator IDGenerator) {
+	idGenerator.Store(&generator)
+}
+
+// GetIDGenerator - returns IDGenerator used for new shortened URLs
+func GetIDGenerator() IDGenerator {
+	generator, ok := idGenerator.Load().(*IDGenerator)
+	if !ok {
+		return HashIDGenerator{}
+	}
+	return *generator
+}
+
+// RegenerateID - replace short ID of shortened URL after collision on attempt
+func RegenerateID(shortURL *ShortenURL, attempt int) (err error) {
+	ID, err := GetIDGenerator().Generate(shortURL.OriginalURL, shortURL.UserID, attempt)
+	if err != nil {
+		return
+	}
+	shortURL.UUID = ID
+	return
+}
+
+// DecodeSequenceID - returns sequence number of base62 encoded short ID, ok is false when ID is not
+// base62 string or its number does not fit uint64
+func DecodeSequenceID(ID string) (n uint64, ok bool) {
+	if ID == "" {
+		return 0, false
+	}
+	for i := 0; i < len(ID); i++ {
+		digit := strings.IndexByte(base62Alphabet, ID[i])
+		if digit < 0 || n > (math.MaxUint64-uint64(digit))/62 {
+			return 0, false
+		}
+		n = n*62 + uint64(digit)
+	}
+	return n, true
+}
+
+func encodeBase62(n uint64) string {
+	if n == 0 {
+		return string(base62Alphabet[0])
+	}
+	var ID []byte
+	for n > 0 {
+		ID = append([]byte{base62Alphabet[n%62]}, ID...)
+		n /= 62
+	}
+	return string(ID)
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generate - returns ID of generator which is not expected to fail
+func generate(t *testing.T, generator IDGenerator, originalURL string, userID string, attempt int) string {
+	t.Helper()
+	ID, err := generator.Generate(originalURL, userID, attempt)
+	require.NoError(t, err)
+	return ID
+}
+
+func TestHashIDGenerator_Generate(t *testing.T) {
+	generator := HashIDGenerator{}
+
+	assert.Equal(t, "2187b119", generate(t, generator, "https://practicum.yandex.ru/", "1", 0), "first attempt keeps FNV-32 hash")
+	assert.Equal(t, "2187b119", generate(t, generator, "https://practicum.yandex.ru/", "2", 0), "first attempt does not depend on user")
+	assert.NotEqual(t, "2187b119", generate(t, generator, "https://practicum.yandex.ru/", "1", 1), "retry attempt changes ID")
+	assert.Equal(t, generate(t, generator, "https://practicum.yandex.ru/", "1", 1), generate(t, generator, "https://practicum.yandex.ru/", "1", 1), "retry attempt is deterministic")
+	assert.NotEqual(t, generate(t, generator, "https://practicum.yandex.ru/", "1", 1), generate(t, generator, "https://practicum.yandex.ru/", "2", 1), "users retry with different IDs")
+}
+
+func TestRandomIDGenerator_Generate(t *testing.T) {
+	generator := RandomIDGenerator{Length: 6}
+
+	first := generate(t, generator, "https://practicum.yandex.ru/", "1", 0)
+	second := generate(t, generator, "https://practicum.yandex.ru/", "1", 0)
+	assert.Len(t, first, 6)
+	assert.Regexp(t, "^[0-9a-zA-Z]+$", first)
+	assert.NotEqual(t, first, second, "random IDs differ")
+
+	readErr := errors.New("entropy is exhausted")
+	randReader = iotest.ErrReader(readErr)
+	defer func() {
+		randReader = rand.Reader
+	}()
+	_, err := generator.Generate("https://practicum.yandex.ru/", "1", 0)
+	assert.ErrorIs(t, err, ErrIDGeneration)
+	assert.ErrorIs(t, err, readErr, "random source error is returned")
+}
+
+func TestSequenceIDGenerator_Generate(t *testing.T) {
+	generator := NewSequenceIDGenerator(59)
+
+	assert.Equal(t, "Y", generate(t, generator, "", "1", 0))
+	assert.Equal(t, "Z", generate(t, generator, "", "1", 0))
+	assert.Equal(t, "10", generate(t, generator, "", "1", 0))
+}
+
+func TestDecodeSequenceID(t *testing.T) {
+	tests := []struct {
+		name   string
+		ID     string
+		want   uint64
+		wantOk bool
+	}{
+		{name: "one digit", ID: "Z", want: 61, wantOk: true},
+		{name: "several digits", ID: "10", want: 62, wantOk: true},
+		{name: "max uint64", ID: encodeBase62(math.MaxUint64), want: math.MaxUint64, wantOk: true},
+		{name: "overflow", ID: encodeBase62(math.MaxUint64) + "0"},
+		{name: "not base62", ID: "sale-2024"},
+		{name: "empty", ID: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, ok := DecodeSequenceID(tt.ID)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, n)
+		})
+	}
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		length   int
+		want     IDGenerator
+		wantErr  bool
+	}{
+		{name: "default strategy", strategy: "", want: HashIDGenerator{}},
+		{name: "hash strategy", strategy: HashIDStrategy, want: HashIDGenerator{}},
+		{name: "random strategy", strategy: RandomIDStrategy, length: 8, want: RandomIDGenerator{Length: 8}},
+		{name: "random strategy with too long ID", strategy: RandomIDStrategy, length: 9, wantErr: true},
+		{name: "sequence strategy", strategy: SequenceIDStrategy, want: NewSequenceIDGenerator(0)},
+		{name: "unknown strategy", strategy: "uuid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := NewIDGenerator(tt.strategy, tt.length)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, generator)
+		})
+	}
+}
+
+func TestRegenerateID(t *testing.T) {
+	SetIDGenerator(RandomIDGenerator{Length: 4})
+	defer SetIDGenerator(HashIDGenerator{})
+
+	shortURL := ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/"}
+	require.NoError(t, RegenerateID(&shortURL, 1))
+	assert.Len(t, shortURL.UUID, 4, "ID regenerated by configured generator")
+
+	randReader = iotest.ErrReader(errors.New("entropy is exhausted"))
+	defer func() {
+		randReader = rand.Reader
+	}()
+	assert.ErrorIs(t, RegenerateID(&shortURL, 2), ErrIDGeneration)
+	assert.Len(t, shortURL.UUID, 4, "ID is kept when generation fails")
+}
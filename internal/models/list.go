@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sort fields of user's shortened URLs list
+const (
+	// SortByCreatedAt - sort by moment of shortening
+	SortByCreatedAt = "created_at"
+	// SortByOriginalURL - sort by original URL
+	SortByOriginalURL = "original_url"
+)
+
+// Status filters of user's shortened URLs list
+const (
+	// StatusAll - both active and deleted shortened URLs
+	StatusAll = "all"
+	// StatusActive - only not deleted shortened URLs
+	StatusActive = "active"
+	// StatusDeleted - only deleted shortened URLs
+	StatusDeleted = "deleted"
+)
+
+// Limits of user's shortened URLs page size
+const (
+	// DefaultListLimit - page size when limit is not requested
+	DefaultListLimit = 100
+	// MaxListLimit - max page size
+	MaxListLimit = 1000
+)
+
+// ErrInvalidListOptions - error when list options have unsupported values or cursor is broken
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// ListOptions - pagination, sorting and filtering of user's shortened URLs list
+type ListOptions struct {
+	Limit  int    // Limit - page size, DefaultListLimit when 0
+	Cursor string // Cursor - opaque position returned as URLsPage.NextCursor of previous page
+	SortBy string // SortBy - SortByCreatedAt (default) or SortByOriginalURL
+	Desc   bool   // Desc - sort in descending order
+	Status string // Status - StatusAll (default), StatusActive or StatusDeleted
+	Search string // Search - case-insensitive substring of original URL or short ID
+}
+
+// URLsPage - page of user's shortened URLs
+type URLsPage struct {
+	URLs       []ShortenURL
+	NextCursor string // NextCursor - cursor of next page, empty on the last page
+}
+
+// ListCursor - position after last shortened URL of page, short ID breaks ties of sort key
+type ListCursor struct {
+	SortBy    string    `json:"s"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	URL       string    `json:"u,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// Normalize - validate list options and fill defaults
+func (o ListOptions) Normalize() (ListOptions, error) {
+	if o.Limit == 0 {
+		o.Limit = DefaultListLimit
+	}
+	if o.Limit < 0 || o.Limit > MaxListLimit {
+		return o, fmt.Errorf("%w: limit must be in range 1..%d", ErrInvalidListOptions, MaxListLimit)
+	}
+	if o.SortBy == "" {
+		o.SortBy = SortByCreatedAt
+	}
+	if o.SortBy != SortByCreatedAt && o.SortBy != SortByOriginalURL {
+		return o, fmt.Errorf("%w: unknown sort field %q", ErrInvalidListOptions, o.SortBy)
+	}
+	if o.Status == "" {
+		o.Status = StatusAll
+	}
+	if o.Status != StatusAll && o.Status != StatusActive && o.Status != StatusDeleted {
+		return o, fmt.Errorf("%w: unknown status %q", ErrInvalidListOptions, o.Status)
+	}
+	if _, err := o.ParseCursor(); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// ParseCursor - decode Cursor, returns nil for the first page
+func (o ListOptions) ParseCursor() (*ListCursor, error) {
+	if o.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: broken cursor", ErrInvalidListOptions)
+	}
+	cursor := &ListCursor{}
+	if err = json.Unmarshal(data, cursor); err != nil || cursor.ID == "" {
+		return nil, fmt.Errorf("%w: broken cursor", ErrInvalidListOptions)
+	}
+	if cursor.SortBy != o.SortBy {
+		return nil, fmt.Errorf("%w: cursor belongs to list sorted by %q", ErrInvalidListOptions, cursor.SortBy)
+	}
+	return cursor, nil
+}
+
+// NewListCursor - build opaque cursor pointing after shortURL in list sorted by sortBy
+func NewListCursor(sortBy string, shortURL ShortenURL) string {
+	cursor := ListCursor{SortBy: sortBy, ID: shortURL.UUID}
+	if sortBy == SortByCreatedAt {
+		cursor.CreatedAt = shortURL.CreatedAt
+	} else {
+		cursor.URL = shortURL.OriginalURL
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
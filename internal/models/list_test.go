@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOptions_Normalize(t *testing.T) {
+	options, err := ListOptions{}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, ListOptions{Limit: DefaultListLimit, SortBy: SortByCreatedAt, Status: StatusAll}, options, "Defaults filled")
+
+	for _, invalid := range []ListOptions{{Limit: -1}, {Limit: MaxListLimit + 1}, {SortBy: "clicks"}, {Status: "expired"}, {Cursor: "%%%"}} {
+		_, err = invalid.Normalize()
+		assert.ErrorIs(t, err, ErrInvalidListOptions)
+	}
+}
+
+func TestListOptions_ParseCursor(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	cursor, err := ListOptions{SortBy: SortByCreatedAt, Cursor: NewListCursor(SortByCreatedAt, ShortenURL{UUID: "2187b119", CreatedAt: createdAt})}.ParseCursor()
+	require.NoError(t, err)
+	assert.Equal(t, "2187b119", cursor.ID)
+	assert.True(t, createdAt.Equal(cursor.CreatedAt), "Creation time kept with sub-second precision")
+
+	cursor, err = ListOptions{}.ParseCursor()
+	assert.NoError(t, err)
+	assert.Nil(t, cursor, "No cursor for the first page")
+}
@@ -0,0 +1,9 @@
+package models
+
+// MigrationStatus - state of schema migrations of storage
+type MigrationStatus struct {
+	Version uint // Version - version of last applied migration, 0 when none is applied
+	Latest  uint // Latest - version of the newest migration known to the service
+	Pending int  // Pending - amount of known migrations newer than Version
+	Dirty   bool // Dirty - applying of Version failed and schema has to be fixed manually
+}
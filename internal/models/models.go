@@ -3,19 +3,43 @@ package models
 import (
 	"encoding/hex"
 	"hash/fnv"
+	"time"
 )
 
 // ShortenURL - model entity of shortened URL
 type ShortenURL struct {
-	UUID        string `json:"uuid"`
-	UserID      string `json:"user_id"`
-	OriginalURL string `json:"original_URL"`
-	DeletedFlag bool   `json:"is_deleted"`
+	UUID        string    `json:"uuid"`
+	UserID      string    `json:"user_id"`
+	OriginalURL string    `json:"original_URL"`
+	DeletedFlag bool      `json:"is_deleted"`
+	IsAlias     bool      `json:"is_alias"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	MaxClicks   int       `json:"max_clicks,omitempty"`
+	Clicks      int       `json:"clicks,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at,omitempty"`
+}
+
+// IsExpired - check if shortened URL expired by date or by clicks amount at the moment
+func (s ShortenURL) IsExpired(now time.Time) bool {
+	if !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt) {
+		return true
+	}
+	return s.MaxClicks > 0 && s.Clicks >= s.MaxClicks
 }
 
 // NewShortURL - create  instance of ShortenURL
-func NewShortURL(originalURL string, userID string) ShortenURL {
-	return ShortenURL{UUID: buildID(originalURL), OriginalURL: originalURL, UserID: userID}
+func NewShortURL(originalURL string, userID string) (ShortenURL, error) {
+	ID, err := GetIDGenerator().Generate(originalURL, userID, 0)
+	if err != nil {
+		return ShortenURL{}, err
+	}
+	return ShortenURL{UUID: ID, OriginalURL: originalURL, UserID: userID}, nil
+}
+
+// NewAliasShortURL - create instance of ShortenURL with user defined short ID
+func NewAliasShortURL(alias string, originalURL string, userID string) ShortenURL {
+	return ShortenURL{UUID: alias, OriginalURL: originalURL, UserID: userID, IsAlias: true}
 }
 
 func buildID(Value string) (ID string) {
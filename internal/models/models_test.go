@@ -2,8 +2,10 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_buildID(t *testing.T) {
@@ -39,7 +41,29 @@ func Test_NewShortURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, NewShortURL(tt.value[0], tt.value[1]), tt.wantValue, "NewShortURL generated incorrect value")
+			shortURL, err := NewShortURL(tt.value[0], tt.value[1])
+			require.NoError(t, err)
+			assert.Equal(t, shortURL, tt.wantValue, "NewShortURL generated incorrect value")
+		})
+	}
+}
+
+func TestShortenURL_IsExpired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		shortURL ShortenURL
+		want     bool
+	}{
+		{name: "without limits", shortURL: ShortenURL{}, want: false},
+		{name: "expires in future", shortURL: ShortenURL{ExpiresAt: now.Add(time.Hour)}, want: false},
+		{name: "expired by date", shortURL: ShortenURL{ExpiresAt: now}, want: true},
+		{name: "clicks left", shortURL: ShortenURL{MaxClicks: 2, Clicks: 1}, want: false},
+		{name: "expired by clicks", shortURL: ShortenURL{MaxClicks: 2, Clicks: 2}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.shortURL.IsExpired(now))
 		})
 	}
 }
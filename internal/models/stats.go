@@ -0,0 +1,10 @@
+package models
+
+// Stats - amounts of users and shortened URLs in the system
+type Stats struct {
+	URLs    int // URLs - amount of stored shortened URLs, both active and soft deleted
+	Users   int // Users - amount of users owning stored shortened URLs
+	Active  int // Active - amount of not deleted shortened URLs
+	Deleted int // Deleted - amount of soft deleted shortened URLs waiting for purge
+	Purged  int // Purged - amount of soft deleted shortened URLs removed after retention period
+}
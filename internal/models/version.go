@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// URLVersion - previous destination of shortened URL replaced by its owner
+type URLVersion struct {
+	ShortURL    string    `json:"short_url"`
+	Version     int       `json:"version"`
+	OriginalURL string    `json:"original_url"`
+	ReplacedAt  time.Time `json:"replaced_at"`
+}
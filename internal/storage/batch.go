@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"database/sql"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// userURL - user ID and original URL pair which is unique among not alias shortened URLs
+type userURL struct {
+	userID      string
+	originalURL string
+}
+
+// batchIndexes - returns indexes of batch URLs in request order, so earlier URL wins when batch shortens same URL twice
+func batchIndexes(size int) []int {
+	indexes := make([]int, 0, size)
+	for i := 0; i < size; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// batchUserURLs - returns user ID and original URL pairs of not alias shortened URLs of batch indexes
+func batchUserURLs(indexes []int, shortURLs []models.ShortenURL) []userURL {
+	pairs := make([]userURL, 0, len(indexes))
+	for _, i := range indexes {
+		if shortURL := shortURLs[i]; !shortURL.IsAlias {
+			pairs = append(pairs, userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL})
+		}
+	}
+	return pairs
+}
+
+// conflictedIndexes - returns pending batch indexes which were not stored by insertion attempt,
+// only first index of short ID is stored when batch has it several times
+func conflictedIndexes(pending []int, shortURLs []models.ShortenURL, inserted map[string]struct{}) (conflicted []int) {
+	claimed := make(map[string]struct{}, len(inserted))
+	for _, i := range pending {
+		ID := shortURLs[i].UUID
+		if _, found := inserted[ID]; found {
+			if _, found = claimed[ID]; !found {
+				claimed[ID] = struct{}{}
+				continue
+			}
+		}
+		conflicted = append(conflicted, i)
+	}
+	return
+}
+
+// scanShortIDs - read short IDs returned by insert statement into inserted and close rows
+func scanShortIDs(rows *sql.Rows, inserted map[string]struct{}) (err error) {
+	defer rows.Close()
+	var ID string
+	for rows.Next() {
+		if err = rows.Scan(&ID); err != nil {
+			return
+		}
+		inserted[ID] = struct{}{}
+	}
+	return rows.Err()
+}
+
+// resolveBatch - fill results of pending batch indexes after insertion attempt.
+// inserted contains short IDs stored by attempt, storedURLs - not alias shortened URLs stored for pairs of pending indexes.
+// Indexes whose short ID was taken by another URL get regenerated ID and are returned for next attempt,
+// they fail when ID can not be regenerated
+func resolveBatch(pending []int, shortURLs []models.ShortenURL, inserted map[string]struct{},
+	storedURLs map[userURL]models.ShortenURL, attempt int, results []models.BatchResult) (next []int) {
+	for _, i := range pending {
+		shortURL := shortURLs[i]
+		if _, found := inserted[shortURL.UUID]; found {
+			delete(inserted, shortURL.UUID)
+			results[i] = models.BatchResult{Status: models.BatchCreated, ShortURL: shortURL}
+			continue
+		}
+		if shortURL.IsAlias {
+			results[i] = models.BatchResult{Status: models.BatchFailed, ShortURL: shortURL, Err: ErrAliasTaken}
+			continue
+		}
+		if stored, found := storedURLs[userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}]; found {
+			results[i] = models.BatchResult{Status: models.BatchExisted, ShortURL: stored}
+			continue
+		}
+		if attempt >= MaxIDGenerationAttempts {
+			results[i] = models.BatchResult{Status: models.BatchFailed, ShortURL: shortURL, Err: ErrIDCollision}
+			continue
+		}
+		if err := models.RegenerateID(&shortURL, attempt); err != nil {
+			results[i] = models.BatchResult{Status: models.BatchFailed, ShortURL: shortURL, Err: err}
+			continue
+		}
+		shortURLs[i] = shortURL
+		next = append(next, i)
+	}
+	return
+}
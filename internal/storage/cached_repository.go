@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// DefaultCacheSize - default max amount of short IDs kept by CachedRepository
+const DefaultCacheSize = 10000
+
+// DefaultCacheTTL - default lifetime of CachedRepository entries
+const DefaultCacheTTL = time.Minute
+
+// cacheEntry - cached FindByID result, err is set only for negatively cached unknown IDs
+type cacheEntry struct {
+	ID        string
+	shortURL  models.ShortenURL
+	err       error
+	expiresAt time.Time
+}
+
+// pendingFill - lookups of short ID which are going to fill cache, version is changed by invalidation of the ID
+type pendingFill struct {
+	lookups int
+	version uint64
+}
+
+// CachedRepository - Repository decorator which keeps FindByID results in bounded LRU cache.
+// Unknown IDs are cached too, every mutation of short URLs invalidates affected entries.
+// Cache is local to process, so entries changed by other instances are served until TTL passes
+type CachedRepository struct {
+	Repository
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu           sync.Mutex
+	entries      map[string]*list.Element
+	recent       *list.List
+	fills        map[string]*pendingFill
+	purgedBefore time.Time
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// FindByID - returns cached shortened URL or reads it from wrapped Repository on miss
+func (c *CachedRepository) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
+	c.mu.Lock()
+	if element, found := c.entries[ID]; found {
+		entry := element.Value.(*cacheEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.recent.MoveToFront(element)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.shortURL, entry.err
+		}
+		c.remove(element)
+	}
+	fill, found := c.fills[ID]
+	if !found {
+		fill = &pendingFill{}
+		c.fills[ID] = fill
+	}
+	fill.lookups++
+	version := fill.version
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	shortURL, err = c.Repository.FindByID(ctx, ID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fill.lookups--; fill.lookups == 0 {
+		delete(c.fills, ID)
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) || version != fill.version {
+		return
+	}
+	expiresAt, cacheable := c.entryExpiration(shortURL, err)
+	if !cacheable {
+		return
+	}
+	if element, found := c.entries[ID]; found {
+		c.remove(element)
+	}
+	c.entries[ID] = c.recent.PushFront(&cacheEntry{ID: ID, shortURL: shortURL, err: err, expiresAt: expiresAt})
+	if c.recent.Len() > c.size {
+		c.remove(c.recent.Back())
+	}
+	return
+}
+
+// entryExpiration - returns moment when cache entry of lookup result expires, it is not later than shortened URL expires,
+// so sweep of expired URLs finds none of them cached. Results which sweep or purge may have already changed are not cacheable,
+// mu must be held
+func (c *CachedRepository) entryExpiration(shortURL models.ShortenURL, err error) (expiresAt time.Time, cacheable bool) {
+	now := c.now()
+	expiresAt = now.Add(c.ttl)
+	if err != nil {
+		return expiresAt, true
+	}
+	if !shortURL.DeletedFlag && shortURL.IsExpired(now) || shortURL.DeletedFlag && shortURL.DeletedAt.Before(c.purgedBefore) {
+		return expiresAt, false
+	}
+	if !shortURL.ExpiresAt.IsZero() && shortURL.ExpiresAt.Before(expiresAt) {
+		expiresAt = shortURL.ExpiresAt
+	}
+	return expiresAt, true
+}
+
+// remove - drop cache entry, mu must be held
+func (c *CachedRepository) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).ID)
+}
+
+// invalidate - drop cache entries of short IDs, lookups of the IDs started before invalidation
+// do not fill cache with their possibly stale results
+func (c *CachedRepository) invalidate(IDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ID := range IDs {
+		if element, found := c.entries[ID]; found {
+			c.remove(element)
+		}
+		if fill, found := c.fills[ID]; found {
+			fill.version++
+		}
+	}
+}
+
+// invalidateMatching - drop cache entries of shortened URLs matching affected, mu must not be held
+func (c *CachedRepository) invalidateMatching(affected func(shortURL models.ShortenURL) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for element := c.recent.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*cacheEntry); entry.err == nil && affected(entry.shortURL) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Store - store shortened URL and invalidate its cached lookup
+func (c *CachedRepository) Store(ctx context.Context, shortURL *models.ShortenURL) (err error) {
+	err = c.Repository.Store(ctx, shortURL)
+	c.invalidate(shortURL.UUID)
+	return
+}
+
+// StoreBatch - store shortened URLs and invalidate their cached lookups
+func (c *CachedRepository) StoreBatch(ctx context.Context, shortURLs []models.ShortenURL) (results []models.BatchResult, err error) {
+	results, err = c.Repository.StoreBatch(ctx, shortURLs)
+	IDs := make([]string, 0, len(shortURLs)+len(results))
+	for _, shortURL := range shortURLs {
+		IDs = append(IDs, shortURL.UUID)
+	}
+	for _, result := range results {
+		IDs = append(IDs, result.ShortURL.UUID)
+	}
+	c.invalidate(IDs...)
+	return
+}
+
+// DeleteShortURLs - delete shortened URLs and invalidate their cached lookups
+func (c *CachedRepository) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
+	err = c.Repository.DeleteShortURLs(ctx, shortURLs)
+	c.invalidate(shortURLs...)
+	return
+}
+
+// RegisterClick - count click and invalidate cached lookup to keep clicks amount actual
+func (c *CachedRepository) RegisterClick(ctx context.Context, ID string) (err error) {
+	err = c.Repository.RegisterClick(ctx, ID)
+	c.invalidate(ID)
+	return
+}
+
+// DeleteExpired - delete expired shortened URLs and drop cache entries of URLs expired at the moment,
+// lookups in progress do not cache expired URLs
+func (c *CachedRepository) DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error) {
+	deleted, err = c.Repository.DeleteExpired(ctx, now)
+	if deleted > 0 {
+		c.invalidateMatching(func(shortURL models.ShortenURL) bool {
+			return !shortURL.DeletedFlag && shortURL.IsExpired(now)
+		})
+	}
+	return
+}
+
+// UpdateURL - change destination of shortened URL and invalidate its cached lookup
+func (c *CachedRepository) UpdateURL(ctx context.Context, ID string, originalURL string) (err error) {
+	err = c.Repository.UpdateURL(ctx, ID, originalURL)
+	c.invalidate(ID)
+	return
+}
+
+// RestoreShortURLs - restore deleted shortened URLs and invalidate their cached lookups
+func (c *CachedRepository) RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error) {
+	restored, err = c.Repository.RestoreShortURLs(ctx, shortURLs)
+	c.invalidate(shortURLs...)
+	return
+}
+
+// PurgeDeleted - purge deleted shortened URLs and drop cache entries of URLs deleted before the moment,
+// lookups in progress do not cache them either
+func (c *CachedRepository) PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error) {
+	purged, err = c.Repository.PurgeDeleted(ctx, before)
+	if purged > 0 {
+		c.mu.Lock()
+		if before.After(c.purgedBefore) {
+			c.purgedBefore = before
+		}
+		c.mu.Unlock()
+		c.invalidateMatching(func(shortURL models.ShortenURL) bool {
+			return shortURL.DeletedFlag && shortURL.DeletedAt.Before(before)
+		})
+	}
+	return
+}
+
+// UpsertURLs - store shortened URLs as they are and invalidate their cached lookups
+func (c *CachedRepository) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error) {
+	err = c.Repository.UpsertURLs(ctx, shortURLs)
+	IDs := make([]string, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		IDs = append(IDs, shortURL.UUID)
+	}
+	c.invalidate(IDs...)
+	return
+}
+
+// Hits - amount of FindByID calls served from cache
+func (c *CachedRepository) Hits() uint64 {
+	return c.hits.Load()
+}
+
+// Misses - amount of FindByID calls passed to wrapped Repository
+func (c *CachedRepository) Misses() uint64 {
+	return c.misses.Load()
+}
+
+// Len - amount of cached short IDs
+func (c *CachedRepository) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recent.Len()
+}
+
+// NewCachedRepository - wrap Repository with LRU cache of FindByID results keeping up to size entries for ttl
+func NewCachedRepository(repository Repository, size int, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		Repository: repository,
+		size:       size,
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+		fills:      make(map[string]*pendingFill),
+	}
+}
+
+// Ping - check connection of wrapped Repository
+func (c *CachedRepository) Ping(ctx context.Context) error {
+	return Ping(ctx, c.Repository)
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/mock"
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+func TestCachedRepository_FindByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	stored := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"}
+	rm.EXPECT().FindByID(gomock.Any(), "2187b119").Return(stored, nil).Times(2)
+	rm.EXPECT().FindByID(gomock.Any(), "unknown").Return(models.ShortenURL{}, ErrNotFound).Times(1)
+	rm.EXPECT().FindByID(gomock.Any(), "broken").Return(models.ShortenURL{}, errors.New("connection refused")).Times(2)
+
+	now := time.Now()
+	cache := NewCachedRepository(rm, 10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		found, err := cache.FindByID(context.Background(), "2187b119")
+		require.NoError(t, err)
+		assert.Equal(t, stored, found)
+		_, err = cache.FindByID(context.Background(), "unknown")
+		assert.ErrorIs(t, err, ErrNotFound, "unknown ID cached negatively")
+	}
+	for i := 0; i < 2; i++ {
+		_, err := cache.FindByID(context.Background(), "broken")
+		assert.Error(t, err, "storage failures are not cached")
+	}
+	assert.Equal(t, uint64(4), cache.Hits())
+	assert.Equal(t, uint64(4), cache.Misses())
+
+	now = now.Add(time.Minute)
+	_, err := cache.FindByID(context.Background(), "2187b119")
+	assert.NoError(t, err, "expired entry read again")
+	assert.Equal(t, uint64(5), cache.Misses())
+}
+
+func TestCachedRepository_eviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rm := mock.NewMockRepository(ctrl)
+	rm.EXPECT().FindByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ID string) (models.ShortenURL, error) {
+		return models.ShortenURL{UUID: ID}, nil
+	}).Times(4)
+
+	cache := NewCachedRepository(rm, 2, time.Minute)
+	for _, ID := range []string{"first", "second", "first", "third", "first", "second"} {
+		found, err := cache.FindByID(context.Background(), ID)
+		require.NoError(t, err)
+		assert.Equal(t, ID, found.UUID)
+	}
+	assert.Equal(t, 2, cache.Len(), "cache is bounded")
+	assert.Equal(t, uint64(2), cache.Hits(), "least recently used second evicted by third, third by second")
+}
+
+func TestCachedRepository_invalidation(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	cache := NewCachedRepository(NewMemoryStorage(), 10, time.Minute)
+
+	_, err := cache.FindByID(ctx, "2187b119")
+	require.ErrorIs(t, err, ErrNotFound)
+	shortURL := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", MaxClicks: 5}
+	require.NoError(t, cache.Store(ctx, &shortURL))
+	found, err := cache.FindByID(ctx, "2187b119")
+	require.NoError(t, err, "negative entry invalidated by Store")
+
+	require.NoError(t, cache.RegisterClick(ctx, "2187b119"))
+	found, _ = cache.FindByID(ctx, "2187b119")
+	assert.Equal(t, 1, found.Clicks, "clicks amount invalidated")
+
+	require.NoError(t, cache.UpdateURL(ctx, "2187b119", "https://practicum.yandex.kz/"))
+	found, _ = cache.FindByID(ctx, "2187b119")
+	assert.Equal(t, "https://practicum.yandex.kz/", found.OriginalURL, "destination invalidated")
+
+	require.NoError(t, cache.DeleteShortURLs(ctx, []string{"2187b119"}))
+	found, _ = cache.FindByID(ctx, "2187b119")
+	assert.True(t, found.DeletedFlag, "deletion invalidated")
+
+	_, err = cache.RestoreShortURLs(ctx, []string{"2187b119"})
+	require.NoError(t, err)
+	found, _ = cache.FindByID(ctx, "2187b119")
+	assert.False(t, found.DeletedFlag, "restoration invalidated")
+
+	_, err = cache.FindByID(ctx, "bc2c0be9")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = cache.StoreBatch(ctx, []models.ShortenURL{{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"}})
+	require.NoError(t, err)
+	_, err = cache.FindByID(ctx, "bc2c0be9")
+	assert.NoError(t, err, "negative entry invalidated by StoreBatch")
+
+	require.NoError(t, cache.DeleteShortURLs(ctx, []string{"bc2c0be9"}))
+	_, _ = cache.FindByID(ctx, "bc2c0be9")
+	purged, err := cache.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	_, err = cache.FindByID(ctx, "bc2c0be9")
+	assert.ErrorIs(t, err, ErrNotFound, "purge drops cache")
+}
+
+// blockingLookups - repository which returns FindByID results only after release is closed
+type blockingLookups struct {
+	Repository
+	started chan string
+	release chan struct{}
+}
+
+func (r *blockingLookups) FindByID(ctx context.Context, ID string) (models.ShortenURL, error) {
+	r.started <- ID
+	<-r.release
+	return r.Repository.FindByID(ctx, ID)
+}
+
+func TestCachedRepository_invalidationDuringLookup(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	ms := NewMemoryStorage()
+	_, err := ms.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"},
+	})
+	require.NoError(t, err)
+	repository := &blockingLookups{Repository: ms, started: make(chan string, 2), release: make(chan struct{})}
+	cache := NewCachedRepository(repository, 10, time.Minute)
+
+	done := make(chan struct{})
+	for _, ID := range []string{"2187b119", "bc2c0be9"} {
+		go func(ID string) {
+			_, _ = cache.FindByID(ctx, ID)
+			done <- struct{}{}
+		}(ID)
+	}
+	<-repository.started
+	<-repository.started
+	require.NoError(t, cache.UpdateURL(ctx, "bc2c0be9", "https://ya.ru/sale"))
+	close(repository.release)
+	<-done
+	<-done
+
+	assert.Equal(t, 1, cache.Len(), "only lookup of invalidated ID does not fill cache")
+	found, err := cache.FindByID(ctx, "2187b119")
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.ru/", found.OriginalURL)
+	assert.Equal(t, uint64(1), cache.Hits(), "lookup of other ID is cached")
+	repository.started = make(chan string, 1)
+	found, err = cache.FindByID(ctx, "bc2c0be9")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ya.ru/sale", found.OriginalURL, "stale destination is not cached")
+}
+
+func TestCachedRepository_sweeps(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	now := time.Now()
+	cache := NewCachedRepository(NewMemoryStorage(), 10, time.Minute)
+	cache.now = func() time.Time { return now }
+	_, err := cache.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "expiring", OriginalURL: "https://practicum.yandex.ru/", UserID: "1", ExpiresAt: now.Add(time.Second)},
+		{UUID: "once", OriginalURL: "https://practicum.yandex.kz/", UserID: "1", MaxClicks: 1},
+		{UUID: "deleted", OriginalURL: "https://ya.ru/", UserID: "1"},
+		{UUID: "active", OriginalURL: "https://ya.ru/sale", UserID: "1"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cache.DeleteShortURLs(ctx, []string{"deleted"}))
+	for _, ID := range []string{"expiring", "once", "deleted", "active"} {
+		_, err = cache.FindByID(ctx, ID)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, cache.Len())
+
+	require.NoError(t, cache.RegisterClick(ctx, "once"))
+	_, err = cache.FindByID(ctx, "once")
+	require.NoError(t, err)
+	assert.Equal(t, 3, cache.Len(), "URL without clicks left is not cached before it is swept")
+
+	now = now.Add(time.Second)
+	deleted, err := cache.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	found, err := cache.FindByID(ctx, "expiring")
+	require.NoError(t, err)
+	assert.True(t, found.DeletedFlag, "entry lives no longer than its URL")
+	found, err = cache.FindByID(ctx, "once")
+	require.NoError(t, err)
+	assert.True(t, found.DeletedFlag)
+
+	hits := cache.Hits()
+	purged, err := cache.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, purged)
+	_, err = cache.FindByID(ctx, "active")
+	require.NoError(t, err)
+	assert.Equal(t, hits+1, cache.Hits(), "sweeps keep entries of URLs they do not change")
+	_, err = cache.FindByID(ctx, "deleted")
+	assert.ErrorIs(t, err, ErrNotFound, "purged URL is not served from cache")
+}
@@ -0,0 +1,62 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/PaBah/url-shortener.git/internal/storage/storagetest"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFileStorage_contract(t *testing.T) {
+	storagetest.RunRepositoryContract(t, func(t *testing.T) storage.Repository {
+		fs, err := storage.NewInFileStorageWithOptions(filepath.Join(t.TempDir(), "store"), storage.InFileOptions{SyncPolicy: storage.SyncNever})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = fs.Close() })
+		return fs
+	})
+}
+
+func TestMemoryStorage_contract(t *testing.T) {
+	storagetest.RunRepositoryContract(t, func(t *testing.T) storage.Repository {
+		return storage.NewMemoryStorage()
+	})
+}
+
+func TestSQLiteStorage_contract(t *testing.T) {
+	storagetest.RunRepositoryContract(t, func(t *testing.T) storage.Repository {
+		ss, err := storage.NewSQLiteStorage(context.Background(), filepath.Join(t.TempDir(), "store.sqlite"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ss.Close() })
+		return ss
+	})
+}
+
+// TestDBStorage_contract - runs against disposable Postgres from TEST_DATABASE_DSN, its tables are truncated before every case.
+// CI provides the database, so the case fails there instead of being skipped
+func TestDBStorage_contract(t *testing.T) {
+	databaseDSN := os.Getenv("TEST_DATABASE_DSN")
+	if databaseDSN == "" && os.Getenv("CI") != "" {
+		t.Fatal("TEST_DATABASE_DSN is required in CI")
+	}
+	if databaseDSN == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	storagetest.RunRepositoryContract(t, func(t *testing.T) storage.Repository {
+		ds, err := storage.NewDBStorage(context.Background(), databaseDSN)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ds.Close() })
+
+		db, err := sql.Open("pgx", databaseDSN)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec(`TRUNCATE urls, clicks, url_versions; UPDATE url_counters SET value = 0`)
+		require.NoError(t, err)
+		return &ds
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/PaBah/url-shortener.git/db"
@@ -20,6 +21,12 @@ import (
 	"github.com/lib/pq"
 )
 
+// shortURLUniqueConstraint - name of unique constraint over urls.short_url
+const shortURLUniqueConstraint = "urls_short_url_key"
+
+// likeEscaper - escapes wildcards of LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // DBStorage - model of Repository storage on top of Data Base
 type DBStorage struct {
 	db *sql.DB
@@ -47,97 +54,277 @@ func (ds *DBStorage) initialize(ctx context.Context, databaseDSN string) (err er
 		return err
 	}
 
-	_ = m.Up()
+	if err = m.Up(); errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
 	return
 }
 
-// Store - stores shortened URL in DB
-func (ds *DBStorage) Store(ctx context.Context, shortURL models.ShortenURL) (err error) {
-	_, DBerr := ds.db.ExecContext(ctx,
-		`INSERT INTO urls(short_url, url, user_id) VALUES ($1, $2, $3)`, shortURL.UUID, shortURL.OriginalURL, shortURL.UserID)
+// Store - stores shortened URL in DB, regenerates its ID when it is taken by another URL
+func (ds *DBStorage) Store(ctx context.Context, shortURL *models.ShortenURL) (err error) {
+	for attempt := 1; attempt <= MaxIDGenerationAttempts; attempt++ {
+		_, err = ds.db.ExecContext(ctx,
+			`INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6)`,
+			shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.IsAlias, nullTime(shortURL.ExpiresAt), shortURL.MaxClicks)
 
-	var pgErr *pgconn.PgError
-	if errors.As(DBerr, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-		err = ErrConflict
-	}
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+			return
+		}
 
-	return
+		if pgErr.ConstraintName != shortURLUniqueConstraint {
+			_ = ds.db.QueryRowContext(ctx,
+				`SELECT short_url FROM urls WHERE url=$1 AND user_id=$2 AND NOT is_alias`, shortURL.OriginalURL, shortURL.UserID).Scan(&shortURL.UUID)
+			return ErrConflict
+		}
+		if shortURL.IsAlias {
+			return ErrAliasTaken
+		}
+		if err = models.RegenerateID(shortURL, attempt); err != nil {
+			return
+		}
+	}
+	return ErrIDCollision
 }
 
-// StoreBatch - stores batch of shortened URLs in DB
-func (ds *DBStorage) StoreBatch(ctx context.Context, shortURLsMap map[string]models.ShortenURL) (err error) {
-	rows, err := ds.db.QueryContext(ctx, `SELECT short_url FROM urls`)
+// StoreBatch - stores batch of shortened URLs in DB by multi-row inserts within one transaction,
+// returns result of every shortened URL in batch order
+func (ds *DBStorage) StoreBatch(ctx context.Context, batchURLs []models.ShortenURL) (results []models.BatchResult, err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer rows.Close()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			results = nil
+		}
+	}()
 
-	shortURLs := make([]string, 0)
-	var shortURL string
-	for rows.Next() {
-		err = rows.Scan(&shortURL)
+	shortURLs := slices.Clone(batchURLs)
+	results = make([]models.BatchResult, len(shortURLs))
+	pending := batchIndexes(len(shortURLs))
+	for attempt := 1; len(pending) > 0; attempt++ {
+		var inserted map[string]struct{}
+		inserted, err = ds.insertBatch(ctx, tx, pending, shortURLs)
 		if err != nil {
-			return err
+			return
 		}
-		shortURLs = append(shortURLs, shortURL)
+		var storedURLs map[userURL]models.ShortenURL
+		storedURLs, err = ds.findUserURLs(ctx, tx, batchUserURLs(conflictedIndexes(pending, shortURLs, inserted), shortURLs))
+		if err != nil {
+			return
+		}
+		pending = resolveBatch(pending, shortURLs, inserted, storedURLs, attempt, results)
+	}
+	err = tx.Commit()
+	return
+}
+
+// insertBatch - insert shortened URLs of batch indexes skipping conflicting ones, returns short IDs of inserted URLs
+func (ds *DBStorage) insertBatch(ctx context.Context, tx *sql.Tx, indexes []int, shortURLs []models.ShortenURL) (inserted map[string]struct{}, err error) {
+	inserted = make(map[string]struct{}, len(indexes))
+	for start := 0; start < len(indexes); start += MaxBatchInsertRows {
+		chunk := indexes[start:min(start+MaxBatchInsertRows, len(indexes))]
+		values := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*6)
+		for _, i := range chunk {
+			shortURL := shortURLs[i]
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+			args = append(args, shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.IsAlias, nullTime(shortURL.ExpiresAt), shortURL.MaxClicks)
+		}
+
+		var rows *sql.Rows
+		rows, err = tx.QueryContext(ctx, `INSERT INTO urls (short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES `+
+			strings.Join(values, ", ")+` ON CONFLICT DO NOTHING RETURNING short_url`, args...)
+		if err != nil {
+			return
+		}
+		err = scanShortIDs(rows, inserted)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// findUserURLs - returns stored not alias shortened URLs by user ID and original URL pairs
+func (ds *DBStorage) findUserURLs(ctx context.Context, tx *sql.Tx, pairs []userURL) (storedURLs map[userURL]models.ShortenURL, err error) {
+	storedURLs = make(map[userURL]models.ShortenURL, len(pairs))
+	if len(pairs) == 0 {
+		return
+	}
+	originalURLs, userIDs := make([]string, 0, len(pairs)), make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		originalURLs, userIDs = append(originalURLs, pair.originalURL), append(userIDs, pair.userID)
 	}
-	err = rows.Err()
 
-	tx, err := ds.db.Begin()
+	rows, err := tx.QueryContext(ctx,
+		`SELECT short_url, url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls
+			WHERE NOT is_alias AND (url, user_id) IN (SELECT * FROM unnest($1::text[], $2::uuid[]))`,
+		pq.Array(originalURLs), pq.Array(userIDs))
 	if err != nil {
-		return err
+		return
 	}
-	for _, shortURL := range shortURLsMap {
-		if !slices.Contains(shortURLs, shortURL.UUID) {
-			shortURLs = append(shortURLs, shortURL.UUID)
-			_, err = tx.ExecContext(ctx,
-				"INSERT INTO urls (short_url, url, user_id) VALUES($1, $2, $3)", shortURL.UUID, shortURL.OriginalURL, shortURL.UserID)
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) {
-				_ = tx.Rollback()
-				return
-			}
+	defer rows.Close()
+	for rows.Next() {
+		var shortURL models.ShortenURL
+		var expiresAt sql.NullTime
+		err = rows.Scan(&shortURL.UUID, &shortURL.OriginalURL, &shortURL.UserID, &shortURL.DeletedFlag, &expiresAt, &shortURL.MaxClicks, &shortURL.Clicks)
+		if err != nil {
+			return
 		}
+		shortURL.ExpiresAt = expiresAt.Time
+		storedURLs[userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}] = shortURL
 	}
-	return tx.Commit()
+	return storedURLs, rows.Err()
 }
 
 // FindByID - filter and returns shortened URL by short ID
 func (ds *DBStorage) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
-	row := ds.db.QueryRowContext(ctx, `SELECT url, user_id, is_deleted FROM urls WHERE short_url=$1`, ID)
+	row := ds.db.QueryRowContext(ctx,
+		`SELECT url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls WHERE short_url=$1`, ID)
 	var URL string
 	var userID string
 	var deletedFlag bool
-	err = row.Scan(&URL, &userID, &deletedFlag)
-
+	var expiresAt sql.NullTime
+	var maxClicks, clicks int
+	err = row.Scan(&URL, &userID, &deletedFlag, &expiresAt, &maxClicks, &clicks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return shortURL, ErrNotFound
+	}
 	if err != nil {
 		return
 	}
 
-	shortURL = models.ShortenURL{OriginalURL: URL, UUID: ID, UserID: userID, DeletedFlag: deletedFlag}
+	shortURL = models.ShortenURL{
+		OriginalURL: URL,
+		UUID:        ID,
+		UserID:      userID,
+		DeletedFlag: deletedFlag,
+		ExpiresAt:   expiresAt.Time,
+		MaxClicks:   maxClicks,
+		Clicks:      clicks,
+	}
 	return
 }
 
-// GetAllUsers - returns all shortened URLs of the User from context
-func (ds *DBStorage) GetAllUsers(ctx context.Context) (shortURLs []models.ShortenURL, err error) {
+// ListUserURLs - returns page of shortened URLs of the User from context, filtering, sorting and pagination are done by DB
+func (ds *DBStorage) ListUserURLs(ctx context.Context, options models.ListOptions) (page models.URLsPage, err error) {
+	options, err = options.Normalize()
+	if err != nil {
+		return
+	}
+	cursor, err := options.ParseCursor()
+	if err != nil {
+		return
+	}
+
+	query := `SELECT ` + urlColumns + ` FROM urls WHERE user_id=$1`
+	args := []interface{}{ctx.Value(auth.ContextUserKey).(string)}
+	switch options.Status {
+	case models.StatusActive:
+		query += ` AND NOT is_deleted`
+	case models.StatusDeleted:
+		query += ` AND is_deleted`
+	}
+	if options.Search != "" {
+		args = append(args, "%"+likeEscaper.Replace(options.Search)+"%")
+		query += fmt.Sprintf(` AND (url ILIKE $%d OR short_url ILIKE $%d)`, len(args), len(args))
+	}
+
+	column, direction, comparison := "created_at", "ASC", ">"
+	if options.SortBy == models.SortByOriginalURL {
+		column = "url"
+	}
+	if options.Desc {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor != nil {
+		var key interface{} = cursor.CreatedAt
+		if options.SortBy == models.SortByOriginalURL {
+			key = cursor.URL
+		}
+		args = append(args, key, cursor.ID)
+		query += fmt.Sprintf(` AND (%s, short_url) %s ($%d, $%d)`, column, comparison, len(args)-1, len(args))
+	}
+	args = append(args, options.Limit+1)
+	query += fmt.Sprintf(` ORDER BY %s %s, short_url %s LIMIT $%d`, column, direction, direction, len(args))
+
 	var rows *sql.Rows
-	rows, err = ds.db.QueryContext(ctx, `SELECT url, short_url, user_id FROM urls WHERE user_id=$1`, ctx.Value(auth.ContextUserKey).(string))
+	rows, err = ds.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return
 	}
-	err = rows.Err()
 	defer rows.Close()
 
-	shortURLs = make([]models.ShortenURL, 0)
+	shortURLs := make([]models.ShortenURL, 0)
 	for rows.Next() {
 		var shortURL models.ShortenURL
-		err = rows.Scan(&shortURL.OriginalURL, &shortURL.UUID, &shortURL.UserID)
-		if err != nil {
-			return nil, err
+		if shortURL, err = scanURL(rows); err != nil {
+			return
 		}
 		shortURLs = append(shortURLs, shortURL)
 	}
-	return
+	if err = rows.Err(); err != nil {
+		return
+	}
+	return newURLsPage(shortURLs, options), nil
+}
+
+// ScanURLs - calls scan for every shortened URL of all users ordered by short ID, rows are streamed by one query,
+// so they are read from one consistent snapshot of DB
+func (ds *DBStorage) ScanURLs(ctx context.Context, scan func(shortURL models.ShortenURL) error) (err error) {
+	rows, err := ds.db.QueryContext(ctx, `SELECT `+urlColumns+` FROM urls ORDER BY short_url`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL models.ShortenURL
+		if shortURL, err = scanURL(rows); err != nil {
+			return
+		}
+		if err = scan(shortURL); err != nil {
+			return
+		}
+	}
+	return rows.Err()
+}
+
+// UpsertURLs - store shortened URLs as they are with clicks and deletion in one transaction, stored URLs with the same short ID are replaced
+func (ds *DBStorage) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for start := 0; start < len(shortURLs); start += MaxBatchInsertRows {
+		chunk := shortURLs[start:min(start+MaxBatchInsertRows, len(shortURLs))]
+		values := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*10)
+		for _, shortURL := range chunk {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10))
+			args = append(args, shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.DeletedFlag, shortURL.IsAlias,
+				nullTime(shortURL.ExpiresAt), shortURL.MaxClicks, shortURL.Clicks, createdAt(shortURL), nullTime(shortURL.DeletedAt))
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO urls (short_url, url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at)
+			VALUES `+strings.Join(values, ", ")+` ON CONFLICT (short_url) DO UPDATE SET
+			url=EXCLUDED.url, user_id=EXCLUDED.user_id, is_deleted=EXCLUDED.is_deleted, is_alias=EXCLUDED.is_alias,
+			expires_at=EXCLUDED.expires_at, max_clicks=EXCLUDED.max_clicks, clicks=EXCLUDED.clicks, created_at=EXCLUDED.created_at, deleted_at=EXCLUDED.deleted_at`, args...)
+		if err != nil {
+			return
+		}
+	}
+	return tx.Commit()
 }
 
 // AsyncCheckURLsUserID - async checking if URL belongs to the User from context
@@ -164,18 +351,257 @@ func (ds *DBStorage) AsyncCheckURLsUserID(userID string, shortURLCh chan string)
 
 // DeleteShortURLs - delete shortened URLs from Data Base
 func (ds *DBStorage) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
-	_, err = ds.db.ExecContext(ctx, `UPDATE urls SET is_deleted = TRUE WHERE urls.short_url = ANY($1)`, pq.Array(shortURLs))
+	_, err = ds.db.ExecContext(ctx,
+		`UPDATE urls SET is_deleted = TRUE, deleted_at = now() WHERE urls.short_url = ANY($1) AND NOT is_deleted`, pq.Array(shortURLs))
 
 	return
 }
 
-// GetStats - return amount of users and amount of url in the system
-func (ds *DBStorage) GetStats(ctx context.Context) (urls int, users int, err error) {
-	err = ds.db.QueryRowContext(ctx, `SELECT COUNT(id), COUNT(DISTINCT user_id) FROM urls`).Scan(&urls, &users)
+// GetStats - return amounts of users, active, deleted and purged urls in the system
+func (ds *DBStorage) GetStats(ctx context.Context) (stats models.Stats, err error) {
+	err = ds.db.QueryRowContext(ctx,
+		`SELECT COUNT(id), COUNT(DISTINCT user_id), COUNT(id) FILTER (WHERE is_deleted),
+			COALESCE((SELECT value FROM url_counters WHERE name = 'purged'), 0) FROM urls`).
+		Scan(&stats.URLs, &stats.Users, &stats.Deleted, &stats.Purged)
+	stats.Active = stats.URLs - stats.Deleted
 
 	return
 }
 
+// RestoreShortURLs - undo soft deletion of not expired shortened URLs of the User from context
+func (ds *DBStorage) RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error) {
+	rows, err := ds.db.QueryContext(ctx,
+		`UPDATE urls SET is_deleted = FALSE, deleted_at = NULL WHERE short_url = ANY($1) AND user_id = $2 AND is_deleted
+			AND (expires_at IS NULL OR expires_at > now()) AND (max_clicks = 0 OR clicks < max_clicks) RETURNING short_url`,
+		pq.Array(shortURLs), ctx.Value(auth.ContextUserKey).(string))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	restored = make([]string, 0)
+	for rows.Next() {
+		var shortURL string
+		if err = rows.Scan(&shortURL); err != nil {
+			return nil, err
+		}
+		restored = append(restored, shortURL)
+	}
+	return restored, rows.Err()
+}
+
+// MigrationStatus - return applied and the newest known versions of DB schema migrations
+func (ds *DBStorage) MigrationStatus(ctx context.Context) (status models.MigrationStatus, err error) {
+	return migrationStatus(ctx, ds.db, db.MigrationsFS, "migrations")
+}
+
+// PurgeDeleted - permanently remove shortened URLs soft deleted before the moment with their clicks and history
+func (ds *DBStorage) PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM urls WHERE is_deleted AND deleted_at < $1 RETURNING short_url`, before)
+	if err != nil {
+		return
+	}
+	shortURLs := make([]string, 0)
+	for rows.Next() {
+		var shortURL string
+		if err = rows.Scan(&shortURL); err != nil {
+			_ = rows.Close()
+			return
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+	if len(shortURLs) == 0 {
+		return 0, tx.Commit()
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM clicks WHERE short_url = ANY($1)`, pq.Array(shortURLs)); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM url_versions WHERE short_url = ANY($1)`, pq.Array(shortURLs)); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE url_counters SET value = value + $1 WHERE name = 'purged'`, len(shortURLs)); err != nil {
+		return
+	}
+	return len(shortURLs), tx.Commit()
+}
+
+// RegisterClick - count click on shortened URL, fails with ErrLinkExpired when URL is expired and ErrNotFound when it does not exist
+func (ds *DBStorage) RegisterClick(ctx context.Context, ID string) (err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`UPDATE urls SET clicks = clicks + 1 WHERE short_url = $1
+			AND (expires_at IS NULL OR expires_at > NOW()) AND (max_clicks = 0 OR clicks < max_clicks)`, ID)
+	if err != nil {
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected > 0 {
+		return
+	}
+	var exists bool
+	if err = ds.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM urls WHERE short_url = $1)`, ID).Scan(&exists); err != nil {
+		return
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrLinkExpired
+}
+
+// DeleteExpired - mark as deleted shortened URLs expired at the moment
+func (ds *DBStorage) DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`UPDATE urls SET is_deleted = TRUE, deleted_at = $1 WHERE NOT is_deleted
+			AND ((expires_at IS NOT NULL AND expires_at <= $1) OR (max_clicks > 0 AND clicks >= max_clicks))`, now)
+	if err != nil {
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// StoreClicks - stores batch of clicks in DB
+func (ds *DBStorage) StoreClicks(ctx context.Context, clicks []models.Click) (err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, click := range clicks {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO clicks (short_url, clicked_at, referrer, user_agent, ip) VALUES($1, $2, $3, $4, $5)`,
+			click.ShortURL, click.Timestamp, click.Referrer, click.UserAgent, click.IP)
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+// GetClickStats - returns per day clicks aggregates of shortened URL
+func (ds *DBStorage) GetClickStats(ctx context.Context, shortURL string) (stats []models.ClickStats, err error) {
+	rows, err := ds.db.QueryContext(ctx,
+		`SELECT (clicked_at AT TIME ZONE 'UTC')::date AS day, COUNT(*), COUNT(DISTINCT ip) FROM clicks
+			WHERE short_url=$1 GROUP BY day ORDER BY day`, shortURL)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	stats = make([]models.ClickStats, 0)
+	for rows.Next() {
+		var dayStats models.ClickStats
+		if err = rows.Scan(&dayStats.Day, &dayStats.Clicks, &dayStats.Visitors); err != nil {
+			return nil, err
+		}
+		stats = append(stats, dayStats)
+	}
+	return stats, rows.Err()
+}
+
+// CountClicks - returns amounts of stored clicks of shortened URLs, URLs without clicks are absent
+func (ds *DBStorage) CountClicks(ctx context.Context, shortURLs []string) (counts map[string]int, err error) {
+	counts = make(map[string]int)
+	if len(shortURLs) == 0 {
+		return
+	}
+	rows, err := ds.db.QueryContext(ctx,
+		`SELECT short_url, COUNT(*) FROM clicks WHERE short_url = ANY($1) GROUP BY short_url`, pq.Array(shortURLs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL string
+		var clicks int
+		if err = rows.Scan(&shortURL, &clicks); err != nil {
+			return nil, err
+		}
+		counts[shortURL] = clicks
+	}
+	return counts, rows.Err()
+}
+
+// UpdateURL - replace original URL of the User's shortened URL, previous one is kept in url_versions
+func (ds *DBStorage) UpdateURL(ctx context.Context, ID string, originalURL string) (err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var currentURL, userID string
+	var deletedFlag bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT url, user_id, is_deleted FROM urls WHERE short_url=$1 FOR UPDATE`, ID).Scan(&currentURL, &userID, &deletedFlag)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && (deletedFlag || userID != ctx.Value(auth.ContextUserKey).(string)) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	if currentURL == originalURL {
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO url_versions (short_url, version, url)
+			SELECT $1, COALESCE(MAX(version), 0) + 1, $2 FROM url_versions WHERE short_url=$1`, ID, currentURL)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE urls SET url=$1 WHERE short_url=$2`, originalURL, ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrConflict
+	}
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+// GetURLHistory - returns previous original URLs of shortened URL from oldest to newest
+func (ds *DBStorage) GetURLHistory(ctx context.Context, ID string) (versions []models.URLVersion, err error) {
+	rows, err := ds.db.QueryContext(ctx,
+		`SELECT version, url, replaced_at FROM url_versions WHERE short_url=$1 ORDER BY version`, ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	versions = make([]models.URLVersion, 0)
+	for rows.Next() {
+		version := models.URLVersion{ShortURL: ID}
+		if err = rows.Scan(&version.Version, &version.OriginalURL, &version.ReplacedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
 // Ping - check if connection to Data Base is fine
 func (ds *DBStorage) Ping(ctx context.Context) error {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 1*time.Second)
@@ -186,6 +612,9 @@ func (ds *DBStorage) Ping(ctx context.Context) error {
 
 // Close - close connection to Data Base
 func (ds *DBStorage) Close() error {
+	if ds.db == nil {
+		return nil
+	}
 	return ds.db.Close()
 }
 
@@ -195,3 +624,7 @@ func NewDBStorage(ctx context.Context, databaseDSN string) (DBStorage, error) {
 	err := store.initialize(ctx, databaseDSN)
 	return store, err
 }
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
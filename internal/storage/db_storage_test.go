@@ -2,14 +2,19 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDBStorage_Close(t *testing.T) {
@@ -26,10 +31,10 @@ func TestDBStorage_FindByID(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, user_id, is_deleted FROM urls WHERE short_url=$1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls WHERE short_url=$1")).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"short_url", "user_id", "is_deleted"}).
-			AddRow("test", 1, false))
+		WillReturnRows(sqlmock.NewRows([]string{"short_url", "user_id", "is_deleted", "expires_at", "max_clicks", "clicks"}).
+			AddRow("test", 1, false, nil, 0, 0))
 
 	Data, err := ds.FindByID(context.Background(), "test")
 	assert.NoError(t, err)
@@ -68,8 +73,8 @@ func TestDBStorage_Store(t *testing.T) {
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url) VALUES ($1, $2)")).
 		WithArgs("bc2c0be9", "test").WillReturnResult(sqlmock.NewResult(1, 1))
 
-	shortURL := models.NewShortURL("test", "1")
-	_ = ds.Store(context.Background(), shortURL)
+	shortURL := newTestShortURL(t, "test", "1")
+	_ = ds.Store(context.Background(), &shortURL)
 	assert.Equal(t, "bc2c0be9", shortURL.UUID, "Found message scanned correctly")
 }
 
@@ -78,18 +83,56 @@ func TestDBStorage_Store_with_error(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url, user_id) VALUES ($1, $2, $3)")).
-		WithArgs("bc2c0be9", "test", "1").WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WithArgs("bc2c0be9", "test", "1", false, sql.NullTime{}, 0).WillReturnError(&pgconn.PgError{Code: "23505"})
 
-	shortURL := models.NewShortURL("test", "1")
+	shortURL := newTestShortURL(t, "test", "1")
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, 1)
-	err := ds.Store(ctx, shortURL)
+	err := ds.Store(ctx, &shortURL)
 	assert.Error(t, err, "duplicate")
 }
 
+func TestDBStorage_Store_collision(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	shortURL := newTestShortURL(t, "test", "1")
+	regeneratedID, err := models.HashIDGenerator{}.Generate("test", "1", 1)
+	require.NoError(t, err)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WithArgs("bc2c0be9", "test", "1", false, sql.NullTime{}, 0).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: shortURLUniqueConstraint})
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WithArgs(regeneratedID, "test", "1", false, sql.NullTime{}, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = ds.Store(context.Background(), &shortURL)
+	assert.NoError(t, err, "collision is resolved by ID regeneration")
+	assert.Equal(t, regeneratedID, shortURL.UUID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_Store_conflict(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	shortURL := models.ShortenURL{UUID: "random01", OriginalURL: "test", UserID: "1"}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6)")).
+		WithArgs("random01", "test", "1", false, sql.NullTime{}, 0).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "urls_url_user_id_key"})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url FROM urls WHERE url=$1 AND user_id=$2 AND NOT is_alias")).
+		WithArgs("test", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).AddRow("bc2c0be9"))
+
+	err := ds.Store(context.Background(), &shortURL)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "bc2c0be9", shortURL.UUID, "conflict returns stored ID")
+}
+
 func TestNewDBStorage(t *testing.T) {
-	_, err := NewDBStorage(context.Background(), "test")
+	store, err := NewDBStorage(context.Background(), "test")
 	assert.Error(t, err, "Don't not initialize DB storage with incorrect DSN")
+	assert.NoError(t, store.Close(), "partly opened storage is closed")
+	assert.NoError(t, (&DBStorage{}).Close(), "not opened storage is closed")
 }
 
 func TestDBStorage_StoreBatch(t *testing.T) {
@@ -97,18 +140,27 @@ func TestDBStorage_StoreBatch(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url FROM urls")).
-		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).
-			AddRow("test"))
-
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls (short_url, url, user_id) VALUES($1, $2, $3)")).
-		WithArgs("bc2c0be9", "test", "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls (short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6), ($7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING RETURNING short_url")).
+		WithArgs("bc2c0be9", "test", "1", false, sql.NullTime{}, 0, "taken", "https://ya.ru/", "1", false, sql.NullTime{}, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).AddRow("bc2c0be9"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url, url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"short_url", "url", "user_id", "is_deleted", "expires_at", "max_clicks", "clicks"}).
+			AddRow("stored12", "https://ya.ru/", "1", false, nil, 0, 0))
 	mock.ExpectCommit()
-	shortURLs := map[string]models.ShortenURL{"test1": models.NewShortURL("test", "1")}
+	shortURLs := []models.ShortenURL{
+		newTestShortURL(t, "test", "1"),
+		{UUID: "taken", OriginalURL: "https://ya.ru/", UserID: "1"},
+	}
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, 1)
-	err := ds.StoreBatch(ctx, shortURLs)
+	results, err := ds.StoreBatch(ctx, shortURLs)
 	assert.NoError(t, err, "Batch value insertion not failed")
+	assert.Equal(t, []models.BatchResult{
+		{Status: models.BatchCreated, ShortURL: newTestShortURL(t, "test", "1")},
+		{Status: models.BatchExisted, ShortURL: models.ShortenURL{UUID: "stored12", OriginalURL: "https://ya.ru/", UserID: "1"}},
+	}, results, "conflicting URL resolved to stored one")
+	assert.NoError(t, mock.ExpectationsWereMet(), "batch inserted by one statement")
 }
 
 func TestDBStorage_StoreBatch_with_error(t *testing.T) {
@@ -116,12 +168,11 @@ func TestDBStorage_StoreBatch_with_error(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url FROM urls")).
-		WillReturnError(&pgconn.PgError{Code: "777"})
+	mock.ExpectBegin().WillReturnError(&pgconn.PgError{Code: "777"})
 
-	shortURLs := map[string]models.ShortenURL{"test1": models.NewShortURL("test", "1")}
+	shortURLs := []models.ShortenURL{newTestShortURL(t, "test", "1")}
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, 1)
-	err := ds.StoreBatch(ctx, shortURLs)
+	_, err := ds.StoreBatch(ctx, shortURLs)
 	assert.Error(t, err, "Batch value insertion failed")
 }
 
@@ -130,34 +181,89 @@ func TestDBStorage_StoreBatch_parse_error(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url FROM urls")).
-		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).
-			AddRow("test"))
-
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls (short_url, url) VALUES($1, $2)")).
-		WithArgs("bc2c0be9", "test").WillReturnError(&pgconn.PgError{Code: "777"})
-	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls (short_url, url, user_id, is_alias, expires_at, max_clicks) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING RETURNING short_url")).
+		WithArgs("bc2c0be9", "test", "1", false, sql.NullTime{}, 0).WillReturnError(&pgconn.PgError{Code: "777"})
+	mock.ExpectRollback()
 
-	shortURLs := map[string]models.ShortenURL{"test1": models.NewShortURL("test", "1")}
+	shortURLs := []models.ShortenURL{newTestShortURL(t, "test", "1")}
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, 1)
-	err := ds.StoreBatch(ctx, shortURLs)
+	results, err := ds.StoreBatch(ctx, shortURLs)
 	assert.Error(t, err, "Batch value insertion failed")
+	assert.Nil(t, results)
+	assert.NoError(t, mock.ExpectationsWereMet(), "transaction rolled back")
 }
 
-func TestDBStorage_GetAllUsers(t *testing.T) {
+func TestDBStorage_ListUserURLs(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, short_url, user_id FROM urls WHERE user_id=$1")).
-		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"url", "short_url", "user_id"}).
-			AddRow("url", "test", "test"))
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, short_url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at FROM urls WHERE user_id=$1 ORDER BY created_at ASC, short_url ASC LIMIT $2")).
+		WithArgs("test", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "short_url", "user_id", "is_deleted", "is_alias", "expires_at", "max_clicks", "clicks", "created_at", "deleted_at"}).
+			AddRow("url", "test", "test", false, false, nil, 5, 2, createdAt, nil).
+			AddRow("url2", "test2", "test", false, false, nil, 0, 0, createdAt, nil))
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "test")
-	Data, err := ds.GetAllUsers(ctx)
+	page, err := ds.ListUserURLs(ctx, models.ListOptions{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ShortenURL{{UUID: "test", OriginalURL: "url", UserID: "test", MaxClicks: 5, Clicks: 2, CreatedAt: createdAt}}, page.URLs, "Found message scanned correctly")
+	assert.NotEmpty(t, page.NextCursor, "Extra row means next page exists")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, short_url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at FROM urls WHERE user_id=$1 AND NOT is_deleted AND (url ILIKE $2 OR short_url ILIKE $2) AND (url, short_url) < ($3, $4) ORDER BY url DESC, short_url DESC LIMIT $5")).
+		WithArgs("test", `%50\%%`, "url", "test", 11).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "short_url", "user_id", "is_deleted", "is_alias", "expires_at", "max_clicks", "clicks", "created_at", "deleted_at"}))
+	cursor := models.NewListCursor(models.SortByOriginalURL, models.ShortenURL{UUID: "test", OriginalURL: "url"})
+	page, err = ds.ListUserURLs(ctx, models.ListOptions{Limit: 10, Cursor: cursor, SortBy: models.SortByOriginalURL, Desc: true, Status: models.StatusActive, Search: "50%"})
+	assert.NoError(t, err)
+	assert.Empty(t, page.URLs)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet(), "Filtering, sorting and cursor pushed down to SQL")
+}
+
+func TestDBStorage_ScanURLs(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, short_url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at FROM urls ORDER BY short_url")).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "short_url", "user_id", "is_deleted", "is_alias", "expires_at", "max_clicks", "clicks", "created_at", "deleted_at"}).
+			AddRow("url", "test", "test", true, false, nil, 0, 4, createdAt, createdAt).
+			AddRow("url2", "test2", "test", false, false, nil, 0, 0, createdAt, nil))
+
+	var scanned []models.ShortenURL
+	err := ds.ScanURLs(context.Background(), func(shortURL models.ShortenURL) error {
+		scanned = append(scanned, shortURL)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, scanned, 2)
+	assert.Equal(t, models.ShortenURL{UUID: "test", OriginalURL: "url", UserID: "test", DeletedFlag: true, Clicks: 4, CreatedAt: createdAt, DeletedAt: createdAt}, scanned[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_UpsertURLs(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls (short_url, url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at)")).
+		WithArgs("test", "url", "test", true, false, sqlmock.AnyArg(), 0, 4, createdAt, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	err := ds.UpsertURLs(context.Background(), []models.ShortenURL{{UUID: "test", OriginalURL: "url", UserID: "test", DeletedFlag: true, Clicks: 4, CreatedAt: createdAt, DeletedAt: createdAt}})
 	assert.NoError(t, err)
-	assert.Equal(t, []models.ShortenURL{{UUID: "test", OriginalURL: "url", UserID: "test"}}, Data, "Found message scanned correctly")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO urls")).WillReturnError(errors.New("invalid input syntax for type uuid"))
+	mock.ExpectRollback()
+	err = ds.UpsertURLs(context.Background(), []models.ShortenURL{{UUID: "test", OriginalURL: "url", UserID: "test"}})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet(), "transaction rolled back")
 }
 
 func TestDBStorage_AsyncCheckURLsUserID(t *testing.T) {
@@ -165,10 +271,10 @@ func TestDBStorage_AsyncCheckURLsUserID(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, user_id, is_deleted FROM urls WHERE short_url=$1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls WHERE short_url=$1")).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"url", "user_id", "is_deleted"}).
-			AddRow("url", "test", false))
+		WillReturnRows(sqlmock.NewRows([]string{"url", "user_id", "is_deleted", "expires_at", "max_clicks", "clicks"}).
+			AddRow("url", "test", false, nil, 0, 0))
 	shortURLCh := make(chan string)
 	res := ds.AsyncCheckURLsUserID("test", shortURLCh)
 	shortURLCh <- "test"
@@ -180,10 +286,236 @@ func TestDBStorage_DeleteShortURLs(t *testing.T) {
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET is_deleted = TRUE WHERE urls.short_url = ANY($1)`)).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET is_deleted = TRUE, deleted_at = now() WHERE urls.short_url = ANY($1) AND NOT is_deleted`)).
 		WithArgs(sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1)).
 		WillReturnError(nil)
 	err := ds.DeleteShortURLs(context.Background(), []string{"test"})
 	assert.NoError(t, err, "successfully deleted urls")
 }
+
+func TestDBStorage_RegisterClick(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET clicks = clicks + 1 WHERE short_url = $1`)).
+		WithArgs("test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET clicks = clicks + 1 WHERE short_url = $1`)).
+		WithArgs("test").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM urls WHERE short_url = $1)`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET clicks = clicks + 1 WHERE short_url = $1`)).
+		WithArgs("unknown").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM urls WHERE short_url = $1)`)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	assert.NoError(t, ds.RegisterClick(context.Background(), "test"), "click registered")
+	assert.ErrorIs(t, ds.RegisterClick(context.Background(), "test"), ErrLinkExpired, "no clicks left")
+	assert.ErrorIs(t, ds.RegisterClick(context.Background(), "unknown"), ErrNotFound)
+}
+
+func TestDBStorage_DeleteExpired(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	now := time.Now()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET is_deleted = TRUE, deleted_at = $1 WHERE NOT is_deleted`)).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := ds.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted, "expired URLs deleted")
+}
+
+func TestDBStorage_StoreClicks(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	click := models.NewClick("test", "https://ya.ru/", "curl/8.0", "10.0.0.7")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO clicks (short_url, clicked_at, referrer, user_agent, ip) VALUES($1, $2, $3, $4, $5)")).
+		WithArgs("test", click.Timestamp, "https://ya.ru/", "curl/8.0", "10.0.0.0").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := ds.StoreClicks(context.Background(), []models.Click{click})
+	assert.NoError(t, err, "clicks stored")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_GetClickStats(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT (clicked_at AT TIME ZONE 'UTC')::date AS day, COUNT(*), COUNT(DISTINCT ip) FROM clicks")).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count", "visitors"}).AddRow(day, 3, 2))
+
+	stats, err := ds.GetClickStats(context.Background(), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ClickStats{{Day: day, Clicks: 3, Visitors: 2}}, stats)
+}
+
+func TestDBStorage_CountClicks(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT short_url, COUNT(*) FROM clicks WHERE short_url = ANY($1) GROUP BY short_url")).
+		WithArgs(pq.Array([]string{"test", "none"})).
+		WillReturnRows(sqlmock.NewRows([]string{"short_url", "count"}).AddRow("test", 3))
+
+	counts, err := ds.CountClicks(context.Background(), []string{"test", "none"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"test": 3}, counts)
+}
+
+func TestDBStorage_UpdateURL(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	selectQuery := regexp.QuoteMeta("SELECT url, user_id, is_deleted FROM urls WHERE short_url=$1 FOR UPDATE")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"url", "user_id", "is_deleted"}).AddRow("https://ya.ru/", "1", false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO url_versions (short_url, version, url)")).
+		WithArgs("test", "https://ya.ru/").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE urls SET url=$1 WHERE short_url=$2")).
+		WithArgs("https://practicum.yandex.kz/", "test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, ds.UpdateURL(ctx, "test", "https://practicum.yandex.kz/"), "previous URL kept in history")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"url", "user_id", "is_deleted"}).AddRow("https://ya.ru/", "1", false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO url_versions (short_url, version, url)")).
+		WithArgs("test", "https://ya.ru/").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE urls SET url=$1 WHERE short_url=$2")).
+		WithArgs("https://practicum.yandex.kz/", "test").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "urls_url_user_id_key"})
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ds.UpdateURL(ctx, "test", "https://practicum.yandex.kz/"), ErrConflict, "user already shortened URL")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"url", "user_id", "is_deleted"}).AddRow("https://ya.ru/", "2", false))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ds.UpdateURL(ctx, "test", "https://practicum.yandex.kz/"), ErrNotFound, "URL of another user")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ds.UpdateURL(ctx, "unknown", "https://practicum.yandex.kz/"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_GetURLHistory(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	replacedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, url, replaced_at FROM url_versions WHERE short_url=$1 ORDER BY version")).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "url", "replaced_at"}).AddRow(1, "https://ya.ru/", replacedAt))
+
+	versions, err := ds.GetURLHistory(context.Background(), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.URLVersion{{ShortURL: "test", Version: 1, OriginalURL: "https://ya.ru/", ReplacedAt: replacedAt}}, versions)
+}
+
+func TestDBStorage_GetStats(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id), COUNT(DISTINCT user_id), COUNT(id) FILTER (WHERE is_deleted)")).
+		WillReturnRows(sqlmock.NewRows([]string{"urls", "users", "deleted", "purged"}).AddRow(5, 2, 1, 3))
+
+	stats, err := ds.GetStats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, models.Stats{URLs: 5, Users: 2, Active: 4, Deleted: 1, Purged: 3}, stats)
+}
+
+func TestDBStorage_MigrationStatus(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(8, false))
+
+	status, err := ds.MigrationStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, models.MigrationStatus{Version: 8, Latest: 10, Pending: 2}, status)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}))
+	status, err = ds.MigrationStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, models.MigrationStatus{Latest: 10, Pending: 10}, status, "no migration is applied")
+}
+
+func TestDBStorage_RestoreShortURLs(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE urls SET is_deleted = FALSE, deleted_at = NULL WHERE short_url = ANY($1) AND user_id = $2 AND is_deleted")).
+		WithArgs(sqlmock.AnyArg(), "1").
+		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).AddRow("test"))
+
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	restored, err := ds.RestoreShortURLs(ctx, []string{"test", "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, restored)
+}
+
+func TestDBStorage_PurgeDeleted(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	before := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM urls WHERE is_deleted AND deleted_at < $1 RETURNING short_url")).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"short_url"}).AddRow("test").AddRow("test2"))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM clicks WHERE short_url = ANY($1)")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM url_versions WHERE short_url = ANY($1)")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE url_counters SET value = value + $1 WHERE name = 'purged'")).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	purged, err := ds.PurgeDeleted(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged, "deleted URLs purged with clicks and history")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
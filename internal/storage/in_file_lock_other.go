@@ -0,0 +1,10 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// lockFile - file locks are not supported on the platform, so the file is never locked
+func lockFile(file *os.File) error {
+	return nil
+}
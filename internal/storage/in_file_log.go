@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/logger"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+// Policies of InFileStorage log fsync
+const (
+	// SyncAlways - fsync log after every mutation
+	SyncAlways = "always"
+	// SyncInterval - fsync log in background every InFileOptions.SyncInterval
+	SyncInterval = "interval"
+	// SyncNever - leave flushing of log to operating system
+	SyncNever = "never"
+)
+
+// Default durability options of InFileStorage
+const (
+	// DefaultSyncInterval - default period of background log fsync
+	DefaultSyncInterval = time.Second
+	// DefaultCompactInterval - default min period between log compactions
+	DefaultCompactInterval = 10 * time.Minute
+)
+
+// ErrUnknownSyncPolicy - error when InFileStorage is configured with unsupported fsync policy
+var ErrUnknownSyncPolicy = errors.New("unknown file sync policy")
+
+// ErrStorageLocked - error when storage file is opened for writing while another process, e.g. running server, writes it
+var ErrStorageLocked = errors.New("storage file is locked by another process")
+
+// ErrReadOnly - error when InFileStorage opened read-only is mutated
+var ErrReadOnly = errors.New("storage file is opened read-only")
+
+// ErrBrokenLog - error when storage file has broken or unknown record before its last line, so it can not be replayed safely
+var ErrBrokenLog = errors.New("storage file is broken")
+
+// errTornRecord - error of the last line of log which is cut by crash in the middle of append
+var errTornRecord = errors.New("record torn at the end of storage file")
+
+// InFileOptions - durability options of InFileStorage log
+type InFileOptions struct {
+	SyncPolicy      string        // SyncPolicy - fsync policy of log: always, interval or never
+	SyncInterval    time.Duration // SyncInterval - period of background fsync for interval policy
+	CompactInterval time.Duration // CompactInterval - min period between log compactions, 0 compacts only on Close
+	ReadOnly        bool          // ReadOnly - load snapshot of files without locking, changing or keeping them open, mutations fail with ErrReadOnly
+}
+
+// DefaultInFileOptions - returns InFileOptions with interval fsync and periodic compaction
+func DefaultInFileOptions() InFileOptions {
+	return InFileOptions{
+		SyncPolicy:      SyncInterval,
+		SyncInterval:    DefaultSyncInterval,
+		CompactInterval: DefaultCompactInterval,
+	}
+}
+
+// Operations of InFileStorage log records
+const (
+	// logOpPut - shortened URL is created or changed to the state from record
+	logOpPut = "put"
+	// logOpPurge - shortened URLs are removed permanently
+	logOpPurge = "purge"
+)
+
+// logRecord - mutation of InFileStorage state, storage file is append-only JSON lines log of them
+type logRecord struct {
+	Op  string             `json:"op"`
+	URL *models.ShortenURL `json:"url,omitempty"`
+	IDs []string           `json:"ids,omitempty"`
+}
+
+// appendLog - write records to the end of log, fsync it according to policy and apply them to state by apply.
+// Both happen under logMu, so compaction never snapshots state without appended records. Log is compacted first when it is due
+func (fs *InFileStorage) appendLog(records []logRecord, apply func()) error {
+	if fs.options.ReadOnly {
+		return ErrReadOnly
+	}
+	fs.logMu.Lock()
+	defer fs.logMu.Unlock()
+	if fs.file == nil {
+		apply()
+		return nil
+	}
+
+	if fs.options.CompactInterval > 0 && time.Since(fs.compactedAt) >= fs.options.CompactInterval {
+		if err := fs.compact(); err != nil {
+			logger.Log().Error("can not compact storage file", zap.Error(err))
+		}
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := fs.file.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	if fs.options.SyncPolicy == SyncAlways {
+		if err := fs.file.Sync(); err != nil {
+			return err
+		}
+	} else {
+		fs.unsynced = true
+	}
+	apply()
+	return nil
+}
+
+// replayLog - rebuild state from log read from r, returns offset after the last applied record.
+// Only the last line without line break may be torn by crash in the middle of append, it fails with errTornRecord
+// and the caller decides whether to cut it off. Broken or unknown records anywhere else fail with ErrBrokenLog
+func (fs *InFileStorage) replayLog(r io.Reader) (end int64, err error) {
+	reader := bufio.NewReader(r)
+	var offset int64
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return end, readErr
+		}
+		// line may keep several records, when append followed record cut off without its line break
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var raw json.RawMessage
+			err = decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil && readErr != nil {
+				return end, fmt.Errorf("%w: %v", errTornRecord, err)
+			}
+			if err == nil {
+				err = fs.applyLogRecord(raw)
+			}
+			if err != nil {
+				return end, fmt.Errorf("%w: line %d: %v", ErrBrokenLog, line, err)
+			}
+			end = offset + decoder.InputOffset()
+		}
+		offset += int64(len(data))
+		end = offset
+		if readErr != nil {
+			return end, nil
+		}
+	}
+}
+
+// applyLogRecord - apply raw log record to state, records without op are shortened URLs written by previous versions
+func (fs *InFileStorage) applyLogRecord(raw json.RawMessage) error {
+	record := logRecord{}
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return err
+	}
+	if record.Op == "" {
+		record = logRecord{Op: logOpPut, URL: &models.ShortenURL{}}
+		if err := json.Unmarshal(raw, record.URL); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case record.Op == logOpPut && record.URL != nil:
+		fs.state.set(*record.URL)
+	case record.Op == logOpPurge:
+		for _, ID := range record.IDs {
+			fs.state.remove(ID)
+		}
+	default:
+		return fmt.Errorf("unknown log record %q", record.Op)
+	}
+	return nil
+}
+
+// compact - atomically replace log with snapshot of current state, logMu has to be held
+func (fs *InFileStorage) compact() (err error) {
+	file, err := replaceFile(fs.filePath, func(encoder *json.Encoder) (err error) {
+		fs.state.each(func(ID string, shortURL models.ShortenURL) bool {
+			err = encoder.Encode(&logRecord{Op: logOpPut, URL: &shortURL})
+			return err == nil
+		})
+		return
+	})
+	if err != nil {
+		return
+	}
+	_ = fs.file.Close()
+	fs.file = file
+	fs.compactedAt = time.Now()
+	fs.unsynced = false
+	return
+}
+
+// replaceFile - atomically replace content of file with JSON values written by write through temporary file,
+// fsync and rename, returns replaced file opened for appending
+func replaceFile(path string, write func(encoder *json.Encoder) error) (file *os.File, err error) {
+	tmpPath := path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	writer := bufio.NewWriter(tmp)
+	if err = write(json.NewEncoder(writer)); err != nil {
+		return
+	}
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if err = tmp.Sync(); err != nil {
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return
+	}
+	syncDir(filepath.Dir(path))
+	return os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0644)
+}
+
+// syncDir - fsync directory so rename of file in it survives crash
+func syncDir(path string) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	_ = dir.Sync()
+	_ = dir.Close()
+}
+
+// syncLog - fsync log if it has records written after previous fsync
+func (fs *InFileStorage) syncLog() error {
+	fs.logMu.Lock()
+	defer fs.logMu.Unlock()
+	if fs.file == nil || !fs.unsynced {
+		return nil
+	}
+	fs.unsynced = false
+	return fs.file.Sync()
+}
+
+// syncSidecar - fsync sidecar file after write according to policy, interval policy leaves it to syncLoop,
+// lock of the sidecar has to be held
+func (fs *InFileStorage) syncSidecar(file *os.File, unsynced *bool) error {
+	switch fs.options.SyncPolicy {
+	case SyncAlways:
+		return file.Sync()
+	case SyncInterval:
+		*unsynced = true
+	}
+	return nil
+}
+
+// syncSidecars - fsync clicks and history files if they have writes after previous fsync
+func (fs *InFileStorage) syncSidecars() error {
+	fs.clicksMu.Lock()
+	var clicksErr error
+	if fs.clicksFile != nil && fs.clicksUnsynced {
+		fs.clicksUnsynced = false
+		clicksErr = fs.clicksFile.Sync()
+	}
+	fs.clicksMu.Unlock()
+
+	fs.historyMu.Lock()
+	defer fs.historyMu.Unlock()
+	if fs.historyFile != nil && fs.historyUnsynced {
+		fs.historyUnsynced = false
+		return errors.Join(clicksErr, fs.historyFile.Sync())
+	}
+	return clicksErr
+}
+
+// syncLoop - fsync log in background until storage is closed
+func (fs *InFileStorage) syncLoop() {
+	defer close(fs.syncDone)
+	ticker := time.NewTicker(fs.options.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fs.stopSync:
+			return
+		case <-ticker.C:
+			if err := errors.Join(fs.syncLog(), fs.syncSidecars()); err != nil {
+				logger.Log().Error("can not sync storage file", zap.Error(err))
+			}
+		}
+	}
+}
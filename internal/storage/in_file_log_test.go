@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countLines(t *testing.T, filePath string) int {
+	file, err := os.Open(filePath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines
+}
+
+// crash - close files of storage without compaction and release its lock like exited process does
+func crash(fs *InFileStorage) {
+	if fs.stopSync != nil {
+		close(fs.stopSync)
+		<-fs.syncDone
+		fs.stopSync = nil
+	}
+	for _, file := range []*os.File{fs.file, fs.clicksFile, fs.historyFile, fs.lock} {
+		if file != nil {
+			_ = file.Close()
+		}
+	}
+	fs.file, fs.clicksFile, fs.historyFile, fs.lock = nil, nil, nil, nil
+}
+
+func TestInFileStorage_replay_without_Close(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncAlways})
+	require.NoError(t, err)
+
+	first := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"}
+	second := models.ShortenURL{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"}
+	require.NoError(t, fs.Store(context.Background(), &first))
+	_, err = fs.StoreBatch(context.Background(), []models.ShortenURL{second})
+	require.NoError(t, err)
+	require.NoError(t, fs.DeleteShortURLs(context.Background(), []string{"bc2c0be9"}))
+	crash(fs)
+
+	crashed, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	defer crashed.Close()
+	found, err := crashed.FindByID(context.Background(), "2187b119")
+	assert.NoError(t, err, "stored URL replayed from log without Close")
+	assert.Equal(t, "https://practicum.yandex.ru/", found.OriginalURL)
+	found, err = crashed.FindByID(context.Background(), "bc2c0be9")
+	assert.NoError(t, err)
+	assert.True(t, found.DeletedFlag, "deletion replayed from log")
+}
+
+func TestInFileStorage_replay_torn_record(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	legacy := `{"uuid":"2187b119","user_id":"1","original_URL":"https://practicum.yandex.ru/","is_deleted":false,"is_alias":false}` + "\n"
+	torn := `{"op":"put","url":{"uuid":"bc2c0be9","user_`
+	require.NoError(t, os.WriteFile(filePath, []byte(legacy+torn), 0644))
+
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	_, err = fs.FindByID(context.Background(), "2187b119")
+	assert.NoError(t, err, "record of previous file format replayed")
+	_, err = fs.FindByID(context.Background(), "bc2c0be9")
+	assert.Error(t, err, "torn record skipped")
+
+	shortURL := models.ShortenURL{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"}
+	require.NoError(t, fs.Store(context.Background(), &shortURL))
+	crash(fs)
+
+	reopened, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	defer reopened.Close()
+	_, err = reopened.FindByID(context.Background(), "bc2c0be9")
+	assert.NoError(t, err, "records appended after torn one are replayed")
+}
+
+func TestInFileStorage_replay_broken_log(t *testing.T) {
+	record := `{"op":"put","url":{"uuid":"2187b119","user_id":"1","original_URL":"https://practicum.yandex.ru/"}}`
+	testCases := []struct {
+		name string
+		log  string
+	}{
+		{name: "broken record before last line", log: `{"op":"put","url":{"uuid":` + "\n" + record + "\n"},
+		{name: "unknown operation", log: record + "\n" + `{"op":"rename","ids":["2187b119"]}` + "\n"},
+		{name: "unknown operation at the end", log: record + "\n" + `{"op":"rename","ids":["2187b119"]}`},
+		{name: "broken last line with line break", log: record + "\n" + `{"op":"put","url":{"uuid":` + "\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "store")
+			require.NoError(t, os.WriteFile(filePath, []byte(tc.log), 0644))
+
+			_, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+			assert.ErrorIs(t, err, ErrBrokenLog)
+			data, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.log, string(data), "broken log is not cut off")
+			_, err = NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever, ReadOnly: true})
+			assert.ErrorIs(t, err, ErrBrokenLog)
+		})
+	}
+
+	filePath := filepath.Join(t.TempDir(), "store")
+	joined := strings.Replace(record, "2187b119", "bc2c0be9", 1) + record + "\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(joined), 0644))
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err, "record appended after one cut off without line break is replayed")
+	defer fs.Close()
+	assert.Equal(t, 2, fs.state.len())
+}
+
+func TestInFileStorage_sidecars(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncInterval, SyncInterval: time.Hour})
+	require.NoError(t, err)
+	shortURL := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"}
+	require.NoError(t, fs.Store(ctx, &shortURL))
+	require.NoError(t, fs.StoreClicks(ctx, []models.Click{{ShortURL: "2187b119", Timestamp: time.Now()}}))
+	require.NoError(t, fs.UpdateURL(ctx, "2187b119", "https://ya.ru/"))
+	assert.True(t, fs.clicksUnsynced, "clicks are left to background fsync by interval policy")
+	assert.True(t, fs.historyUnsynced, "history is left to background fsync by interval policy")
+	require.NoError(t, fs.syncSidecars())
+	assert.False(t, fs.clicksUnsynced)
+	assert.False(t, fs.historyUnsynced)
+
+	require.NoError(t, fs.DeleteShortURLs(ctx, []string{"2187b119"}))
+	purged, err := fs.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	for _, sidecar := range []string{".clicks", ".history", ".purged"} {
+		_, err = os.Stat(filePath + sidecar + ".tmp")
+		assert.True(t, os.IsNotExist(err), "temporary file of %s renamed", sidecar)
+	}
+	assert.Equal(t, 0, countLines(t, filePath+".clicks"), "clicks of purged URL removed")
+
+	require.NoError(t, fs.StoreClicks(ctx, []models.Click{{ShortURL: "bc2c0be9", Timestamp: time.Now()}}))
+	require.NoError(t, fs.Close())
+	assert.Equal(t, 1, countLines(t, filePath+".clicks"), "clicks are appended to replaced file")
+
+	reopened, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	defer reopened.Close()
+	stats, err := reopened.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Purged, "purged counter replaced atomically")
+}
+
+func TestInFileStorage_sidecar_open_error(t *testing.T) {
+	for _, sidecar := range []string{".clicks", ".history"} {
+		t.Run(sidecar, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "store")
+			require.NoError(t, os.Mkdir(filePath+sidecar, 0755))
+
+			_, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+			assert.Error(t, err, "storage is not opened without its %s file", sidecar)
+
+			require.NoError(t, os.Remove(filePath+sidecar))
+			fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+			require.NoError(t, err, "lock of failed storage is released")
+			assert.NoError(t, fs.Close())
+		})
+	}
+}
+
+func TestInFileStorage_compaction(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever, CompactInterval: time.Nanosecond})
+	require.NoError(t, err)
+
+	shortURL := models.ShortenURL{UUID: "once", OriginalURL: "https://ya.ru/", UserID: "1", MaxClicks: 3}
+	require.NoError(t, fs.Store(context.Background(), &shortURL))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, fs.RegisterClick(context.Background(), "once"))
+	}
+	assert.Equal(t, 2, countLines(t, filePath), "log compacted before last append")
+	_, err = os.Stat(filePath + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temporary file renamed")
+
+	require.NoError(t, fs.Close())
+	assert.Equal(t, 1, countLines(t, filePath), "log compacted on Close")
+
+	reopened := NewInFileStorage(filePath)
+	defer reopened.Close()
+	found, err := reopened.FindByID(context.Background(), "once")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, found.Clicks, "snapshot keeps latest state")
+}
+
+func TestNewInFileStorageWithOptions_unknown_policy(t *testing.T) {
+	_, err := NewInFileStorageWithOptions(filepath.Join(t.TempDir(), "store"), InFileOptions{SyncPolicy: "sometimes"})
+	assert.ErrorIs(t, err, ErrUnknownSyncPolicy)
+}
+
+func TestInFileStorage_readOnly(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	_, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever, ReadOnly: true})
+	assert.ErrorIs(t, err, os.ErrNotExist, "missing file is not created")
+
+	record := `{"op":"put","url":{"uuid":"2187b119","user_id":"1","original_URL":"https://practicum.yandex.ru/"}}` + "\n"
+	torn := `{"op":"put","url":{"uuid":"bc2c0be9","user_`
+	require.NoError(t, os.WriteFile(filePath, []byte(record+torn), 0644))
+	writer, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	defer writer.Close()
+	require.NoError(t, os.WriteFile(filePath, []byte(record+torn), 0644), "append of writer is torn")
+
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncInterval, SyncInterval: time.Millisecond, ReadOnly: true})
+	require.NoError(t, err, "file locked by writer is read")
+	_, err = fs.FindByID(context.Background(), "2187b119")
+	assert.NoError(t, err)
+	shortURL := models.ShortenURL{UUID: "ya", OriginalURL: "https://ya.ru/", UserID: "1"}
+	assert.ErrorIs(t, fs.Store(context.Background(), &shortURL), ErrReadOnly)
+	assert.ErrorIs(t, fs.StoreClicks(context.Background(), []models.Click{{ShortURL: "2187b119"}}), ErrReadOnly)
+	_, err = fs.FindByID(context.Background(), "ya")
+	assert.ErrorIs(t, err, ErrNotFound, "rejected mutation is not applied")
+	require.NoError(t, fs.Close())
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, record+torn, string(data), "torn record is skipped without cutting log off")
+
+	_, err = NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	assert.ErrorIs(t, err, ErrStorageLocked, "second writer is refused")
+}
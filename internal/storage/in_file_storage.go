@@ -1,148 +1,336 @@
 package storage
 
 import (
-	"context"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
-	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/logger"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"go.uber.org/zap"
 )
 
-// InFileStorage - model of Repository storage on top of file
+// InFileStorage - model of Repository storage on top of append-only log file, safe for concurrent use.
+// State is kept in memory, mutations are appended to the log and clicks and history to sidecar files before they are applied
 type InFileStorage struct {
-	state map[string]models.ShortenURL
-	file  *os.File
+	*memoryState
+	filePath    string
+	options     InFileOptions
+	lock        *os.File
+	file        *os.File
+	logMu       sync.Mutex
+	unsynced    bool
+	compactedAt time.Time
+	stopSync    chan struct{}
+	syncDone    chan struct{}
+	clicksFile  *os.File
+	historyFile *os.File
+
+	clicksUnsynced  bool
+	historyUnsynced bool
 }
 
-// Store - stores shortened URL to internal field
-func (fs *InFileStorage) Store(ctx context.Context, shortURL models.ShortenURL) (err error) {
-	_, duplicate := fs.state[shortURL.UUID]
-	if duplicate {
-		err = ErrConflict
+// appendClicks - append clicks to clicks file and fsync it according to policy, clicksMu has to be held
+func (fs *InFileStorage) appendClicks(clicks []models.Click) (err error) {
+	if fs.options.ReadOnly {
+		return ErrReadOnly
 	}
-	fs.state[shortURL.UUID] = shortURL
-	return
+	if fs.clicksFile == nil {
+		return
+	}
+	var buffer bytes.Buffer
+	writer := json.NewEncoder(&buffer)
+	for _, click := range clicks {
+		if err = writer.Encode(&click); err != nil {
+			return
+		}
+	}
+	if _, err = fs.clicksFile.Write(buffer.Bytes()); err != nil {
+		return
+	}
+	return fs.syncSidecar(fs.clicksFile, &fs.clicksUnsynced)
 }
 
-// FindByID - filter and returns shortened URL by short ID
-func (fs *InFileStorage) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
-	var found bool
-	shortURL, found = fs.state[ID]
-	if !found {
-		err = fmt.Errorf("no value with such ID")
+// appendVersion - append URL version to history file and fsync it according to policy, historyMu has to be held
+func (fs *InFileStorage) appendVersion(version models.URLVersion) error {
+	if fs.options.ReadOnly {
+		return ErrReadOnly
 	}
-	return
+	if fs.historyFile == nil {
+		return nil
+	}
+	if err := json.NewEncoder(fs.historyFile).Encode(&version); err != nil {
+		return err
+	}
+	return fs.syncSidecar(fs.historyFile, &fs.historyUnsynced)
 }
 
-// GetAllUsers - returns all shortened URLs of the User from context
-func (fs *InFileStorage) GetAllUsers(ctx context.Context) (shortURLs []models.ShortenURL, err error) {
-	shortURLs = make([]models.ShortenURL, 0)
-	for _, shortURL := range fs.state {
-		if shortURL.UserID == ctx.Value(auth.ContextUserKey).(string) {
-			shortURLs = append(shortURLs, shortURL)
+// rewriteSidecars - atomically replace content of clicks, history and purged counter files with current state
+// like log is replaced by compaction, so crash in the middle leaves previous content
+func (fs *InFileStorage) rewriteSidecars() (err error) {
+	fs.clicksMu.Lock()
+	defer fs.clicksMu.Unlock()
+	fs.historyMu.Lock()
+	defer fs.historyMu.Unlock()
+	if fs.clicksFile != nil {
+		file, err := replaceFile(fs.filePath+".clicks", func(encoder *json.Encoder) error {
+			for _, click := range fs.clicks {
+				if err := encoder.Encode(&click); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
+		_ = fs.clicksFile.Close()
+		fs.clicksFile, fs.clicksUnsynced = file, false
 	}
-
-	if len(shortURLs) == 0 {
-		err = errors.New("user don't have any URLs")
+	if fs.historyFile != nil {
+		file, err := replaceFile(fs.filePath+".history", func(encoder *json.Encoder) error {
+			for _, versions := range fs.history {
+				for _, version := range versions {
+					if err := encoder.Encode(&version); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		_ = fs.historyFile.Close()
+		fs.historyFile, fs.historyUnsynced = file, false
 	}
-
-	return
-}
-
-// StoreBatch - stores batch of shortened URLs in internal field
-func (fs *InFileStorage) StoreBatch(ctx context.Context, shortURLs map[string]models.ShortenURL) (err error) {
-	for _, shortURL := range shortURLs {
-		fs.state[shortURL.UUID] = shortURL
+	if fs.file != nil {
+		var file *os.File
+		file, err = replaceFile(fs.filePath+".purged", func(encoder *json.Encoder) error {
+			return encoder.Encode(fs.purged.Load())
+		})
+		if err != nil {
+			return
+		}
+		err = file.Close()
 	}
 	return
 }
 
-// AsyncCheckURLsUserID - async checking if URL belongs to the User from context
-func (fs *InFileStorage) AsyncCheckURLsUserID(userID string, shortURLCh chan string) chan string {
-	addRes := make(chan string)
-	go func() {
-		defer close(addRes)
-
-		for data := range shortURLCh {
+// initialize - lock storage file against other writers, replay its log and open sidecar files for appending
+func (fs *InFileStorage) initialize(filePath string) (err error) {
+	fs.filePath = filePath
+	if fs.options.ReadOnly {
+		return fs.loadSnapshot()
+	}
 
-			shortURL, err := fs.FindByID(context.Background(), data)
-			var result string
-			if err == nil && shortURL.UserID == userID {
-				result = shortURL.UUID
-			}
+	fs.lock, err = os.OpenFile(filePath+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = fs.lock.Close()
+			fs.lock = nil
+		}
+	}()
+	if err = lockFile(fs.lock); err != nil {
+		return
+	}
 
-			addRes <- result
+	fs.file, err = os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			fs.closeFiles()
 		}
 	}()
-	return addRes
-}
+	end, err := fs.replayLog(fs.file)
+	if errors.Is(err, errTornRecord) {
+		logger.Log().Warn("storage file is cut off after torn record", zap.Int64("offset", end), zap.Error(err))
+		err = fs.file.Truncate(end)
+	}
+	if err != nil {
+		return
+	}
+	fs.compactedAt = time.Now()
 
-// DeleteShortURLs - delete shortened URLs from Data Base
-func (fs *InFileStorage) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
-	for _, shortURL := range shortURLs {
-		shortenedURL := fs.state[shortURL]
-		shortenedURL.DeletedFlag = true
-		fs.state[shortURL] = shortenedURL
+	if fs.clicksFile, err = os.OpenFile(filePath+".clicks", os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644); err != nil {
+		return
+	}
+	fs.readClicks(fs.clicksFile)
+	if fs.historyFile, err = os.OpenFile(filePath+".history", os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644); err != nil {
+		return
 	}
+	fs.readHistory(fs.historyFile)
+	fs.readPurged()
 	return
 }
 
-// GetStats - return amount of users and amount of url in the system
-func (fs *InFileStorage) GetStats(ctx context.Context) (urls int, users int, err error) {
-	urls = len(fs.state)
-	usersMap := map[string]byte{}
-	for _, shortURL := range fs.state {
-		usersMap[shortURL.UserID] = 1
+// closeFiles - close log and sidecar files opened by failed initialization
+func (fs *InFileStorage) closeFiles() {
+	for _, file := range []**os.File{&fs.file, &fs.clicksFile, &fs.historyFile} {
+		if *file != nil {
+			_ = (*file).Close()
+			*file = nil
+		}
 	}
-	users = len(usersMap)
-
-	return
 }
 
-func (fs *InFileStorage) initialize(filePath string) {
-	fs.file, _ = os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0644)
+// loadSnapshot - read log as it is at the moment of opening and sidecar files without keeping them open.
+// Writer appends only whole records and replaces log by rename, so the read part is consistent state,
+// record torn by concurrent append at the end of it is skipped
+func (fs *InFileStorage) loadSnapshot() error {
+	file, err := os.Open(fs.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end, err := fs.replayLog(io.LimitReader(file, info.Size()))
+	if errors.Is(err, errTornRecord) {
+		logger.Log().Warn("torn record at the end of storage file is skipped", zap.Int64("offset", end), zap.Error(err))
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
 
-	fs.state = make(map[string]models.ShortenURL)
+	clicksFile, err := os.Open(fs.filePath + ".clicks")
+	if err == nil {
+		fs.readClicks(clicksFile)
+		_ = clicksFile.Close()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	historyFile, err := os.Open(fs.filePath + ".history")
+	if err == nil {
+		fs.readHistory(historyFile)
+		_ = historyFile.Close()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	fs.readPurged()
+	return nil
+}
 
-	decoder := json.NewDecoder(fs.file)
-	shortURLRecord := models.ShortenURL{}
+// readClicks - load clicks of clicks file until its end or the first broken click
+func (fs *InFileStorage) readClicks(r io.Reader) {
+	if r == nil {
+		return
+	}
+	decoder := json.NewDecoder(r)
 	for {
-		if err := decoder.Decode(&shortURLRecord); err != nil {
-			break
+		click := models.Click{}
+		if err := decoder.Decode(&click); err != nil {
+			return
 		}
-		fs.state[shortURLRecord.UUID] = shortURLRecord
+		fs.clicks = append(fs.clicks, click)
 	}
 }
 
-func (fs *InFileStorage) writeBackup() error {
-	writer := json.NewEncoder(fs.file)
-	for _, shortenURL := range fs.state {
-		err := writer.Encode(&shortenURL)
-		if err != nil {
-			return err
+// readHistory - load URL versions of history file until its end or the first broken version
+func (fs *InFileStorage) readHistory(r io.Reader) {
+	if r == nil {
+		return
+	}
+	decoder := json.NewDecoder(r)
+	for {
+		version := models.URLVersion{}
+		if err := decoder.Decode(&version); err != nil {
+			return
 		}
+		fs.history[version.ShortURL] = append(fs.history[version.ShortURL], version)
 	}
-	return nil
 }
 
-// Close - close file
+// readPurged - load amount of purged URLs from purged counter file
+func (fs *InFileStorage) readPurged() {
+	if purged, err := os.ReadFile(fs.filePath + ".purged"); err == nil {
+		purgedAmount, _ := strconv.ParseInt(string(bytes.TrimSpace(purged)), 10, 64)
+		fs.purged.Store(purgedAmount)
+	}
+}
+
+// Close - stop background fsync, compact log, close files and release lock of storage file
 func (fs *InFileStorage) Close() error {
-	err := fs.writeBackup()
-	if err != nil {
-		logger.Log().Error("can not write backup file", zap.Error(err))
+	if fs.stopSync != nil {
+		close(fs.stopSync)
+		<-fs.syncDone
+		fs.stopSync = nil
+	}
+
+	if err := fs.syncSidecars(); err != nil {
+		logger.Log().Error("can not sync storage file", zap.Error(err))
+	}
+	defer fs.state.lockAllWriters()()
+	fs.logMu.Lock()
+	defer fs.logMu.Unlock()
+	if fs.clicksFile != nil {
+		_ = fs.clicksFile.Close()
+	}
+	if fs.historyFile != nil {
+		_ = fs.historyFile.Close()
+	}
+	if fs.lock != nil {
+		// lock is released after files are compacted and closed
+		defer func() {
+			_ = fs.lock.Close()
+			fs.lock = nil
+		}()
+	}
+	if fs.file == nil {
+		return nil
 	}
-	return fs.file.Close()
+	if err := fs.compact(); err != nil {
+		logger.Log().Error("can not compact storage file", zap.Error(err))
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
 }
 
-// NewInFileStorage - create instance of InFileStorage
-func NewInFileStorage(filePath string) InFileStorage {
-	store := InFileStorage{}
-	store.initialize(filePath)
+// NewInFileStorage - create instance of InFileStorage with DefaultInFileOptions
+func NewInFileStorage(filePath string) *InFileStorage {
+	store, err := NewInFileStorageWithOptions(filePath, DefaultInFileOptions())
+	if err != nil {
+		logger.Log().Error("can not open storage file", zap.Error(err))
+	}
 	return store
 }
+
+// NewInFileStorageWithOptions - create instance of InFileStorage, replays its log and starts background fsync for interval policy
+func NewInFileStorageWithOptions(filePath string, options InFileOptions) (*InFileStorage, error) {
+	store := &InFileStorage{options: options}
+	store.memoryState = newMemoryState(store)
+	switch options.SyncPolicy {
+	case SyncAlways, SyncNever:
+	case SyncInterval:
+		if options.SyncInterval <= 0 {
+			return store, fmt.Errorf("%w: non-positive sync interval", ErrUnknownSyncPolicy)
+		}
+	default:
+		return store, fmt.Errorf("%w %q", ErrUnknownSyncPolicy, options.SyncPolicy)
+	}
+
+	if err := store.initialize(filePath); err != nil {
+		return store, err
+	}
+	if options.SyncPolicy == SyncInterval && !options.ReadOnly {
+		store.stopSync = make(chan struct{})
+		store.syncDone = make(chan struct{})
+		go store.syncLoop()
+	}
+	return store, nil
+}
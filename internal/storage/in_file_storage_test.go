@@ -4,13 +4,38 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/PaBah/url-shortener.git/internal/auth"
 	"github.com/PaBah/url-shortener.git/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// withoutFile - InFileStorage with state which is not opened on file, its mutations are only applied
+func withoutFile(state *shardedState) *InFileStorage {
+	fs := &InFileStorage{}
+	fs.memoryState = newMemoryState(fs)
+	fs.state = state
+	return fs
+}
+
+// newTestShortURL - create ShortenURL by configured generator which is not expected to fail
+func newTestShortURL(t testing.TB, originalURL string, userID string) models.ShortenURL {
+	t.Helper()
+	shortURL, err := models.NewShortURL(originalURL, userID)
+	assert.NoError(t, err)
+	return shortURL
+}
+
+func storedURL(fs *InFileStorage, ID string) models.ShortenURL {
+	shortURL, _ := fs.state.get(ID)
+	return shortURL
+}
+
 func TestInFileStorage_FindByID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -21,9 +46,9 @@ func TestInFileStorage_FindByID(t *testing.T) {
 	}{
 		{
 			name:     "Successfully found value",
-			state:    map[string]models.ShortenURL{"2187b119": models.NewShortURL("https://practicum.yandex.ru/", "1")},
+			state:    map[string]models.ShortenURL{"2187b119": newTestShortURL(t, "https://practicum.yandex.ru/", "1")},
 			ID:       "2187b119",
-			wantData: models.NewShortURL("https://practicum.yandex.ru/", "1"),
+			wantData: newTestShortURL(t, "https://practicum.yandex.ru/", "1"),
 			wantErr:  false,
 		},
 		{
@@ -37,9 +62,7 @@ func TestInFileStorage_FindByID(t *testing.T) {
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, 1)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cs := &InFileStorage{
-				state: tt.state,
-			}
+			cs := withoutFile(newShardedState(tt.state))
 			gotData, err := cs.FindByID(ctx, tt.ID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindByID() error = %v, wantErr %v", err, tt.wantErr)
@@ -59,27 +82,25 @@ func TestInFileStorage_Store(t *testing.T) {
 	}{
 		{
 			name:     "With initialed store",
-			state:    map[string]models.ShortenURL{"2a49568d": models.NewShortURL("https://practicum.yandex.kz/", "1")},
-			value:    models.NewShortURL("https://practicum.yandex.ru/", "1"),
+			state:    map[string]models.ShortenURL{"2a49568d": newTestShortURL(t, "https://practicum.yandex.kz/", "1")},
+			value:    newTestShortURL(t, "https://practicum.yandex.ru/", "1"),
 			wantData: "2187b119",
 		},
 
 		{
 			name:     "Empty store",
 			state:    make(map[string]models.ShortenURL),
-			value:    models.NewShortURL("https://practicum.yandex.ru/", "1"),
+			value:    newTestShortURL(t, "https://practicum.yandex.ru/", "1"),
 			wantData: "2187b119",
 		},
 	}
 	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cs := &InFileStorage{
-				state: tt.state,
-			}
-			err := cs.Store(ctx, tt.value)
+			cs := withoutFile(newShardedState(tt.state))
+			err := cs.Store(ctx, &tt.value)
 			assert.NoError(t, err)
-			assert.Equal(t, cs.state[tt.wantData], tt.value, "Результат после добавления не совпадает с ожидаемым")
+			assert.Equal(t, storedURL(cs, tt.wantData), tt.value, "Результат после добавления не совпадает с ожидаемым")
 		})
 	}
 }
@@ -88,45 +109,85 @@ func TestWorkWithFile(t *testing.T) {
 	fs := NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
 
-	fs.state = map[string]models.ShortenURL{"bc2c0be9": models.NewShortURL("test", "1")}
+	fs.state = newShardedState(map[string]models.ShortenURL{"bc2c0be9": newTestShortURL(t, "test", "1")})
 
-	err := fs.writeBackup()
+	err := fs.compact()
 	assert.NoError(t, err, "data had been written with error")
 
-	fs.state = nil
+	fs.memoryState = newMemoryState(fs)
+	crash(fs)
 	fs.initialize("/tmp/.test_store")
 
-	assert.Equal(t, fs.state, map[string]models.ShortenURL{"bc2c0be9": models.NewShortURL("test", "1")}, "data had been read with error")
+	assert.Equal(t, 1, fs.state.len(), "data had been read with error")
+	assert.Equal(t, newTestShortURL(t, "test", "1"), storedURL(fs, "bc2c0be9"), "data had been read with error")
 	_ = os.Remove("/tmp/.test_store")
 }
 
 func TestInFileStorage_StoreBatch(t *testing.T) {
-	fs := NewInFileStorage("/tmp/.test_store")
+	fs := NewInFileStorage(filepath.Join(t.TempDir(), "store"))
 	defer fs.Close()
-	shortURLs := map[string]models.ShortenURL{
-		"test1": models.NewShortURL("test", "1"),
-		"test2": models.NewShortURL("test", "1"),
+	shortURLs := []models.ShortenURL{
+		newTestShortURL(t, "test", "1"),
+		newTestShortURL(t, "test", "1"),
 	}
-	err := fs.StoreBatch(context.Background(), shortURLs)
+	results, err := fs.StoreBatch(context.Background(), shortURLs)
 
 	assert.NoError(t, err, "Batch value insertion not failed")
-	_ = os.Remove("/tmp/.test_store")
+	assert.Equal(t, models.BatchCreated, results[0].Status)
+	assert.Equal(t, models.BatchExisted, results[1].Status, "same URL of the same user stored once")
 }
 
-func TestInFileStorage_GetAllUsers(t *testing.T) {
+func TestInFileStorage_ListUserURLs(t *testing.T) {
 	fs := NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
-	shortURLs := map[string]models.ShortenURL{
-		"test1": models.NewShortURL("test", "1"),
-		"test2": models.NewShortURL("test", "1"),
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	fs.state = newShardedState(map[string]models.ShortenURL{
+		"aaaa0001": {UUID: "aaaa0001", OriginalURL: "https://c.ru/", UserID: "1", CreatedAt: createdAt},
+		"aaaa0002": {UUID: "aaaa0002", OriginalURL: "https://a.ru/sale", UserID: "1", CreatedAt: createdAt.Add(time.Hour), DeletedFlag: true},
+		"aaaa0003": {UUID: "aaaa0003", OriginalURL: "https://b.ru/", UserID: "1", CreatedAt: createdAt.Add(time.Hour)},
+		"aaaa0004": {UUID: "aaaa0004", OriginalURL: "https://d.ru/", UserID: "2", CreatedAt: createdAt},
+	})
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	shortIDs := func(page models.URLsPage) (IDs []string) {
+		for _, shortURL := range page.URLs {
+			IDs = append(IDs, shortURL.UUID)
+		}
+		return
 	}
-	fs.state = shortURLs
 
-	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
-	data, err := fs.GetAllUsers(ctx)
-	assert.NoError(t, err)
-	assert.Equal(t, []models.ShortenURL{models.NewShortURL("test", "1"), models.NewShortURL("test", "1")}, data, "Found message scanned correctly")
-	assert.NoError(t, err, "Batch value insertion not failed")
+	page, err := fs.ListUserURLs(ctx, models.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0002"}, shortIDs(page), "First page sorted by creation, ties by short ID")
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = fs.ListUserURLs(ctx, models.ListOptions{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0003"}, shortIDs(page), "Second page continues after cursor")
+	assert.Empty(t, page.NextCursor, "Last page has no cursor")
+
+	page, err = fs.ListUserURLs(ctx, models.ListOptions{SortBy: models.SortByOriginalURL, Desc: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0003", "aaaa0002"}, shortIDs(page), "Sorted by original URL descending")
+
+	page, err = fs.ListUserURLs(ctx, models.ListOptions{Status: models.StatusActive})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0003"}, shortIDs(page), "Deleted filtered out")
+
+	page, err = fs.ListUserURLs(ctx, models.ListOptions{Status: models.StatusDeleted})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0002"}, shortIDs(page), "Only deleted")
+
+	page, err = fs.ListUserURLs(ctx, models.ListOptions{Search: "SALE"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0002"}, shortIDs(page), "Case-insensitive substring search")
+
+	first, _ := fs.ListUserURLs(ctx, models.ListOptions{Limit: 1})
+	_, err = fs.ListUserURLs(ctx, models.ListOptions{SortBy: models.SortByOriginalURL, Cursor: first.NextCursor})
+	assert.ErrorIs(t, err, models.ErrInvalidListOptions, "Cursor of another sort rejected")
+	_, err = fs.ListUserURLs(ctx, models.ListOptions{Cursor: "broken"})
+	assert.ErrorIs(t, err, models.ErrInvalidListOptions, "Broken cursor rejected")
+	_, err = fs.ListUserURLs(ctx, models.ListOptions{Limit: models.MaxListLimit + 1})
+	assert.ErrorIs(t, err, models.ErrInvalidListOptions, "Too big page rejected")
 	_ = os.Remove("/tmp/.test_store")
 }
 
@@ -134,9 +195,9 @@ func TestInFileStorage_AsyncCheckURLsUserID(t *testing.T) {
 	fs := NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
 	shortURLs := map[string]models.ShortenURL{
-		"bc2c0be9": models.NewShortURL("test", "test"),
+		"bc2c0be9": newTestShortURL(t, "test", "test"),
 	}
-	fs.state = shortURLs
+	fs.state = newShardedState(shortURLs)
 	shortURLCh := make(chan string)
 	res := fs.AsyncCheckURLsUserID("test", shortURLCh)
 	shortURLCh <- "bc2c0be9"
@@ -148,9 +209,9 @@ func TestInFileStorage_DeleteShortURLs(t *testing.T) {
 	fs := NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
 	shortURLs := map[string]models.ShortenURL{
-		"bc2c0be9": models.NewShortURL("test", "test"),
+		"bc2c0be9": newTestShortURL(t, "test", "test"),
 	}
-	fs.state = shortURLs
+	fs.state = newShardedState(shortURLs)
 	err := fs.DeleteShortURLs(context.Background(), []string{"test"})
 	assert.NoError(t, err, "successfully deleted urls")
 	_ = os.Remove("/tmp/.test_store")
@@ -160,11 +221,281 @@ func BenchmarkInFileStorage_StoreBatch(b *testing.B) {
 	fs := NewInFileStorage("/tmp/.test_store")
 	defer fs.Close()
 	for i := 0; i < b.N; i++ {
-		testFirst, testSecond := fmt.Sprintf("%d%s", i, "test"), fmt.Sprintf("%d%s", i, "2test")
-		shortURLs := map[string]models.ShortenURL{
-			testFirst:  models.NewShortURL("test", "1"),
-			testSecond: models.NewShortURL("test", "1"),
+		shortURLs := []models.ShortenURL{
+			newTestShortURL(b, "test", "1"),
+			newTestShortURL(b, "test", "1"),
 		}
-		_ = fs.StoreBatch(context.Background(), shortURLs)
+		_, _ = fs.StoreBatch(context.Background(), shortURLs)
+	}
+}
+
+func TestInFileStorage_Store_collision(t *testing.T) {
+	colliding := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://other.url/", UserID: "2"}
+	cs := withoutFile(newShardedState(map[string]models.ShortenURL{"2187b119": colliding}))
+
+	shortURL := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	err := cs.Store(context.Background(), &shortURL)
+	assert.NoError(t, err, "collision is resolved by ID regeneration")
+	assert.NotEqual(t, "2187b119", shortURL.UUID, "ID regenerated")
+	assert.Equal(t, colliding, storedURL(cs, "2187b119"), "colliding URL is not overwritten")
+	assert.Equal(t, shortURL, storedURL(cs, shortURL.UUID))
+
+	duplicate := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	err = cs.Store(context.Background(), &duplicate)
+	assert.ErrorIs(t, err, ErrConflict, "same URL of the same user conflicts")
+	assert.Equal(t, shortURL.UUID, duplicate.UUID, "conflict returns stored ID")
+}
+
+func TestInFileStorage_Store_alias(t *testing.T) {
+	cs := withoutFile(newShardedState(map[string]models.ShortenURL{"2187b119": newTestShortURL(t, "https://practicum.yandex.ru/", "1")}))
+
+	alias := models.NewAliasShortURL("spring-sale", "https://practicum.yandex.ru/", "1")
+	err := cs.Store(context.Background(), &alias)
+	assert.NoError(t, err, "alias may point to already shortened URL")
+	assert.Equal(t, alias, storedURL(cs, "spring-sale"))
+
+	taken := models.NewAliasShortURL("spring-sale", "https://other.url/", "2")
+	err = cs.Store(context.Background(), &taken)
+	assert.ErrorIs(t, err, ErrAliasTaken)
+	assert.Equal(t, alias, storedURL(cs, "spring-sale"), "taken alias is not overwritten")
+}
+
+func TestInFileStorage_RegisterClick(t *testing.T) {
+	cs := withoutFile(newShardedState(map[string]models.ShortenURL{"once": {UUID: "once", OriginalURL: "test", MaxClicks: 1}}))
+
+	assert.NoError(t, cs.RegisterClick(context.Background(), "once"), "first click allowed")
+	assert.ErrorIs(t, cs.RegisterClick(context.Background(), "once"), ErrLinkExpired, "no clicks left")
+	assert.Error(t, cs.RegisterClick(context.Background(), "unknown"), "unknown ID")
+}
+
+func TestInFileStorage_DeleteExpired(t *testing.T) {
+	now := time.Now()
+	cs := withoutFile(newShardedState(map[string]models.ShortenURL{
+		"expired": {UUID: "expired", ExpiresAt: now.Add(-time.Minute)},
+		"clicked": {UUID: "clicked", MaxClicks: 1, Clicks: 1},
+		"active":  {UUID: "active", ExpiresAt: now.Add(time.Minute)},
+	}))
+
+	deleted, err := cs.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.True(t, storedURL(cs, "expired").DeletedFlag)
+	assert.True(t, storedURL(cs, "clicked").DeletedFlag)
+	assert.False(t, storedURL(cs, "active").DeletedFlag)
+}
+
+func TestInFileStorage_Clicks(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs := NewInFileStorage(filePath)
+
+	firstDay := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	secondDay := time.Date(2024, 5, 2, 23, 59, 0, 0, time.UTC)
+	clicks := []models.Click{
+		{ShortURL: "bc2c0be9", Timestamp: secondDay, IP: "10.0.0.0"},
+		{ShortURL: "bc2c0be9", Timestamp: firstDay, IP: "10.0.0.0"},
+		{ShortURL: "bc2c0be9", Timestamp: firstDay.Add(time.Hour), IP: "10.0.1.0"},
+		{ShortURL: "2187b119", Timestamp: firstDay, IP: "10.0.0.0"},
+	}
+	err := fs.StoreClicks(context.Background(), clicks)
+	assert.NoError(t, err)
+	_ = fs.Close()
+
+	fs = NewInFileStorage(filePath)
+	defer fs.Close()
+	stats, err := fs.GetClickStats(context.Background(), "bc2c0be9")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ClickStats{
+		{Day: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Clicks: 2, Visitors: 2},
+		{Day: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 1, Visitors: 1},
+	}, stats, "clicks read from file and aggregated per day")
+}
+
+func TestInFileStorage_UpdateURL(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs := NewInFileStorage(filePath)
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	fs.state.set(models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"})
+	fs.state.set(models.ShortenURL{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"})
+	fs.state.set(models.ShortenURL{UUID: "other123", OriginalURL: "https://ya.ru/", UserID: "2"})
+
+	assert.NoError(t, fs.UpdateURL(ctx, "2187b119", "https://practicum.yandex.kz/"))
+	assert.Equal(t, "https://practicum.yandex.kz/", storedURL(fs, "2187b119").OriginalURL, "destination changed")
+	assert.ErrorIs(t, fs.UpdateURL(ctx, "2187b119", "https://ya.ru/"), ErrConflict, "user already shortened URL")
+	assert.ErrorIs(t, fs.UpdateURL(ctx, "other123", "https://practicum.yandex.kz/"), ErrNotFound, "URL of another user")
+	assert.ErrorIs(t, fs.UpdateURL(ctx, "unknown", "https://practicum.yandex.kz/"), ErrNotFound)
+	_ = fs.Close()
+
+	fs = NewInFileStorage(filePath)
+	defer fs.Close()
+	versions, err := fs.GetURLHistory(ctx, "2187b119")
+	assert.NoError(t, err)
+	require.Len(t, versions, 1, "history read from file")
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "https://practicum.yandex.ru/", versions[0].OriginalURL)
+}
+
+func TestInFileStorage_RestoreAndPurge(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs := NewInFileStorage(filePath)
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	now := time.Now()
+	fs.state.set(models.ShortenURL{UUID: "restored", OriginalURL: "https://ya.ru/", UserID: "1"})
+	fs.state.set(models.ShortenURL{UUID: "purged", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"})
+	fs.state.set(models.ShortenURL{UUID: "expired", OriginalURL: "https://practicum.yandex.kz/", UserID: "1", ExpiresAt: now.Add(-time.Minute)})
+	fs.state.set(models.ShortenURL{UUID: "other", OriginalURL: "https://ya.ru/", UserID: "2"})
+	_ = fs.StoreClicks(ctx, []models.Click{{ShortURL: "purged", Timestamp: now}, {ShortURL: "restored", Timestamp: now}})
+	_ = fs.DeleteShortURLs(ctx, []string{"restored", "purged", "expired", "other", "unknown"})
+	_, found := fs.state.get("unknown")
+	assert.False(t, found, "unknown ID is not created by deletion")
+
+	restored, err := fs.RestoreShortURLs(ctx, []string{"restored", "expired", "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"restored"}, restored, "expired and other user's URLs are not restored")
+	assert.False(t, storedURL(fs, "restored").DeletedFlag)
+	assert.True(t, storedURL(fs, "restored").DeletedAt.IsZero())
+
+	purgedURL := storedURL(fs, "purged")
+	purgedURL.DeletedAt = now.Add(-time.Hour)
+	fs.state.set(purgedURL)
+	purged, err := fs.PurgeDeleted(ctx, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged, "only URLs deleted before retention are purged")
+	_ = fs.Close()
+
+	fs = NewInFileStorage(filePath)
+	defer fs.Close()
+	_, err = fs.FindByID(ctx, "purged")
+	assert.Error(t, err, "purged URL removed from file")
+	stats, err := fs.GetClickStats(ctx, "purged")
+	assert.NoError(t, err)
+	assert.Empty(t, stats, "clicks of purged URL removed from file")
+	storeStats, err := fs.GetStats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Stats{URLs: 3, Users: 2, Active: 1, Deleted: 2, Purged: 1}, storeStats)
+}
+
+func TestInFileStorage_concurrent(t *testing.T) {
+	const workers, iterations = 8, 100
+	filePath := filepath.Join(t.TempDir(), "store")
+	fs, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever, CompactInterval: 50 * time.Millisecond})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(5)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				shortURL := newTestShortURL(t, fmt.Sprintf("https://ya.ru/%d/%d", w, i), "1")
+				assert.NoError(t, fs.Store(ctx, &shortURL))
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				ID := fmt.Sprintf("batch-%d-%d", w, i)
+				_, err := fs.StoreBatch(ctx, []models.ShortenURL{
+					{UUID: ID, OriginalURL: "https://practicum.yandex.ru/" + ID, UserID: "1"},
+				})
+				assert.NoError(t, err)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				_, _ = fs.FindByID(ctx, fmt.Sprintf("batch-%d-%d", w, i))
+				if i%10 == 0 {
+					_, _ = fs.ListUserURLs(ctx, models.ListOptions{Limit: 10})
+				}
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				assert.NoError(t, fs.DeleteShortURLs(ctx, []string{fmt.Sprintf("batch-%d-%d", w, i)}))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				shortURL := newTestShortURL(t, fmt.Sprintf("https://ya.ru/shared/%d", i), "1")
+				if err := fs.Store(ctx, &shortURL); err != nil {
+					assert.ErrorIs(t, err, ErrConflict)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, err := fs.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2*workers*iterations+iterations, stats.URLs, "every stored URL kept, URL shared by workers stored once")
+	require.NoError(t, fs.Close())
+
+	reopened, err := NewInFileStorageWithOptions(filePath, InFileOptions{SyncPolicy: SyncNever})
+	require.NoError(t, err)
+	defer reopened.Close()
+	replayed, err := reopened.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stats, replayed, "log written concurrently replays to the same state")
+}
+
+func TestInFileStorage_originalURLIndex(t *testing.T) {
+	fs := NewInFileStorage(filepath.Join(t.TempDir(), "store"))
+	defer fs.Close()
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	first := models.ShortenURL{UUID: "first", OriginalURL: "https://ya.ru/", UserID: "1"}
+	require.NoError(t, fs.Store(ctx, &first))
+
+	require.NoError(t, fs.UpdateURL(ctx, "first", "https://practicum.yandex.ru/"))
+	second := models.ShortenURL{UUID: "second", OriginalURL: "https://ya.ru/", UserID: "1"}
+	assert.NoError(t, fs.Store(ctx, &second), "updated URL frees its previous original URL")
+	conflicted := models.ShortenURL{UUID: "third", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"}
+	assert.ErrorIs(t, fs.Store(ctx, &conflicted), ErrConflict, "updated URL takes its new original URL")
+	assert.Equal(t, "first", conflicted.UUID)
+
+	require.NoError(t, fs.UpsertURLs(ctx, []models.ShortenURL{{UUID: "second", OriginalURL: "https://ya.ru/", UserID: "2"}}))
+	third := models.ShortenURL{UUID: "third", OriginalURL: "https://ya.ru/", UserID: "1"}
+	assert.NoError(t, fs.Store(ctx, &third), "upserted URL of another user frees original URL")
+
+	require.NoError(t, fs.DeleteShortURLs(ctx, []string{"third"}))
+	deleted := models.ShortenURL{UUID: "fourth", OriginalURL: "https://ya.ru/", UserID: "1"}
+	assert.ErrorIs(t, fs.Store(ctx, &deleted), ErrConflict, "deleted URL keeps its original URL")
+	_, err := fs.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.NoError(t, fs.Store(ctx, &deleted), "purged URL frees its original URL")
+}
+
+func TestInFileStorage_shardedWriters(t *testing.T) {
+	fs := NewInFileStorage(filepath.Join(t.TempDir(), "store"))
+	defer fs.Close()
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	locked, free := "locked", "free"
+	for shardNumber(free) == shardNumber(locked) {
+		free += "-"
+	}
+
+	unlock := fs.state.lockWriters(nil, []string{locked})
+	stored := make(chan error)
+	go func() {
+		stored <- fs.Store(ctx, &models.ShortenURL{UUID: free, OriginalURL: "https://ya.ru/", UserID: "1"})
+	}()
+	select {
+	case err := <-stored:
+		assert.NoError(t, err, "URL of another shard is stored while shard is locked")
+	case <-time.After(time.Second):
+		t.Fatal("URL of another shard waits for locked shard")
+	}
+
+	go func() {
+		stored <- fs.Store(ctx, &models.ShortenURL{UUID: locked, OriginalURL: "https://practicum.yandex.ru/", UserID: "1"})
+	}()
+	select {
+	case <-stored:
+		t.Fatal("URL of locked shard is stored")
+	case <-time.After(50 * time.Millisecond):
 	}
+	unlock()
+	assert.NoError(t, <-stored)
 }
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// urlColumns - columns of urls table read by scanURL
+const urlColumns = `url, short_url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at`
+
+// scanURL - read shortened URL from row of urlColumns
+func scanURL(rows *sql.Rows) (shortURL models.ShortenURL, err error) {
+	var expiresAt, deletedAt sql.NullTime
+	err = rows.Scan(&shortURL.OriginalURL, &shortURL.UUID, &shortURL.UserID, &shortURL.DeletedFlag, &shortURL.IsAlias,
+		&expiresAt, &shortURL.MaxClicks, &shortURL.Clicks, &shortURL.CreatedAt, &deletedAt)
+	shortURL.ExpiresAt, shortURL.DeletedAt = expiresAt.Time, deletedAt.Time
+	return
+}
+
+// createdAt - returns moment of shortening, URLs without it are treated as shortened now
+func createdAt(shortURL models.ShortenURL) time.Time {
+	if shortURL.CreatedAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return shortURL.CreatedAt
+}
+
+// newURLsPage - cut page from sorted shortened URLs fetched with one extra item which signals next page
+func newURLsPage(shortURLs []models.ShortenURL, options models.ListOptions) models.URLsPage {
+	page := models.URLsPage{URLs: shortURLs}
+	if len(shortURLs) > options.Limit {
+		page.URLs = shortURLs[:options.Limit]
+		page.NextCursor = models.NewListCursor(options.SortBy, page.URLs[options.Limit-1])
+	}
+	return page
+}
+
+// compareByListSort - compare shortened URLs by sort key and short ID
+func compareByListSort(sortBy string, a models.ShortenURL, b models.ShortenURL) int {
+	var result int
+	if sortBy == models.SortByCreatedAt {
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	} else {
+		result = strings.Compare(a.OriginalURL, b.OriginalURL)
+	}
+	if result == 0 {
+		result = strings.Compare(a.UUID, b.UUID)
+	}
+	return result
+}
+
+// listShortURLs - apply normalized list options to user's shortened URLs kept in memory
+func listShortURLs(shortURLs []models.ShortenURL, options models.ListOptions) (models.URLsPage, error) {
+	cursor, err := options.ParseCursor()
+	if err != nil {
+		return models.URLsPage{}, err
+	}
+	var after *models.ShortenURL
+	if cursor != nil {
+		after = &models.ShortenURL{UUID: cursor.ID, CreatedAt: cursor.CreatedAt, OriginalURL: cursor.URL}
+	}
+	search := strings.ToLower(options.Search)
+
+	filtered := make([]models.ShortenURL, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		if options.Status == models.StatusActive && shortURL.DeletedFlag || options.Status == models.StatusDeleted && !shortURL.DeletedFlag {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(shortURL.OriginalURL), search) &&
+			!strings.Contains(strings.ToLower(shortURL.UUID), search) {
+			continue
+		}
+		if after != nil {
+			comparison := compareByListSort(options.SortBy, shortURL, *after)
+			if !options.Desc && comparison <= 0 || options.Desc && comparison >= 0 {
+				continue
+			}
+		}
+		filtered = append(filtered, shortURL)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		comparison := compareByListSort(options.SortBy, filtered[i], filtered[j])
+		if options.Desc {
+			return comparison > 0
+		}
+		return comparison < 0
+	})
+	if len(filtered) > options.Limit+1 {
+		filtered = filtered[:options.Limit+1]
+	}
+	return newURLsPage(filtered, options), nil
+}
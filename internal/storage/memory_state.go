@@ -0,0 +1,505 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// stateJournal - persistence of memoryState mutations, every mutation is persisted before it is applied
+type stateJournal interface {
+	// appendLog - persist records and apply them to state by apply, appends are serialized with each other
+	appendLog(records []logRecord, apply func()) error
+	// appendClicks - persist clicks, clicksMu of state is held
+	appendClicks(clicks []models.Click) error
+	// appendVersion - persist previous original URL of shortened URL, historyMu of state is held
+	appendVersion(version models.URLVersion) error
+	// rewriteSidecars - persist clicks, history and purged counter after shortened URLs are purged
+	rewriteSidecars() error
+}
+
+// memoryState - shortened URLs with their clicks and history kept in memory, safe for concurrent use. It implements
+// Repository for MemoryStorage and InFileStorage, which differ only by journal persisting mutations.
+// Mutations lock only shards of short IDs and original URLs they change, readers lock only shard of requested short ID
+type memoryState struct {
+	journal   stateJournal
+	state     *shardedState
+	purged    atomic.Int64
+	clicksMu  sync.RWMutex
+	clicks    []models.Click
+	historyMu sync.RWMutex
+	history   map[string][]models.URLVersion
+}
+
+// newMemoryState - create empty memoryState which persists mutations by journal
+func newMemoryState(journal stateJournal) *memoryState {
+	return &memoryState{
+		journal: journal,
+		state:   newShardedState(nil),
+		history: make(map[string][]models.URLVersion),
+	}
+}
+
+// Store - stores shortened URL to internal field, regenerates its ID when it is taken by another URL
+func (ms *memoryState) Store(ctx context.Context, shortURL *models.ShortenURL) (err error) {
+	if shortURL.CreatedAt.IsZero() {
+		shortURL.CreatedAt = time.Now().UTC()
+	}
+	if shortURL.IsAlias {
+		defer ms.state.lockWriters(nil, []string{shortURL.UUID})()
+		if _, taken := ms.state.get(shortURL.UUID); taken {
+			return ErrAliasTaken
+		}
+		return ms.put(*shortURL)
+	}
+
+	pair := userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}
+	defer ms.state.lockWriters([]userURL{pair}, nil)()
+	if stored, found := ms.findByOriginalURL(pair); found {
+		*shortURL = stored
+		return ErrConflict
+	}
+
+	for attempt := 1; attempt <= MaxIDGenerationAttempts; attempt++ {
+		if stored, err := ms.storeNew(*shortURL); stored || err != nil {
+			return err
+		}
+		if err := models.RegenerateID(shortURL, attempt); err != nil {
+			return err
+		}
+	}
+	return ErrIDCollision
+}
+
+// storeNew - put shortened URL when its short ID is not taken, shard of the ID is locked only for the check and put
+func (ms *memoryState) storeNew(shortURL models.ShortenURL) (stored bool, err error) {
+	defer ms.state.lockWriters(nil, []string{shortURL.UUID})()
+	if _, taken := ms.state.get(shortURL.UUID); taken {
+		return false, nil
+	}
+	return true, ms.put(shortURL)
+}
+
+// findByOriginalURL - returns stored not alias shortened URL of user ID and original URL pair by index,
+// index shard of the pair has to be locked
+func (ms *memoryState) findByOriginalURL(pair userURL) (shortURL models.ShortenURL, found bool) {
+	ID, found := ms.state.lookup(pair)
+	if !found {
+		return
+	}
+	return ms.state.get(ID)
+}
+
+// findUserURLs - returns stored not alias shortened URLs by user ID and original URL pairs, index shards of pairs have to be locked
+func (ms *memoryState) findUserURLs(pairs []userURL) map[userURL]models.ShortenURL {
+	storedURLs := make(map[userURL]models.ShortenURL, len(pairs))
+	for _, pair := range pairs {
+		if shortURL, found := ms.findByOriginalURL(pair); found {
+			storedURLs[pair] = shortURL
+		}
+	}
+	return storedURLs
+}
+
+// FindByID - filter and returns shortened URL by short ID
+func (ms *memoryState) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
+	var found bool
+	shortURL, found = ms.state.get(ID)
+	if !found {
+		err = ErrNotFound
+	}
+	return
+}
+
+// ListUserURLs - returns page of shortened URLs of the User from context
+func (ms *memoryState) ListUserURLs(ctx context.Context, options models.ListOptions) (page models.URLsPage, err error) {
+	options, err = options.Normalize()
+	if err != nil {
+		return
+	}
+
+	userID := ctx.Value(auth.ContextUserKey).(string)
+	shortURLs := make([]models.ShortenURL, 0)
+	ms.state.each(func(ID string, shortURL models.ShortenURL) bool {
+		if shortURL.UserID == userID {
+			shortURLs = append(shortURLs, shortURL)
+		}
+		return true
+	})
+	return listShortURLs(shortURLs, options)
+}
+
+// StoreBatch - stores batch of shortened URLs in internal field, returns result of every shortened URL in batch order
+func (ms *memoryState) StoreBatch(ctx context.Context, batchURLs []models.ShortenURL) (results []models.BatchResult, err error) {
+	now := time.Now().UTC()
+	shortURLs := make([]models.ShortenURL, 0, len(batchURLs))
+	for _, shortURL := range batchURLs {
+		if shortURL.CreatedAt.IsZero() {
+			shortURL.CreatedAt = now
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	results = make([]models.BatchResult, len(shortURLs))
+	batch := make([]models.ShortenURL, 0, len(shortURLs))
+	batchIDs := make(map[string]struct{}, len(shortURLs))
+	pending := batchIndexes(len(shortURLs))
+	pairs := batchUserURLs(pending, shortURLs)
+	defer ms.state.lockBatchWriters(pairs)()
+	storedURLs := ms.findUserURLs(pairs)
+	for attempt := 1; len(pending) > 0; attempt++ {
+		inserted := make(map[string]struct{}, len(pending))
+		for _, i := range pending {
+			shortURL := shortURLs[i]
+			if _, taken := ms.state.get(shortURL.UUID); taken {
+				continue
+			}
+			if _, taken := batchIDs[shortURL.UUID]; taken {
+				continue
+			}
+			pair := userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}
+			if !shortURL.IsAlias {
+				if _, found := storedURLs[pair]; found {
+					continue
+				}
+				storedURLs[pair] = shortURL
+			}
+			inserted[shortURL.UUID] = struct{}{}
+			batchIDs[shortURL.UUID] = struct{}{}
+			batch = append(batch, shortURL)
+		}
+		pending = resolveBatch(pending, shortURLs, inserted, storedURLs, attempt, results)
+	}
+	if err = ms.put(batch...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ScanURLs - calls scan for every shortened URL of all users ordered by short ID,
+// URLs are copied from state with mutations held off, so they are one consistent snapshot
+func (ms *memoryState) ScanURLs(ctx context.Context, scan func(shortURL models.ShortenURL) error) (err error) {
+	unlock := ms.state.lockAllWriters()
+	shortURLs := make([]models.ShortenURL, 0, ms.state.len())
+	ms.state.each(func(ID string, shortURL models.ShortenURL) bool {
+		shortURLs = append(shortURLs, shortURL)
+		return true
+	})
+	unlock()
+
+	sort.Slice(shortURLs, func(i, j int) bool { return shortURLs[i].UUID < shortURLs[j].UUID })
+	for _, shortURL := range shortURLs {
+		if err = scan(shortURL); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpsertURLs - store shortened URLs as they are with clicks and deletion, stored URLs with the same short ID are replaced
+func (ms *memoryState) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error) {
+	defer ms.state.lockAllWriters()()
+	upserted := make([]models.ShortenURL, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		shortURL.CreatedAt = createdAt(shortURL)
+		upserted = append(upserted, shortURL)
+	}
+	return ms.put(upserted...)
+}
+
+// AsyncCheckURLsUserID - async checking if URL belongs to the User from context
+func (ms *memoryState) AsyncCheckURLsUserID(userID string, shortURLCh chan string) chan string {
+	addRes := make(chan string)
+	go func() {
+		defer close(addRes)
+
+		for data := range shortURLCh {
+
+			shortURL, err := ms.FindByID(context.Background(), data)
+			var result string
+			if err == nil && shortURL.UserID == userID {
+				result = shortURL.UUID
+			}
+
+			addRes <- result
+		}
+	}()
+	return addRes
+}
+
+// DeleteShortURLs - delete shortened URLs from Data Base
+func (ms *memoryState) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
+	defer ms.state.lockWriters(nil, shortURLs)()
+	now := time.Now().UTC()
+	deleted := make([]models.ShortenURL, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		shortenedURL, found := ms.state.get(shortURL)
+		if !found || shortenedURL.DeletedFlag {
+			continue
+		}
+		shortenedURL.DeletedFlag = true
+		shortenedURL.DeletedAt = now
+		deleted = append(deleted, shortenedURL)
+	}
+	return ms.put(deleted...)
+}
+
+// RestoreShortURLs - undo soft deletion of not expired shortened URLs of the User from context
+func (ms *memoryState) RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error) {
+	defer ms.state.lockWriters(nil, shortURLs)()
+	now := time.Now()
+	restoredURLs := make([]models.ShortenURL, 0, len(shortURLs))
+	restored = make([]string, 0)
+	for _, ID := range shortURLs {
+		shortURL, found := ms.state.get(ID)
+		if !found || !shortURL.DeletedFlag || shortURL.UserID != ctx.Value(auth.ContextUserKey).(string) || shortURL.IsExpired(now) {
+			continue
+		}
+		shortURL.DeletedFlag = false
+		shortURL.DeletedAt = time.Time{}
+		restoredURLs = append(restoredURLs, shortURL)
+		restored = append(restored, ID)
+	}
+	if err = ms.put(restoredURLs...); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// MigrationStatus - memory and file keep no schema, so it always fails with ErrNoMigrations
+func (ms *memoryState) MigrationStatus(ctx context.Context) (status models.MigrationStatus, err error) {
+	return status, ErrNoMigrations
+}
+
+// PurgeDeleted - permanently remove shortened URLs soft deleted before the moment with their clicks and history
+func (ms *memoryState) PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error) {
+	defer ms.state.lockAllWriters()()
+	purgedIDs := map[string]struct{}{}
+	record := logRecord{Op: logOpPurge}
+	ms.state.each(func(ID string, shortURL models.ShortenURL) bool {
+		if shortURL.DeletedFlag && shortURL.DeletedAt.Before(before) {
+			purgedIDs[ID] = struct{}{}
+			record.IDs = append(record.IDs, ID)
+		}
+		return true
+	})
+	if len(purgedIDs) == 0 {
+		return
+	}
+	err = ms.journal.appendLog([]logRecord{record}, func() {
+		for _, ID := range record.IDs {
+			ms.state.remove(ID)
+		}
+	})
+	if err != nil {
+		return
+	}
+	ms.historyMu.Lock()
+	for _, ID := range record.IDs {
+		delete(ms.history, ID)
+	}
+	ms.historyMu.Unlock()
+
+	ms.clicksMu.Lock()
+	clicks := make([]models.Click, 0, len(ms.clicks))
+	for _, click := range ms.clicks {
+		if _, found := purgedIDs[click.ShortURL]; !found {
+			clicks = append(clicks, click)
+		}
+	}
+	ms.clicks = clicks
+	ms.clicksMu.Unlock()
+	ms.purged.Add(int64(len(purgedIDs)))
+
+	if err = ms.journal.rewriteSidecars(); err != nil {
+		return
+	}
+	return len(purgedIDs), nil
+}
+
+// GetStats - return amounts of users, active, deleted and purged urls in the system
+func (ms *memoryState) GetStats(ctx context.Context) (stats models.Stats, err error) {
+	usersMap := map[string]byte{}
+	ms.state.each(func(ID string, shortURL models.ShortenURL) bool {
+		stats.URLs++
+		usersMap[shortURL.UserID] = 1
+		if shortURL.DeletedFlag {
+			stats.Deleted++
+		}
+		return true
+	})
+	stats.Users = len(usersMap)
+	stats.Active = stats.URLs - stats.Deleted
+	stats.Purged = int(ms.purged.Load())
+
+	return
+}
+
+// RegisterClick - count click on shortened URL, fails with ErrLinkExpired when URL is expired and ErrNotFound when it does not exist
+func (ms *memoryState) RegisterClick(ctx context.Context, ID string) (err error) {
+	defer ms.state.lockWriters(nil, []string{ID})()
+	shortURL, found := ms.state.get(ID)
+	if !found {
+		return ErrNotFound
+	}
+	if shortURL.IsExpired(time.Now()) {
+		return ErrLinkExpired
+	}
+	shortURL.Clicks++
+	return ms.put(shortURL)
+}
+
+// DeleteExpired - mark as deleted shortened URLs expired at the moment
+func (ms *memoryState) DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error) {
+	defer ms.state.lockAllWriters()()
+	expired := make([]models.ShortenURL, 0)
+	ms.state.each(func(ID string, shortURL models.ShortenURL) bool {
+		if !shortURL.DeletedFlag && shortURL.IsExpired(now) {
+			shortURL.DeletedFlag = true
+			shortURL.DeletedAt = now.UTC()
+			expired = append(expired, shortURL)
+		}
+		return true
+	})
+	if err = ms.put(expired...); err != nil {
+		return
+	}
+	return len(expired), nil
+}
+
+// StoreClicks - persist clicks by journal and append them to internal field
+func (ms *memoryState) StoreClicks(ctx context.Context, clicks []models.Click) (err error) {
+	ms.clicksMu.Lock()
+	defer ms.clicksMu.Unlock()
+	if err = ms.journal.appendClicks(clicks); err != nil {
+		return
+	}
+	ms.clicks = append(ms.clicks, clicks...)
+	return
+}
+
+// GetClickStats - returns per day clicks aggregates of shortened URL
+func (ms *memoryState) GetClickStats(ctx context.Context, shortURL string) (stats []models.ClickStats, err error) {
+	days := map[time.Time]*models.ClickStats{}
+	visitors := map[time.Time]map[string]struct{}{}
+	ms.clicksMu.RLock()
+	defer ms.clicksMu.RUnlock()
+	for _, click := range ms.clicks {
+		if click.ShortURL != shortURL {
+			continue
+		}
+		day := click.Timestamp.UTC().Truncate(24 * time.Hour)
+		if _, found := days[day]; !found {
+			days[day] = &models.ClickStats{Day: day}
+			visitors[day] = map[string]struct{}{}
+		}
+		days[day].Clicks++
+		visitors[day][click.IP] = struct{}{}
+	}
+
+	stats = make([]models.ClickStats, 0, len(days))
+	for day, dayStats := range days {
+		dayStats.Visitors = len(visitors[day])
+		stats = append(stats, *dayStats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Day.Before(stats[j].Day) })
+	return
+}
+
+// CountClicks - returns amounts of stored clicks of shortened URLs, URLs without clicks are absent
+func (ms *memoryState) CountClicks(ctx context.Context, shortURLs []string) (counts map[string]int, err error) {
+	counts = make(map[string]int)
+	requested := make(map[string]struct{}, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		requested[shortURL] = struct{}{}
+	}
+	ms.clicksMu.RLock()
+	defer ms.clicksMu.RUnlock()
+	for _, click := range ms.clicks {
+		if _, found := requested[click.ShortURL]; found {
+			counts[click.ShortURL]++
+		}
+	}
+	return
+}
+
+// UpdateURL - replace original URL of the User's shortened URL, previous one is persisted by journal and appended to history
+func (ms *memoryState) UpdateURL(ctx context.Context, ID string, originalURL string) (err error) {
+	unlock, shortURL, found := ms.lockUpdate(ID, originalURL)
+	defer unlock()
+	if !found || shortURL.DeletedFlag || shortURL.UserID != ctx.Value(auth.ContextUserKey).(string) {
+		return ErrNotFound
+	}
+	if shortURL.OriginalURL == originalURL {
+		return
+	}
+	if _, taken := ms.findByOriginalURL(userURL{userID: shortURL.UserID, originalURL: originalURL}); taken && !shortURL.IsAlias {
+		return ErrConflict
+	}
+
+	ms.historyMu.Lock()
+	defer ms.historyMu.Unlock()
+	version := models.URLVersion{
+		ShortURL:    ID,
+		Version:     len(ms.history[ID]) + 1,
+		OriginalURL: shortURL.OriginalURL,
+		ReplacedAt:  time.Now().UTC(),
+	}
+	if err = ms.journal.appendVersion(version); err != nil {
+		return
+	}
+	ms.history[ID] = append(ms.history[ID], version)
+
+	shortURL.OriginalURL = originalURL
+	return ms.put(shortURL)
+}
+
+// lockUpdate - lock shard of short ID with index shards of its current and new original URLs and returns
+// shortened URL read under the locks, locking is repeated when original URL is changed in between
+func (ms *memoryState) lockUpdate(ID string, originalURL string) (unlock func(), shortURL models.ShortenURL, found bool) {
+	for {
+		read, found := ms.state.get(ID)
+		if !found {
+			return func() {}, read, false
+		}
+		unlock = ms.state.lockWriters([]userURL{
+			{userID: read.UserID, originalURL: read.OriginalURL},
+			{userID: read.UserID, originalURL: originalURL},
+		}, []string{ID})
+		shortURL, found = ms.state.get(ID)
+		if found && shortURL.UserID == read.UserID && shortURL.OriginalURL == read.OriginalURL {
+			return unlock, shortURL, true
+		}
+		unlock()
+	}
+}
+
+// GetURLHistory - returns previous original URLs of shortened URL from oldest to newest
+func (ms *memoryState) GetURLHistory(ctx context.Context, ID string) (versions []models.URLVersion, err error) {
+	ms.historyMu.RLock()
+	defer ms.historyMu.RUnlock()
+	versions = make([]models.URLVersion, len(ms.history[ID]))
+	copy(versions, ms.history[ID])
+	return
+}
+
+// put - persist shortened URLs by journal and apply them to state, writer locks of their shards have to be held
+func (ms *memoryState) put(shortURLs ...models.ShortenURL) error {
+	if len(shortURLs) == 0 {
+		return nil
+	}
+	records := make([]logRecord, 0, len(shortURLs))
+	for i := range shortURLs {
+		records = append(records, logRecord{Op: logOpPut, URL: &shortURLs[i]})
+	}
+	return ms.journal.appendLog(records, func() {
+		for _, shortURL := range shortURLs {
+			ms.state.set(shortURL)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// MemoryStorage - model of Repository storage which keeps data only in memory, everything is lost on shutdown.
+// It shares state handling with InFileStorage but its journal persists nothing
+type MemoryStorage struct {
+	*memoryState
+}
+
+// memoryJournal - stateJournal of MemoryStorage which applies mutations without persisting them
+type memoryJournal struct{}
+
+func (memoryJournal) appendLog(records []logRecord, apply func()) error {
+	apply()
+	return nil
+}
+
+func (memoryJournal) appendClicks(clicks []models.Click) error {
+	return nil
+}
+
+func (memoryJournal) appendVersion(version models.URLVersion) error {
+	return nil
+}
+
+func (memoryJournal) rewriteSidecars() error {
+	return nil
+}
+
+// Close - nothing to release, data is dropped with MemoryStorage
+func (ms *MemoryStorage) Close() error {
+	return nil
+}
+
+// NewMemoryStorage - create empty MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{memoryState: newMemoryState(memoryJournal{})}
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	ms := NewMemoryStorage()
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+
+	shortURL := newTestShortURL(t, "https://practicum.yandex.ru/", "1")
+	require.NoError(t, ms.Store(ctx, &shortURL))
+	require.NoError(t, ms.StoreClicks(ctx, []models.Click{{ShortURL: shortURL.UUID}}))
+	require.NoError(t, ms.UpdateURL(ctx, shortURL.UUID, "https://practicum.yandex.kz/"))
+
+	found, err := ms.FindByID(ctx, shortURL.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.kz/", found.OriginalURL)
+	versions, err := ms.GetURLHistory(ctx, shortURL.UUID)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	require.NoError(t, ms.DeleteShortURLs(ctx, []string{shortURL.UUID}))
+	purged, err := ms.PurgeDeleted(ctx, found.CreatedAt.AddDate(1, 0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	stats, err := ms.GetStats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Stats{Purged: 1}, stats)
+	_, err = ms.MigrationStatus(ctx)
+	assert.ErrorIs(t, err, ErrNoMigrations, "memory keeps no schema")
+	assert.NoError(t, ms.Close())
+}
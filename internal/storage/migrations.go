@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationStatus - compare version kept by golang-migrate in schema_migrations table with migrations of dir in migrations
+func migrationStatus(ctx context.Context, db *sql.DB, migrations fs.FS, dir string) (status models.MigrationStatus, err error) {
+	var version int64
+	err = db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &status.Dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	if version > 0 {
+		status.Version = uint(version)
+	}
+
+	versions, err := migrationVersions(migrations, dir)
+	if err != nil {
+		return
+	}
+	for _, known := range versions {
+		if known > status.Version {
+			status.Pending++
+		}
+	}
+	if len(versions) > 0 {
+		status.Latest = versions[len(versions)-1]
+	}
+	return
+}
+
+// migrationVersions - ascending versions of migrations of dir in migrations
+func migrationVersions(migrations fs.FS, dir string) (versions []uint, err error) {
+	source, err := iofs.New(migrations, dir)
+	if err != nil {
+		return
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = source.Next(version)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/PaBah/url-shortener.git/internal/models"
+)
+
+// stateShardsCount - amount of memoryState shards
+const stateShardsCount = 32
+
+// stateShard - part of memoryState shortened URLs, mu guards urls, writeMu is held by mutation of shortened URLs of the shard
+// from reading their current state until it is changed, so mutations of different shards do not contend
+type stateShard struct {
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	urls    map[string]models.ShortenURL
+}
+
+// indexShard - part of index of not alias shortened URLs by user ID and original URL, mu is held by mutation
+// which checks or changes pairs of the shard
+type indexShard struct {
+	mu  sync.Mutex
+	IDs map[userURL]string
+}
+
+// shardedState - shortened URLs by short ID split into shards by ID hash, so readers of different IDs do not contend,
+// with index of not alias ones by user ID and original URL split the same way by pair hash
+type shardedState struct {
+	shards [stateShardsCount]stateShard
+	index  [stateShardsCount]indexShard
+}
+
+// newShardedState - create shardedState filled with shortened URLs by short ID
+func newShardedState(urls map[string]models.ShortenURL) *shardedState {
+	state := &shardedState{}
+	for i := range state.shards {
+		state.shards[i].urls = make(map[string]models.ShortenURL)
+		state.index[i].IDs = make(map[userURL]string)
+	}
+	for _, shortURL := range urls {
+		state.set(shortURL)
+	}
+	return state
+}
+
+// shardNumber - returns number of shard of key
+func shardNumber(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % stateShardsCount)
+}
+
+// indexShardNumber - returns number of index shard of user ID and original URL pair
+func indexShardNumber(pair userURL) int {
+	return shardNumber(pair.userID + "\x00" + pair.originalURL)
+}
+
+func (s *shardedState) shard(ID string) *stateShard {
+	return &s.shards[shardNumber(ID)]
+}
+
+// get - returns shortened URL by short ID
+func (s *shardedState) get(ID string) (shortURL models.ShortenURL, found bool) {
+	shard := s.shard(ID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	shortURL, found = shard.urls[ID]
+	return
+}
+
+// lookup - returns short ID of not alias shortened URL of user ID and original URL pair,
+// index shard of the pair has to be locked by lockWriters
+func (s *shardedState) lookup(pair userURL) (ID string, found bool) {
+	ID, found = s.index[indexShardNumber(pair)].IDs[pair]
+	return
+}
+
+// set - save shortened URL by its short ID and index it, index shards of its previous and new pairs
+// have to be locked when they differ
+func (s *shardedState) set(shortURL models.ShortenURL) {
+	shard := s.shard(shortURL.UUID)
+	shard.mu.Lock()
+	previous, found := shard.urls[shortURL.UUID]
+	shard.urls[shortURL.UUID] = shortURL
+	shard.mu.Unlock()
+
+	if found && !previous.IsAlias && !shortURL.IsAlias &&
+		previous.UserID == shortURL.UserID && previous.OriginalURL == shortURL.OriginalURL {
+		return
+	}
+	if found {
+		s.unindex(previous)
+	}
+	if !shortURL.IsAlias {
+		pair := userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}
+		s.index[indexShardNumber(pair)].IDs[pair] = shortURL.UUID
+	}
+}
+
+// remove - delete shortened URL by short ID with its index entry
+func (s *shardedState) remove(ID string) {
+	shard := s.shard(ID)
+	shard.mu.Lock()
+	shortURL, found := shard.urls[ID]
+	delete(shard.urls, ID)
+	shard.mu.Unlock()
+	if found {
+		s.unindex(shortURL)
+	}
+}
+
+// unindex - drop index entry of not alias shortened URL if it still points to it
+func (s *shardedState) unindex(shortURL models.ShortenURL) {
+	if shortURL.IsAlias {
+		return
+	}
+	pair := userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}
+	IDs := s.index[indexShardNumber(pair)].IDs
+	if IDs[pair] == shortURL.UUID {
+		delete(IDs, pair)
+	}
+}
+
+// lockWriters - lock for mutation index shards of pairs and then state shards of short IDs, returned function unlocks them
+func (s *shardedState) lockWriters(pairs []userURL, IDs []string) (unlock func()) {
+	shardNumbers := make(map[int]struct{}, len(IDs))
+	for _, ID := range IDs {
+		shardNumbers[shardNumber(ID)] = struct{}{}
+	}
+	return s.lockShards(indexNumbers(pairs), shardNumbers)
+}
+
+// lockBatchWriters - lock for mutation index shards of pairs and every state shard, as short IDs of batch
+// may be regenerated into any shard, returned function unlocks them
+func (s *shardedState) lockBatchWriters(pairs []userURL) (unlock func()) {
+	return s.lockShards(indexNumbers(pairs), allNumbers())
+}
+
+// lockAllWriters - lock every index and state shard for mutation, returned function unlocks them
+func (s *shardedState) lockAllWriters() (unlock func()) {
+	return s.lockShards(allNumbers(), allNumbers())
+}
+
+// lockShards - lock index shards and then state shards, both in order of shard numbers,
+// so mutations never wait for each other in a cycle
+func (s *shardedState) lockShards(indexNumbers map[int]struct{}, shardNumbers map[int]struct{}) (unlock func()) {
+	locked := make([]sync.Locker, 0, len(indexNumbers)+len(shardNumbers))
+	for _, number := range sortedNumbers(indexNumbers) {
+		locked = append(locked, &s.index[number].mu)
+	}
+	for _, number := range sortedNumbers(shardNumbers) {
+		locked = append(locked, &s.shards[number].writeMu)
+	}
+	return lockAll(locked)
+}
+
+// indexNumbers - returns set of index shard numbers of pairs
+func indexNumbers(pairs []userURL) map[int]struct{} {
+	numbers := make(map[int]struct{}, len(pairs))
+	for _, pair := range pairs {
+		numbers[indexShardNumber(pair)] = struct{}{}
+	}
+	return numbers
+}
+
+// allNumbers - returns set of every shard number
+func allNumbers() map[int]struct{} {
+	numbers := make(map[int]struct{}, stateShardsCount)
+	for number := 0; number < stateShardsCount; number++ {
+		numbers[number] = struct{}{}
+	}
+	return numbers
+}
+
+// sortedNumbers - returns numbers of set in ascending order
+func sortedNumbers(numbers map[int]struct{}) []int {
+	sorted := make([]int, 0, len(numbers))
+	for number := range numbers {
+		sorted = append(sorted, number)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// lockAll - lock lockers one by one and return function which unlocks them in reverse order
+func lockAll(lockers []sync.Locker) (unlock func()) {
+	for _, locker := range lockers {
+		locker.Lock()
+	}
+	return func() {
+		for i := len(lockers) - 1; i >= 0; i-- {
+			lockers[i].Unlock()
+		}
+	}
+}
+
+// each - call fn for every shortened URL until it returns false, shards are read locked one by one
+func (s *shardedState) each(fn func(ID string, shortURL models.ShortenURL) bool) {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.RLock()
+		for ID, shortURL := range shard.urls {
+			if !fn(ID, shortURL) {
+				shard.mu.RUnlock()
+				return
+			}
+		}
+		shard.mu.RUnlock()
+	}
+}
+
+// len - returns amount of shortened URLs
+func (s *shardedState) len() (length int) {
+	for i := range s.shards {
+		s.shards[i].mu.RLock()
+		length += len(s.shards[i].urls)
+		s.shards[i].mu.RUnlock()
+	}
+	return
+}
@@ -0,0 +1,647 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/db"
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	moderncsqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeFormat - fixed width UTC format of times kept in SQLite, so text comparison of them is chronological
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000000"
+
+// SQLiteStorage - model of Repository storage on top of embedded SQLite Data Base
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+func (ss *SQLiteStorage) initialize(ctx context.Context, path string) (err error) {
+	ss.db, err = sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return
+	}
+	// SQLite serializes writers anyway, single connection also keeps one database for ":memory:" path
+	ss.db.SetMaxOpenConns(1)
+
+	driver, err := iofs.New(db.SQLiteMigrationsFS, "sqlite")
+	if err != nil {
+		return err
+	}
+
+	d, err := sqlite.WithInstance(ss.db, &sqlite.Config{})
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", driver, "sqlite_db", d)
+	if err != nil {
+		return err
+	}
+
+	if err = m.Up(); errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
+	return
+}
+
+// Store - stores shortened URL in SQLite, regenerates its ID when it is taken by another URL
+func (ss *SQLiteStorage) Store(ctx context.Context, shortURL *models.ShortenURL) (err error) {
+	if shortURL.CreatedAt.IsZero() {
+		shortURL.CreatedAt = time.Now().UTC()
+	}
+	for attempt := 1; attempt <= MaxIDGenerationAttempts; attempt++ {
+		_, err = ss.db.ExecContext(ctx,
+			`INSERT INTO urls(short_url, url, user_id, is_alias, expires_at, max_clicks, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.IsAlias, sqliteNullTime(shortURL.ExpiresAt),
+			shortURL.MaxClicks, sqliteTime(shortURL.CreatedAt))
+
+		if !isSQLiteUniqueViolation(err) {
+			return
+		}
+
+		if !strings.Contains(err.Error(), "urls.short_url") {
+			_ = ss.db.QueryRowContext(ctx,
+				`SELECT short_url FROM urls WHERE url=? AND user_id=? AND NOT is_alias`, shortURL.OriginalURL, shortURL.UserID).Scan(&shortURL.UUID)
+			return ErrConflict
+		}
+		if shortURL.IsAlias {
+			return ErrAliasTaken
+		}
+		if err = models.RegenerateID(shortURL, attempt); err != nil {
+			return
+		}
+	}
+	return ErrIDCollision
+}
+
+// StoreBatch - stores batch of shortened URLs in SQLite by multi-row inserts within one transaction,
+// returns result of every shortened URL in batch order
+func (ss *SQLiteStorage) StoreBatch(ctx context.Context, batchURLs []models.ShortenURL) (results []models.BatchResult, err error) {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			results = nil
+		}
+	}()
+
+	now := time.Now().UTC()
+	shortURLs := make([]models.ShortenURL, 0, len(batchURLs))
+	for _, shortURL := range batchURLs {
+		if shortURL.CreatedAt.IsZero() {
+			shortURL.CreatedAt = now
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
+	results = make([]models.BatchResult, len(shortURLs))
+	pending := batchIndexes(len(shortURLs))
+	for attempt := 1; len(pending) > 0; attempt++ {
+		var inserted map[string]struct{}
+		inserted, err = ss.insertBatch(ctx, tx, pending, shortURLs)
+		if err != nil {
+			return
+		}
+		var storedURLs map[userURL]models.ShortenURL
+		storedURLs, err = ss.findUserURLs(ctx, tx, batchUserURLs(conflictedIndexes(pending, shortURLs, inserted), shortURLs))
+		if err != nil {
+			return
+		}
+		pending = resolveBatch(pending, shortURLs, inserted, storedURLs, attempt, results)
+	}
+	err = tx.Commit()
+	return
+}
+
+// insertBatch - insert shortened URLs of batch indexes skipping conflicting ones, returns short IDs of inserted URLs
+func (ss *SQLiteStorage) insertBatch(ctx context.Context, tx *sql.Tx, indexes []int, shortURLs []models.ShortenURL) (inserted map[string]struct{}, err error) {
+	inserted = make(map[string]struct{}, len(indexes))
+	for start := 0; start < len(indexes); start += MaxBatchInsertRows {
+		chunk := indexes[start:min(start+MaxBatchInsertRows, len(indexes))]
+		args := make([]interface{}, 0, len(chunk)*7)
+		for _, i := range chunk {
+			shortURL := shortURLs[i]
+			args = append(args, shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.IsAlias,
+				sqliteNullTime(shortURL.ExpiresAt), shortURL.MaxClicks, sqliteTime(shortURL.CreatedAt))
+		}
+
+		var rows *sql.Rows
+		rows, err = tx.QueryContext(ctx, `INSERT INTO urls (short_url, url, user_id, is_alias, expires_at, max_clicks, created_at) VALUES `+
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?), ", len(chunk)), ", ")+` ON CONFLICT DO NOTHING RETURNING short_url`, args...)
+		if err != nil {
+			return
+		}
+		err = scanShortIDs(rows, inserted)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// findUserURLs - returns stored not alias shortened URLs by user ID and original URL pairs
+func (ss *SQLiteStorage) findUserURLs(ctx context.Context, tx *sql.Tx, pairs []userURL) (storedURLs map[userURL]models.ShortenURL, err error) {
+	storedURLs = make(map[userURL]models.ShortenURL, len(pairs))
+	for start := 0; start < len(pairs); start += MaxBatchInsertRows {
+		chunk := pairs[start:min(start+MaxBatchInsertRows, len(pairs))]
+		args := make([]interface{}, 0, len(chunk)*2)
+		for _, pair := range chunk {
+			args = append(args, pair.originalURL, pair.userID)
+		}
+
+		var rows *sql.Rows
+		rows, err = tx.QueryContext(ctx,
+			`SELECT short_url, url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls
+				WHERE NOT is_alias AND (url, user_id) IN (VALUES `+strings.TrimSuffix(strings.Repeat("(?, ?), ", len(chunk)), ", ")+`)`, args...)
+		if err != nil {
+			return
+		}
+		for rows.Next() {
+			var shortURL models.ShortenURL
+			var expiresAt sql.NullTime
+			err = rows.Scan(&shortURL.UUID, &shortURL.OriginalURL, &shortURL.UserID, &shortURL.DeletedFlag, &expiresAt, &shortURL.MaxClicks, &shortURL.Clicks)
+			if err != nil {
+				_ = rows.Close()
+				return
+			}
+			shortURL.ExpiresAt = expiresAt.Time
+			storedURLs[userURL{userID: shortURL.UserID, originalURL: shortURL.OriginalURL}] = shortURL
+		}
+		if err = rows.Close(); err != nil {
+			return
+		}
+		if err = rows.Err(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindByID - filter and returns shortened URL by short ID
+func (ss *SQLiteStorage) FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error) {
+	var expiresAt sql.NullTime
+	err = ss.db.QueryRowContext(ctx,
+		`SELECT url, user_id, is_deleted, expires_at, max_clicks, clicks FROM urls WHERE short_url=?`, ID).
+		Scan(&shortURL.OriginalURL, &shortURL.UserID, &shortURL.DeletedFlag, &expiresAt, &shortURL.MaxClicks, &shortURL.Clicks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ShortenURL{}, ErrNotFound
+	}
+	if err != nil {
+		return models.ShortenURL{}, err
+	}
+
+	shortURL.UUID = ID
+	shortURL.ExpiresAt = expiresAt.Time
+	return
+}
+
+// ListUserURLs - returns page of shortened URLs of the User from context, filtering, sorting and pagination are done by SQLite
+func (ss *SQLiteStorage) ListUserURLs(ctx context.Context, options models.ListOptions) (page models.URLsPage, err error) {
+	options, err = options.Normalize()
+	if err != nil {
+		return
+	}
+	cursor, err := options.ParseCursor()
+	if err != nil {
+		return
+	}
+
+	query := `SELECT ` + urlColumns + ` FROM urls WHERE user_id=?`
+	args := []interface{}{ctx.Value(auth.ContextUserKey).(string)}
+	switch options.Status {
+	case models.StatusActive:
+		query += ` AND NOT is_deleted`
+	case models.StatusDeleted:
+		query += ` AND is_deleted`
+	}
+	if options.Search != "" {
+		search := "%" + likeEscaper.Replace(options.Search) + "%"
+		args = append(args, search, search)
+		query += ` AND (url LIKE ? ESCAPE '\' OR short_url LIKE ? ESCAPE '\')`
+	}
+
+	column, direction, comparison := "created_at", "ASC", ">"
+	if options.SortBy == models.SortByOriginalURL {
+		column = "url"
+	}
+	if options.Desc {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor != nil {
+		var key interface{} = sqliteTime(cursor.CreatedAt)
+		if options.SortBy == models.SortByOriginalURL {
+			key = cursor.URL
+		}
+		args = append(args, key, cursor.ID)
+		query += fmt.Sprintf(` AND (%s, short_url) %s (?, ?)`, column, comparison)
+	}
+	args = append(args, options.Limit+1)
+	query += fmt.Sprintf(` ORDER BY %s %s, short_url %s LIMIT ?`, column, direction, direction)
+
+	rows, err := ss.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	shortURLs := make([]models.ShortenURL, 0)
+	for rows.Next() {
+		var shortURL models.ShortenURL
+		if shortURL, err = scanURL(rows); err != nil {
+			return
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	return newURLsPage(shortURLs, options), nil
+}
+
+// ScanURLs - calls scan for every shortened URL of all users ordered by short ID, rows are streamed by one query,
+// so they are read from one consistent snapshot of database
+func (ss *SQLiteStorage) ScanURLs(ctx context.Context, scan func(shortURL models.ShortenURL) error) (err error) {
+	rows, err := ss.db.QueryContext(ctx, `SELECT `+urlColumns+` FROM urls ORDER BY short_url`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL models.ShortenURL
+		if shortURL, err = scanURL(rows); err != nil {
+			return
+		}
+		if err = scan(shortURL); err != nil {
+			return
+		}
+	}
+	return rows.Err()
+}
+
+// UpsertURLs - store shortened URLs as they are with clicks and deletion in one transaction, stored URLs with the same short ID are replaced
+func (ss *SQLiteStorage) UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error) {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for start := 0; start < len(shortURLs); start += MaxBatchInsertRows {
+		chunk := shortURLs[start:min(start+MaxBatchInsertRows, len(shortURLs))]
+		args := make([]interface{}, 0, len(chunk)*10)
+		for _, shortURL := range chunk {
+			args = append(args, shortURL.UUID, shortURL.OriginalURL, shortURL.UserID, shortURL.DeletedFlag, shortURL.IsAlias,
+				sqliteNullTime(shortURL.ExpiresAt), shortURL.MaxClicks, shortURL.Clicks, sqliteTime(createdAt(shortURL)), sqliteNullTime(shortURL.DeletedAt))
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO urls (short_url, url, user_id, is_deleted, is_alias, expires_at, max_clicks, clicks, created_at, deleted_at)
+			VALUES `+strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?), ", len(chunk)), ", ")+` ON CONFLICT (short_url) DO UPDATE SET
+			url=excluded.url, user_id=excluded.user_id, is_deleted=excluded.is_deleted, is_alias=excluded.is_alias,
+			expires_at=excluded.expires_at, max_clicks=excluded.max_clicks, clicks=excluded.clicks, created_at=excluded.created_at, deleted_at=excluded.deleted_at`, args...)
+		if err != nil {
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+// AsyncCheckURLsUserID - async checking if URL belongs to the User from context
+func (ss *SQLiteStorage) AsyncCheckURLsUserID(userID string, shortURLCh chan string) chan string {
+	addRes := make(chan string)
+	go func() {
+		defer close(addRes)
+
+		for data := range shortURLCh {
+			shortURL, err := ss.FindByID(context.Background(), data)
+			var result string
+			if err == nil && shortURL.UserID == userID {
+				result = shortURL.UUID
+			}
+
+			addRes <- result
+		}
+	}()
+	return addRes
+}
+
+// DeleteShortURLs - delete shortened URLs from SQLite
+func (ss *SQLiteStorage) DeleteShortURLs(ctx context.Context, shortURLs []string) (err error) {
+	if len(shortURLs) == 0 {
+		return
+	}
+	placeholders, args := sqliteInArgs(shortURLs, sqliteTime(time.Now()))
+	_, err = ss.db.ExecContext(ctx,
+		`UPDATE urls SET is_deleted = TRUE, deleted_at = ? WHERE short_url IN (`+placeholders+`) AND NOT is_deleted`,
+		args...)
+	return
+}
+
+// GetStats - return amounts of users, active, deleted and purged urls in the system
+func (ss *SQLiteStorage) GetStats(ctx context.Context) (stats models.Stats, err error) {
+	err = ss.db.QueryRowContext(ctx,
+		`SELECT COUNT(id), COUNT(DISTINCT user_id), COUNT(id) FILTER (WHERE is_deleted),
+			COALESCE((SELECT value FROM url_counters WHERE name = 'purged'), 0) FROM urls`).
+		Scan(&stats.URLs, &stats.Users, &stats.Deleted, &stats.Purged)
+	stats.Active = stats.URLs - stats.Deleted
+
+	return
+}
+
+// RestoreShortURLs - undo soft deletion of not expired shortened URLs of the User from context
+func (ss *SQLiteStorage) RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error) {
+	restored = make([]string, 0)
+	if len(shortURLs) == 0 {
+		return
+	}
+	placeholders, args := sqliteInArgs(shortURLs, ctx.Value(auth.ContextUserKey).(string), sqliteTime(time.Now()))
+	rows, err := ss.db.QueryContext(ctx,
+		`UPDATE urls SET is_deleted = FALSE, deleted_at = NULL WHERE user_id = ? AND is_deleted
+			AND (expires_at IS NULL OR expires_at > ?) AND (max_clicks = 0 OR clicks < max_clicks)
+			AND short_url IN (`+placeholders+`) RETURNING short_url`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL string
+		if err = rows.Scan(&shortURL); err != nil {
+			return nil, err
+		}
+		restored = append(restored, shortURL)
+	}
+	return restored, rows.Err()
+}
+
+// MigrationStatus - return applied and the newest known versions of SQLite schema migrations
+func (ss *SQLiteStorage) MigrationStatus(ctx context.Context) (status models.MigrationStatus, err error) {
+	return migrationStatus(ctx, ss.db, db.SQLiteMigrationsFS, "sqlite")
+}
+
+// PurgeDeleted - permanently remove shortened URLs soft deleted before the moment with their clicks and history
+func (ss *SQLiteStorage) PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error) {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	purgedURLs := `SELECT short_url FROM urls WHERE is_deleted AND deleted_at < ?`
+	if _, err = tx.ExecContext(ctx, `DELETE FROM clicks WHERE short_url IN (`+purgedURLs+`)`, sqliteTime(before)); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM url_versions WHERE short_url IN (`+purgedURLs+`)`, sqliteTime(before)); err != nil {
+		return
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM urls WHERE is_deleted AND deleted_at < ?`, sqliteTime(before))
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affected > 0 {
+		if _, err = tx.ExecContext(ctx, `UPDATE url_counters SET value = value + ? WHERE name = 'purged'`, affected); err != nil {
+			return
+		}
+	}
+	return int(affected), tx.Commit()
+}
+
+// RegisterClick - count click on shortened URL, fails with ErrLinkExpired when URL is expired and ErrNotFound when it does not exist
+func (ss *SQLiteStorage) RegisterClick(ctx context.Context, ID string) (err error) {
+	result, err := ss.db.ExecContext(ctx,
+		`UPDATE urls SET clicks = clicks + 1 WHERE short_url = ?
+			AND (expires_at IS NULL OR expires_at > ?) AND (max_clicks = 0 OR clicks < max_clicks)`, ID, sqliteTime(time.Now()))
+	if err != nil {
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected > 0 {
+		return
+	}
+	var exists bool
+	if err = ss.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM urls WHERE short_url = ?)`, ID).Scan(&exists); err != nil {
+		return
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrLinkExpired
+}
+
+// DeleteExpired - mark as deleted shortened URLs expired at the moment
+func (ss *SQLiteStorage) DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error) {
+	result, err := ss.db.ExecContext(ctx,
+		`UPDATE urls SET is_deleted = TRUE, deleted_at = ?1 WHERE NOT is_deleted
+			AND ((expires_at IS NOT NULL AND expires_at <= ?1) OR (max_clicks > 0 AND clicks >= max_clicks))`, sqliteTime(now))
+	if err != nil {
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// StoreClicks - stores batch of clicks in SQLite
+func (ss *SQLiteStorage) StoreClicks(ctx context.Context, clicks []models.Click) (err error) {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, click := range clicks {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO clicks (short_url, clicked_at, referrer, user_agent, ip) VALUES(?, ?, ?, ?, ?)`,
+			click.ShortURL, sqliteTime(click.Timestamp), click.Referrer, click.UserAgent, click.IP)
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+// GetClickStats - returns per day clicks aggregates of shortened URL
+func (ss *SQLiteStorage) GetClickStats(ctx context.Context, shortURL string) (stats []models.ClickStats, err error) {
+	rows, err := ss.db.QueryContext(ctx,
+		`SELECT substr(clicked_at, 1, 10) AS day, COUNT(*), COUNT(DISTINCT ip) FROM clicks
+			WHERE short_url=? GROUP BY day ORDER BY day`, shortURL)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	stats = make([]models.ClickStats, 0)
+	for rows.Next() {
+		var dayStats models.ClickStats
+		var day string
+		if err = rows.Scan(&day, &dayStats.Clicks, &dayStats.Visitors); err != nil {
+			return nil, err
+		}
+		if dayStats.Day, err = time.Parse(time.DateOnly, day); err != nil {
+			return nil, err
+		}
+		stats = append(stats, dayStats)
+	}
+	return stats, rows.Err()
+}
+
+// CountClicks - returns amounts of stored clicks of shortened URLs, URLs without clicks are absent
+func (ss *SQLiteStorage) CountClicks(ctx context.Context, shortURLs []string) (counts map[string]int, err error) {
+	counts = make(map[string]int)
+	if len(shortURLs) == 0 {
+		return
+	}
+	placeholders, args := sqliteInArgs(shortURLs)
+	rows, err := ss.db.QueryContext(ctx,
+		`SELECT short_url, COUNT(*) FROM clicks WHERE short_url IN (`+placeholders+`) GROUP BY short_url`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL string
+		var clicks int
+		if err = rows.Scan(&shortURL, &clicks); err != nil {
+			return nil, err
+		}
+		counts[shortURL] = clicks
+	}
+	return counts, rows.Err()
+}
+
+// UpdateURL - replace original URL of the User's shortened URL, previous one is kept in url_versions
+func (ss *SQLiteStorage) UpdateURL(ctx context.Context, ID string, originalURL string) (err error) {
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var currentURL, userID string
+	var deletedFlag bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT url, user_id, is_deleted FROM urls WHERE short_url=?`, ID).Scan(&currentURL, &userID, &deletedFlag)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && (deletedFlag || userID != ctx.Value(auth.ContextUserKey).(string)) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	if currentURL == originalURL {
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO url_versions (short_url, version, url, replaced_at)
+			SELECT ?1, COALESCE(MAX(version), 0) + 1, ?2, ?3 FROM url_versions WHERE short_url=?1`, ID, currentURL, sqliteTime(time.Now()))
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE urls SET url=? WHERE short_url=?`, originalURL, ID)
+	if isSQLiteUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+// GetURLHistory - returns previous original URLs of shortened URL from oldest to newest
+func (ss *SQLiteStorage) GetURLHistory(ctx context.Context, ID string) (versions []models.URLVersion, err error) {
+	rows, err := ss.db.QueryContext(ctx,
+		`SELECT version, url, replaced_at FROM url_versions WHERE short_url=? ORDER BY version`, ID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	versions = make([]models.URLVersion, 0)
+	for rows.Next() {
+		version := models.URLVersion{ShortURL: ID}
+		if err = rows.Scan(&version.Version, &version.OriginalURL, &version.ReplacedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// Ping - check if SQLite Data Base is available
+func (ss *SQLiteStorage) Ping(ctx context.Context) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	return ss.db.PingContext(ctxWithTimeout)
+}
+
+// Close - close SQLite Data Base
+func (ss *SQLiteStorage) Close() error {
+	if ss.db == nil {
+		return nil
+	}
+	return ss.db.Close()
+}
+
+// NewSQLiteStorage - create instance of SQLiteStorage on top of Data Base file, ":memory:" path keeps it in memory
+func NewSQLiteStorage(ctx context.Context, path string) (*SQLiteStorage, error) {
+	store := &SQLiteStorage{}
+	err := store.initialize(ctx, path)
+	return store, err
+}
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func sqliteNullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: sqliteTime(t), Valid: true}
+}
+
+// sqliteInArgs - returns placeholders for values of IN list and query arguments with the values after leading ones
+func sqliteInArgs(values []string, leading ...interface{}) (string, []interface{}) {
+	args := make([]interface{}, 0, len(leading)+len(values))
+	args = append(args, leading...)
+	for _, value := range values {
+		args = append(args, value)
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *moderncsqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStorage_reopen(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "store.sqlite")
+	ss, err := NewSQLiteStorage(context.Background(), filePath)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), auth.ContextUserKey, "1")
+	_, err = ss.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: "1"},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: "1"},
+		{UUID: "other123", OriginalURL: "https://ya.ru/", UserID: "2"},
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, ss.UpdateURL(ctx, "2187b119", "https://practicum.yandex.kz/"))
+	assert.ErrorIs(t, ss.UpdateURL(ctx, "2187b119", "https://ya.ru/"), ErrConflict, "user already shortened URL")
+	assert.ErrorIs(t, ss.UpdateURL(ctx, "other123", "https://practicum.yandex.kz/"), ErrNotFound, "URL of another user")
+	assert.ErrorIs(t, ss.UpdateURL(ctx, "unknown", "https://practicum.yandex.kz/"), ErrNotFound)
+	require.NoError(t, ss.Close())
+
+	ss, err = NewSQLiteStorage(context.Background(), filePath)
+	require.NoError(t, err, "migrations are not applied twice")
+	defer ss.Close()
+	found, err := ss.FindByID(ctx, "2187b119")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.kz/", found.OriginalURL, "destination kept in file")
+	versions, err := ss.GetURLHistory(ctx, "2187b119")
+	assert.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "https://practicum.yandex.ru/", versions[0].OriginalURL)
+}
+
+func TestSQLiteStorage_MigrationStatus(t *testing.T) {
+	ss, err := NewSQLiteStorage(context.Background(), filepath.Join(t.TempDir(), "store.sqlite"))
+	require.NoError(t, err)
+	defer ss.Close()
+
+	status, err := ss.MigrationStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.MigrationStatus{Version: 1, Latest: 1}, status)
+
+	_, err = ss.db.Exec(`UPDATE schema_migrations SET version = 0, dirty = TRUE`)
+	require.NoError(t, err)
+	status, err = ss.MigrationStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.MigrationStatus{Version: 0, Latest: 1, Pending: 1, Dirty: true}, status)
+}
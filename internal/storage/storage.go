@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/PaBah/url-shortener.git/internal/models"
 )
@@ -10,13 +11,79 @@ import (
 // ErrConflict - error when user tries to save already existing data
 var ErrConflict = errors.New("data conflict")
 
+// ErrAliasTaken - error when user defined short ID is already used by another URL
+var ErrAliasTaken = errors.New("alias is already taken")
+
+// ErrLinkExpired - error when shortened URL expired by date or clicks amount
+var ErrLinkExpired = errors.New("shortened URL expired")
+
+// ErrNotFound - error when shortened URL does not exist, is deleted or belongs to another user
+var ErrNotFound = errors.New("shortened URL not found")
+
+// ErrIDCollision - error when no free short ID was generated in MaxIDGenerationAttempts
+var ErrIDCollision = errors.New("short ID collision")
+
+// ErrUnknownBackend - error when storage backend is configured with unsupported name
+var ErrUnknownBackend = errors.New("unknown storage backend")
+
+// ErrPingUnsupported - error when storage backend has no connection to check
+var ErrPingUnsupported = errors.New("storage backend has no connection to check")
+
+// ErrNoMigrations - error when storage backend keeps no schema, so it has no migrations
+var ErrNoMigrations = errors.New("storage backend has no schema migrations")
+
+// Names of storage backends which can be chosen by configuration
+const (
+	// BackendMemory - MemoryStorage, data is lost on shutdown
+	BackendMemory = "memory"
+	// BackendFile - InFileStorage
+	BackendFile = "file"
+	// BackendPostgres - DBStorage
+	BackendPostgres = "postgres"
+	// BackendSQLite - SQLiteStorage
+	BackendSQLite = "sqlite"
+)
+
+// MaxIDGenerationAttempts - amount of short ID generations before Store gives up on collisions
+const MaxIDGenerationAttempts = 5
+
+// MaxBatchInsertRows - max amount of shortened URLs inserted by one statement of StoreBatch
+const MaxBatchInsertRows = 1000
+
 // Repository - interface over Repository pattern for system storage
 type Repository interface {
-	Store(ctx context.Context, shortURL models.ShortenURL) (err error)
+	Store(ctx context.Context, shortURL *models.ShortenURL) (err error)
 	FindByID(ctx context.Context, ID string) (shortURL models.ShortenURL, err error)
-	GetAllUsers(ctx context.Context) (shortURLs []models.ShortenURL, err error)
-	StoreBatch(ctx context.Context, shortURLsMap map[string]models.ShortenURL) (err error)
+	ListUserURLs(ctx context.Context, options models.ListOptions) (page models.URLsPage, err error)
+	StoreBatch(ctx context.Context, shortURLs []models.ShortenURL) (results []models.BatchResult, err error)
 	AsyncCheckURLsUserID(usedID string, shortURL chan string) chan string
 	DeleteShortURLs(ctx context.Context, shortURLs []string) (err error)
-	GetStats(ctx context.Context) (urls int, users int, err error)
+	GetStats(ctx context.Context) (stats models.Stats, err error)
+	RegisterClick(ctx context.Context, ID string) (err error)
+	DeleteExpired(ctx context.Context, now time.Time) (deleted int, err error)
+	StoreClicks(ctx context.Context, clicks []models.Click) (err error)
+	GetClickStats(ctx context.Context, shortURL string) (stats []models.ClickStats, err error)
+	CountClicks(ctx context.Context, shortURLs []string) (counts map[string]int, err error)
+	UpdateURL(ctx context.Context, ID string, originalURL string) (err error)
+	GetURLHistory(ctx context.Context, ID string) (versions []models.URLVersion, err error)
+	RestoreShortURLs(ctx context.Context, shortURLs []string) (restored []string, err error)
+	PurgeDeleted(ctx context.Context, before time.Time) (purged int, err error)
+	ScanURLs(ctx context.Context, scan func(shortURL models.ShortenURL) error) (err error)
+	UpsertURLs(ctx context.Context, shortURLs []models.ShortenURL) (err error)
+	MigrationStatus(ctx context.Context) (status models.MigrationStatus, err error)
+}
+
+// Pinger - storage backend with connection which can be checked, decorators of Repository implement it
+// by pinging the wrapped one
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping - check connection of repository, fails with ErrPingUnsupported when it has no connection
+func Ping(ctx context.Context, repository Repository) error {
+	pinger, ok := repository.(Pinger)
+	if !ok {
+		return ErrPingUnsupported
+	}
+	return pinger.Ping(ctx)
 }
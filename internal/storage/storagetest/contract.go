@@ -0,0 +1,459 @@
+// Package storagetest - contract tests which every storage.Repository implementation has to pass
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PaBah/url-shortener.git/internal/auth"
+	"github.com/PaBah/url-shortener.git/internal/models"
+	"github.com/PaBah/url-shortener.git/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Users of contract cases, IDs are UUIDs as DBStorage keeps them in uuid column
+const (
+	userID      = "8c0a4b5e-3f1d-4e2a-9b6c-1d2e3f4a5b6c"
+	otherUserID = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+)
+
+// Factory - creates empty Repository for one contract case, it is closed by the factory with t.Cleanup
+type Factory func(t *testing.T) storage.Repository
+
+// RunRepositoryContract - run every contract case as subtest against fresh Repository
+func RunRepositoryContract(t *testing.T, newRepository Factory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, repository storage.Repository)
+	}{
+		{"Store", testStore},
+		{"StoreBatch", testStoreBatch},
+		{"SameURLOfManyUsers", testSameURLOfManyUsers},
+		{"ListUserURLs", testListUserURLs},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"Clicks", testClicks},
+		{"UpdateURL", testUpdateURL},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"ScanAndUpsertURLs", testScanAndUpsertURLs},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepository(t))
+		})
+	}
+}
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.ContextUserKey, userID)
+}
+
+func storeBatch(ctx context.Context, repository storage.Repository, shortURLs []models.ShortenURL) error {
+	_, err := repository.StoreBatch(ctx, shortURLs)
+	return err
+}
+
+func shortIDs(page models.URLsPage) []string {
+	IDs := make([]string, 0, len(page.URLs))
+	for _, shortURL := range page.URLs {
+		IDs = append(IDs, shortURL.UUID)
+	}
+	return IDs
+}
+
+func testStore(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	shortURL := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: userID, ExpiresAt: expiresAt, MaxClicks: 10}
+	require.NoError(t, repository.Store(ctx, &shortURL))
+
+	found, err := repository.FindByID(ctx, "2187b119")
+	require.NoError(t, err)
+	assert.Equal(t, "2187b119", found.UUID)
+	assert.Equal(t, "https://practicum.yandex.ru/", found.OriginalURL)
+	assert.Equal(t, userID, found.UserID)
+	assert.False(t, found.DeletedFlag)
+	assert.True(t, expiresAt.Equal(found.ExpiresAt), "expiration kept")
+	assert.Equal(t, 10, found.MaxClicks)
+
+	_, err = repository.FindByID(ctx, "unknown")
+	assert.ErrorIs(t, err, storage.ErrNotFound, "unknown short ID")
+
+	duplicate := models.ShortenURL{UUID: "a1b2c3d4", OriginalURL: "https://practicum.yandex.ru/", UserID: userID}
+	assert.ErrorIs(t, repository.Store(ctx, &duplicate), storage.ErrConflict, "same URL of the same user conflicts")
+	assert.Equal(t, "2187b119", duplicate.UUID, "conflict returns stored ID")
+
+	otherUser, err := models.NewShortURL("https://practicum.yandex.ru/", otherUserID)
+	require.NoError(t, err)
+	assert.NoError(t, repository.Store(userContext(otherUserID), &otherUser), "same URL of another user is stored")
+	assert.NotEqual(t, "2187b119", otherUser.UUID, "colliding short ID regenerated")
+
+	alias := models.NewAliasShortURL("spring-sale", "https://practicum.yandex.ru/", userID)
+	assert.NoError(t, repository.Store(ctx, &alias), "alias may point to already shortened URL")
+	taken := models.NewAliasShortURL("spring-sale", "https://ya.ru/", otherUserID)
+	assert.ErrorIs(t, repository.Store(ctx, &taken), storage.ErrAliasTaken)
+	found, err = repository.FindByID(ctx, "spring-sale")
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.ru/", found.OriginalURL, "taken alias is not overwritten")
+}
+
+// testSameURLOfManyUsers - more users than MaxIDGenerationAttempts shorten the same URL by Store and StoreBatch,
+// every one of them gets own short ID
+func testSameURLOfManyUsers(t *testing.T, repository storage.Repository) {
+	users := 2 * storage.MaxIDGenerationAttempts
+	IDs := make(map[string]struct{}, 2*users)
+	for i := 0; i < users; i++ {
+		user := fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		shortURL, err := models.NewShortURL("https://practicum.yandex.ru/", user)
+		require.NoError(t, err)
+		require.NoError(t, repository.Store(userContext(user), &shortURL), "user %d stores URL", i)
+		IDs[shortURL.UUID] = struct{}{}
+
+		batchURL, err := models.NewShortURL("https://practicum.yandex.kz/", user)
+		require.NoError(t, err)
+		results, err := repository.StoreBatch(userContext(user), []models.ShortenURL{batchURL})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, models.BatchCreated, results[0].Status, "user %d stores batch URL: %v", i, results[0].Err)
+		IDs[results[0].ShortURL.UUID] = struct{}{}
+	}
+	assert.Len(t, IDs, 2*users, "every user has own short IDs")
+}
+
+func testStoreBatch(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	stored := models.ShortenURL{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: userID}
+	require.NoError(t, repository.Store(ctx, &stored))
+
+	results, err := repository.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://ya.ru/other", UserID: userID},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: userID},
+		{UUID: "c3d4e5f6", OriginalURL: "https://practicum.yandex.ru/", UserID: userID},
+		{UUID: "d4e5f6a7", OriginalURL: "https://ya.ru/", UserID: userID},
+		models.NewAliasShortURL("2187b119", "https://ya.ru/alias", userID),
+		models.NewAliasShortURL("spring-sale", "https://ya.ru/sale", userID),
+		models.NewAliasShortURL("spring-sale", "https://ya.ru/other-sale", userID),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 7, "result of every batch URL")
+
+	assert.Equal(t, models.BatchCreated, results[0].Status)
+	assert.NotEqual(t, "2187b119", results[0].ShortURL.UUID, "taken short ID regenerated")
+	found, err := repository.FindByID(ctx, results[0].ShortURL.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://ya.ru/other", found.OriginalURL)
+	found, err = repository.FindByID(ctx, "2187b119")
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.ru/", found.OriginalURL, "stored URL is not overwritten")
+
+	assert.Equal(t, models.BatchCreated, results[1].Status)
+	assert.Equal(t, "bc2c0be9", results[1].ShortURL.UUID)
+	assert.Equal(t, models.BatchExisted, results[2].Status, "URL already shortened by user")
+	assert.Equal(t, "2187b119", results[2].ShortURL.UUID, "stored short ID returned")
+	assert.Equal(t, models.BatchExisted, results[3].Status, "URL shortened twice in batch")
+	assert.Equal(t, "bc2c0be9", results[3].ShortURL.UUID)
+	assert.Equal(t, models.BatchFailed, results[4].Status)
+	assert.ErrorIs(t, results[4].Err, storage.ErrAliasTaken)
+	assert.Equal(t, models.BatchCreated, results[5].Status)
+	assert.Equal(t, models.BatchFailed, results[6].Status, "alias taken in batch")
+	assert.ErrorIs(t, results[6].Err, storage.ErrAliasTaken)
+	_, err = repository.FindByID(ctx, "c3d4e5f6")
+	assert.ErrorIs(t, err, storage.ErrNotFound, "existing URL is not stored again")
+
+	results, err = repository.StoreBatch(ctx, []models.ShortenURL{
+		{UUID: "ffff0001", OriginalURL: "https://ya.ru/twice", UserID: userID},
+		{UUID: "aaaa0001", OriginalURL: "https://ya.ru/twice", UserID: userID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchCreated, results[0].Status, "earlier URL of batch wins regardless of its short ID")
+	assert.Equal(t, models.BatchExisted, results[1].Status)
+	assert.Equal(t, "ffff0001", results[1].ShortURL.UUID)
+
+	stats, err := repository.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Stats{URLs: 5, Users: 1, Active: 5}, stats)
+
+	large := make([]models.ShortenURL, 0, 2*storage.MaxBatchInsertRows+1)
+	for i := 0; i < 2*storage.MaxBatchInsertRows+1; i++ {
+		ID := fmt.Sprintf("large%03d", i)
+		large = append(large, models.ShortenURL{UUID: ID, OriginalURL: "https://ya.ru/" + ID, UserID: otherUserID})
+	}
+	results, err = repository.StoreBatch(userContext(otherUserID), large)
+	require.NoError(t, err)
+	for i, result := range results {
+		assert.Equal(t, models.BatchCreated, result.Status, large[i].UUID)
+	}
+	stats, err = repository.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5+len(large), stats.URLs, "batch larger than one insert statement stored")
+}
+
+func testListUserURLs(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	page, err := repository.ListUserURLs(ctx, models.ListOptions{})
+	require.NoError(t, err, "user without URLs is not an error")
+	assert.Empty(t, page.URLs)
+	assert.Empty(t, page.NextCursor)
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, storeBatch(ctx, repository, []models.ShortenURL{
+		{UUID: "aaaa0001", OriginalURL: "https://c.ru/", UserID: userID, CreatedAt: createdAt},
+		{UUID: "aaaa0002", OriginalURL: "https://a.ru/sale_50%", UserID: userID, CreatedAt: createdAt.Add(time.Hour)},
+		{UUID: "aaaa0003", OriginalURL: "https://b.ru/", UserID: userID, CreatedAt: createdAt.Add(time.Hour), MaxClicks: 5},
+		{UUID: "aaaa0004", OriginalURL: "https://d.ru/", UserID: otherUserID, CreatedAt: createdAt},
+	}))
+	require.NoError(t, repository.DeleteShortURLs(ctx, []string{"aaaa0002"}))
+	require.NoError(t, repository.RegisterClick(ctx, "aaaa0003"))
+
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0002"}, shortIDs(page), "sorted by creation, ties by short ID")
+	assert.True(t, createdAt.Equal(page.URLs[0].CreatedAt), "creation time kept")
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0003"}, shortIDs(page), "second page continues after cursor")
+	assert.Empty(t, page.NextCursor, "last page has no cursor")
+	assert.Equal(t, 5, page.URLs[0].MaxClicks, "limits listed")
+	assert.Equal(t, 1, page.URLs[0].Clicks, "clicks listed")
+
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{SortBy: models.SortByOriginalURL, Desc: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0003", "aaaa0002"}, shortIDs(page), "sorted by original URL descending")
+
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Status: models.StatusActive})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0001", "aaaa0003"}, shortIDs(page))
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Status: models.StatusDeleted})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0002"}, shortIDs(page))
+	assert.True(t, page.URLs[0].DeletedFlag)
+	assert.False(t, page.URLs[0].DeletedAt.IsZero(), "moment of deletion listed")
+
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Search: "SALE_50%"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0002"}, shortIDs(page), "case-insensitive search")
+	page, err = repository.ListUserURLs(ctx, models.ListOptions{Search: "_"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa0002"}, shortIDs(page), "wildcard is searched literally")
+
+	_, err = repository.ListUserURLs(ctx, models.ListOptions{Cursor: "broken"})
+	assert.ErrorIs(t, err, models.ErrInvalidListOptions)
+}
+
+func testDeleteAndRestore(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	require.NoError(t, storeBatch(ctx, repository, []models.ShortenURL{
+		{UUID: "restored", OriginalURL: "https://ya.ru/", UserID: userID},
+		{UUID: "expired", OriginalURL: "https://practicum.yandex.kz/", UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)},
+		{UUID: "other", OriginalURL: "https://ya.ru/", UserID: otherUserID},
+	}))
+
+	shortURLCh := make(chan string)
+	checked := repository.AsyncCheckURLsUserID(userID, shortURLCh)
+	go func() {
+		defer close(shortURLCh)
+		for _, ID := range []string{"restored", "other", "unknown"} {
+			shortURLCh <- ID
+		}
+	}()
+	owned := make([]string, 0)
+	for ID := range checked {
+		owned = append(owned, ID)
+	}
+	assert.Equal(t, []string{"restored", "", ""}, owned, "only own URLs pass check")
+
+	require.NoError(t, repository.DeleteShortURLs(ctx, []string{"restored", "expired", "other", "unknown"}))
+	require.NoError(t, repository.DeleteShortURLs(ctx, []string{"restored"}), "repeated deletion is not an error")
+	found, err := repository.FindByID(ctx, "restored")
+	require.NoError(t, err, "deleted URL is still found")
+	assert.True(t, found.DeletedFlag)
+	_, err = repository.FindByID(ctx, "unknown")
+	assert.ErrorIs(t, err, storage.ErrNotFound, "deletion does not create unknown short IDs")
+
+	stats, err := repository.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Stats{URLs: 3, Users: 2, Deleted: 3}, stats)
+
+	restored, err := repository.RestoreShortURLs(ctx, []string{"restored", "expired", "other", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"restored"}, restored, "expired and other user's URLs are not restored")
+	restored, err = repository.RestoreShortURLs(ctx, []string{"restored"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, restored, "active URL is not restored twice")
+	found, err = repository.FindByID(ctx, "restored")
+	require.NoError(t, err)
+	assert.False(t, found.DeletedFlag)
+}
+
+func testClicks(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	require.NoError(t, storeBatch(ctx, repository, []models.ShortenURL{
+		{UUID: "once", OriginalURL: "https://ya.ru/", UserID: userID, MaxClicks: 1},
+		{UUID: "expired", OriginalURL: "https://practicum.yandex.ru/", UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)},
+		{UUID: "active", OriginalURL: "https://practicum.yandex.kz/", UserID: userID, ExpiresAt: time.Now().Add(time.Hour)},
+	}))
+
+	assert.NoError(t, repository.RegisterClick(ctx, "once"), "first click allowed")
+	assert.ErrorIs(t, repository.RegisterClick(ctx, "once"), storage.ErrLinkExpired, "no clicks left")
+	assert.ErrorIs(t, repository.RegisterClick(ctx, "expired"), storage.ErrLinkExpired)
+	assert.ErrorIs(t, repository.RegisterClick(ctx, "unknown"), storage.ErrNotFound)
+	assert.NoError(t, repository.RegisterClick(ctx, "active"))
+	found, err := repository.FindByID(ctx, "active")
+	require.NoError(t, err)
+	assert.Equal(t, 1, found.Clicks)
+
+	deleted, err := repository.DeleteExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	deleted, err = repository.DeleteExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted, "deleted URLs are not counted again")
+
+	firstDay := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repository.StoreClicks(ctx, []models.Click{
+		{ShortURL: "active", Timestamp: firstDay.Add(38 * time.Hour), IP: "10.0.0.0"},
+		{ShortURL: "active", Timestamp: firstDay, IP: "10.0.0.0"},
+		{ShortURL: "active", Timestamp: firstDay.Add(time.Hour), IP: "10.0.1.0"},
+		{ShortURL: "once", Timestamp: firstDay, IP: "10.0.0.0"},
+	}))
+	stats, err := repository.GetClickStats(ctx, "active")
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.True(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Equal(stats[0].Day))
+	assert.Equal(t, 2, stats[0].Clicks)
+	assert.Equal(t, 2, stats[0].Visitors)
+	assert.True(t, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC).Equal(stats[1].Day))
+	assert.Equal(t, 1, stats[1].Clicks)
+
+	stats, err = repository.GetClickStats(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, []models.ClickStats{}, stats)
+
+	counts, err := repository.CountClicks(ctx, []string{"active", "once", "expired"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"active": 3, "once": 1}, counts, "recorded clicks counted, URLs without them are absent")
+	counts, err = repository.CountClicks(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func testUpdateURL(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	require.NoError(t, storeBatch(ctx, repository, []models.ShortenURL{
+		{UUID: "2187b119", OriginalURL: "https://practicum.yandex.ru/", UserID: userID},
+		{UUID: "bc2c0be9", OriginalURL: "https://ya.ru/", UserID: userID},
+		{UUID: "deleted", OriginalURL: "https://ya.ru/deleted", UserID: userID},
+		{UUID: "other123", OriginalURL: "https://ya.ru/", UserID: otherUserID},
+	}))
+	require.NoError(t, repository.DeleteShortURLs(ctx, []string{"deleted"}))
+
+	require.NoError(t, repository.UpdateURL(ctx, "2187b119", "https://practicum.yandex.kz/"))
+	require.NoError(t, repository.UpdateURL(ctx, "2187b119", "https://practicum.yandex.kz/"), "same destination is not a new version")
+	require.NoError(t, repository.UpdateURL(ctx, "2187b119", "https://practicum.yandex.com/"))
+	found, err := repository.FindByID(ctx, "2187b119")
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.com/", found.OriginalURL)
+
+	assert.ErrorIs(t, repository.UpdateURL(ctx, "2187b119", "https://ya.ru/"), storage.ErrConflict, "user already shortened URL")
+	assert.ErrorIs(t, repository.UpdateURL(ctx, "other123", "https://practicum.yandex.kz/"), storage.ErrNotFound, "URL of another user")
+	assert.ErrorIs(t, repository.UpdateURL(ctx, "deleted", "https://practicum.yandex.kz/"), storage.ErrNotFound, "deleted URL")
+	assert.ErrorIs(t, repository.UpdateURL(ctx, "unknown", "https://practicum.yandex.kz/"), storage.ErrNotFound)
+
+	versions, err := repository.GetURLHistory(ctx, "2187b119")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "https://practicum.yandex.ru/", versions[0].OriginalURL)
+	assert.Equal(t, 2, versions[1].Version)
+	assert.Equal(t, "https://practicum.yandex.kz/", versions[1].OriginalURL)
+	assert.False(t, versions[0].ReplacedAt.IsZero())
+
+	versions, err = repository.GetURLHistory(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, []models.URLVersion{}, versions)
+}
+
+func testPurgeDeleted(t *testing.T, repository storage.Repository) {
+	ctx := userContext(userID)
+	require.NoError(t, storeBatch(ctx, repository, []models.ShortenURL{
+		{UUID: "purged", OriginalURL: "https://practicum.yandex.ru/", UserID: userID},
+		{UUID: "kept", OriginalURL: "https://ya.ru/", UserID: userID},
+	}))
+	require.NoError(t, repository.StoreClicks(ctx, []models.Click{{ShortURL: "purged", Timestamp: time.Now()}}))
+	require.NoError(t, repository.UpdateURL(ctx, "purged", "https://practicum.yandex.kz/"))
+	require.NoError(t, repository.DeleteShortURLs(ctx, []string{"purged"}))
+
+	purged, err := repository.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged, "URLs deleted after the moment are kept")
+
+	purged, err = repository.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = repository.FindByID(ctx, "purged")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	clicks, err := repository.GetClickStats(ctx, "purged")
+	require.NoError(t, err)
+	assert.Empty(t, clicks, "clicks of purged URL removed")
+	versions, err := repository.GetURLHistory(ctx, "purged")
+	require.NoError(t, err)
+	assert.Empty(t, versions, "history of purged URL removed")
+
+	stats, err := repository.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Stats{URLs: 1, Users: 1, Active: 1, Purged: 1}, stats)
+
+	restored, err := repository.RestoreShortURLs(ctx, []string{"purged"})
+	require.NoError(t, err)
+	assert.Empty(t, restored, "purged URL can not be restored")
+}
+
+func testScanAndUpsertURLs(t *testing.T, repository storage.Repository) {
+	ctx := context.Background()
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	upserted := []models.ShortenURL{
+		{UUID: "bbbb0002", OriginalURL: "https://ya.ru/", UserID: otherUserID, IsAlias: true, CreatedAt: createdAt, MaxClicks: 10, Clicks: 3},
+		{UUID: "bbbb0001", OriginalURL: "https://practicum.yandex.ru/", UserID: userID, CreatedAt: createdAt,
+			ExpiresAt: createdAt.Add(24 * time.Hour), DeletedFlag: true, DeletedAt: createdAt.Add(time.Hour)},
+	}
+	require.NoError(t, repository.UpsertURLs(ctx, upserted))
+
+	var scanned []models.ShortenURL
+	require.NoError(t, repository.ScanURLs(ctx, func(shortURL models.ShortenURL) error {
+		scanned = append(scanned, shortURL)
+		return nil
+	}))
+	require.Len(t, scanned, 2)
+	assert.Equal(t, "bbbb0001", scanned[0].UUID, "ordered by short ID")
+	assert.Equal(t, userID, scanned[0].UserID)
+	assert.True(t, scanned[0].DeletedFlag, "deletion kept")
+	assert.True(t, createdAt.Add(time.Hour).Equal(scanned[0].DeletedAt))
+	assert.True(t, createdAt.Add(24*time.Hour).Equal(scanned[0].ExpiresAt))
+	assert.True(t, createdAt.Equal(scanned[0].CreatedAt))
+	assert.True(t, scanned[1].IsAlias)
+	assert.Equal(t, 3, scanned[1].Clicks, "clicks kept")
+	assert.Equal(t, 10, scanned[1].MaxClicks)
+
+	upserted[1].DeletedFlag, upserted[1].DeletedAt = false, time.Time{}
+	require.NoError(t, repository.UpsertURLs(ctx, upserted[1:]))
+	found, err := repository.FindByID(ctx, "bbbb0001")
+	require.NoError(t, err)
+	assert.False(t, found.DeletedFlag, "stored URL with the same short ID replaced")
+	stats, err := repository.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.URLs)
+	assert.Equal(t, 2, stats.Users)
+
+	stopped := fmt.Errorf("stopped")
+	calls := 0
+	err = repository.ScanURLs(ctx, func(shortURL models.ShortenURL) error {
+		calls++
+		return stopped
+	})
+	assert.ErrorIs(t, err, stopped, "scan error stops scanning")
+	assert.Equal(t, 1, calls)
+}